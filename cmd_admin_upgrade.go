@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/shopware/extension-verifier/internal/admintwiglinter"
+	"github.com/shopware/extension-verifier/internal/diff"
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/shopware/extension-verifier/internal/llm"
+	"github.com/shopware/shopware-cli/logging"
+	"github.com/shyim/go-version"
+	"github.com/spf13/cobra"
+)
+
+const adminSystemPrompt = `
+You are a helper agent to help to upgrade Shopware administration templates to the Meteor component library. A removed component has already been replaced automatically. I will give you the review note, the API changes of the component, the original element and the automatic conversion.
+- Fix only what the automatic conversion missed, keep everything else as it is.
+- Keep Twig blocks, Vue directives and event handlers.
+- Please only output the upgraded element inside a html code fence, nothing more.
+`
+
+// adminReviewItem is an element which has been converted by a fixer, but needs a manual review.
+type adminReviewItem struct {
+	Error admintwiglinter.CheckError
+	Node  *html.ElementNode
+	// Element before the fixer ran
+	Original string
+}
+
+var adminUpgradeCommand = &cobra.Command{
+	Use:   "admin-upgrade [path]",
+	Short: "Experimental upgrade of administration templates using the fixers and AI",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		log.Error("Extension Verifier project have been moved into shopware-cli itself")
+		log.Error("Sleeping for 30 seconds before running the old command")
+		time.Sleep(30 * time.Second)
+
+		toolCfg, err := getToolConfig(args[0])
+
+		if err != nil {
+			return err
+		}
+
+		shopwareVersion, err := version.NewVersion(toolCfg.MinShopwareVersion)
+
+		if err != nil {
+			return err
+		}
+
+		client, options, err := getLLMClient(cmd, toolCfg.Verifier.LLM.Providers)
+
+		if err != nil {
+			return err
+		}
+
+		options.SystemPrompt = adminSystemPrompt
+		logging.FromContext(cmd.Context()).Debugf("System Prompt:\n%s\n", adminSystemPrompt)
+
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		fixers := admintwiglinter.GetFixers(shopwareVersion)
+
+		for _, adminDirectory := range toolCfg.AdminDirectories {
+			err := filepath.WalkDir(adminDirectory, func(file string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if d.IsDir() || filepath.Ext(file) != ".twig" {
+					return nil
+				}
+
+				info, err := d.Info()
+
+				if err != nil {
+					return err
+				}
+
+				content, err := os.ReadFile(file)
+
+				if err != nil {
+					return err
+				}
+
+				doc, err := html.ParseDocument(string(content))
+
+				if err != nil {
+					return fmt.Errorf("failed to parse %s: %w", file, err)
+				}
+
+				reviewItems, err := fixAdminTemplate(doc.Nodes, fixers)
+
+				if err != nil {
+					return err
+				}
+
+				for _, item := range reviewItems {
+					log.Info("Reviewing conversion", "file", file, "line", item.Error.Line, "component", item.Error.Identifier)
+
+					upgraded, err := upgradeAdminElement(cmd.Context(), file, item, client, options)
+
+					if err != nil {
+						return err
+					}
+
+					if upgraded == nil {
+						log.Warn("LLM returned no valid element, keeping the automatic conversion", "file", file, "line", item.Error.Line)
+						continue
+					}
+
+					upgraded.Line = item.Node.Line
+					// The spans belong to the answer of the LLM, without them the element is written as a whole
+					upgraded.Span = html.Span{}
+					*item.Node = *upgraded
+				}
+
+				text, err := doc.Dump()
+
+				if err != nil {
+					return fmt.Errorf("failed to upgrade %s: %w", file, err)
+				}
+
+				if text == string(content) {
+					return nil
+				}
+
+				if _, err := html.NewParser(text); err != nil {
+					log.Warn("Rejected upgraded template", "file", file, "error", err)
+					return nil
+				}
+
+				if dryRun {
+					relativePath, err := filepath.Rel(toolCfg.RootDir, file)
+
+					if err != nil {
+						return err
+					}
+
+					fmt.Print(diff.Unified("a/"+relativePath, "b/"+relativePath, string(content), text))
+					return nil
+				}
+
+				return os.WriteFile(file, []byte(text), info.Mode().Perm())
+			})
+
+			if err != nil {
+				return err
+			}
+		}
+
+		log.Info("LLM usage", "usage", client.Usage().String())
+
+		return nil
+	},
+}
+
+// fixAdminTemplate runs the fixers and returns the elements changed by fixers whose conversion needs a manual review.
+func fixAdminTemplate(nodes html.NodeList, fixers []admintwiglinter.AdminTwigFixer) ([]adminReviewItem, error) {
+	var items []adminReviewItem
+	reviewed := map[*html.ElementNode]bool{}
+
+	for _, fixer := range fixers {
+		metadata := fixer.Metadata()
+
+		if metadata.Safety != admintwiglinter.FixNeedsReview {
+			if err := fixer.Fix(nodes); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		before := map[*html.ElementNode]string{}
+		originals := map[*html.ElementNode]string{}
+
+		html.TraverseNode(nodes, func(node *html.ElementNode) {
+			before[node] = dumpStartTag(node)
+			originals[node] = node.Dump(0, html.DefaultFormatOptions())
+		})
+
+		if err := fixer.Fix(nodes); err != nil {
+			return nil, err
+		}
+
+		html.TraverseNode(nodes, func(node *html.ElementNode) {
+			startTag, ok := before[node]
+
+			if !ok || reviewed[node] || startTag == dumpStartTag(node) {
+				return
+			}
+
+			reviewed[node] = true
+			items = append(items, adminReviewItem{
+				Error: admintwiglinter.CheckError{
+					Message:    metadata.Description,
+					Severity:   "error",
+					Identifier: metadata.Identifier,
+					Line:       node.Line,
+				},
+				Node:     node,
+				Original: originals[node],
+			})
+		})
+	}
+
+	return items, nil
+}
+
+// dumpStartTag returns the element without its children, so changes of nested elements are not attributed to it.
+func dumpStartTag(node *html.ElementNode) string {
+	element := *node
+	element.Children = nil
+
+	return element.Dump(0, html.DefaultFormatOptions())
+}
+
+// upgradeAdminElement asks the LLM to complete the conversion of a reviewed element.
+// It returns nil when the answer is not exactly one element.
+func upgradeAdminElement(ctx context.Context, file string, item adminReviewItem, client llm.LLMClient, options *llm.LLMOptions) (*html.ElementNode, error) {
+	var str strings.Builder
+	str.WriteString(fmt.Sprintf("Review note: %s\n", item.Error.Message))
+
+	if migration, ok := admintwiglinter.ComponentMigrations[item.Error.Identifier]; ok {
+		str.WriteString(fmt.Sprintf("API changes from %s to %s:\n", item.Error.Identifier, migration.Replacement))
+
+		for _, change := range migration.Changes {
+			str.WriteString("- " + change + "\n")
+		}
+	}
+
+	str.WriteString("This was the original element:\n")
+	str.WriteString("```html\n")
+	str.WriteString(item.Original)
+	str.WriteString("\n```\n")
+	str.WriteString("and this is the automatic conversion:\n")
+	str.WriteString("```html\n")
+	str.WriteString(item.Node.Dump(0, html.DefaultFormatOptions()))
+	str.WriteString("\n```")
+
+	logging.FromContext(ctx).Debugf("Input to LLM for file %s:\n%s\n", file, str.String())
+
+	text, err := client.Generate(ctx, str.String(), options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	code, ok := extractCode(text)
+
+	if !ok {
+		return nil, nil
+	}
+
+	nodes, err := html.NewParser(code)
+
+	if err != nil {
+		return nil, nil
+	}
+
+	var element *html.ElementNode
+
+	for _, node := range nodes {
+		if raw, ok := node.(*html.RawNode); ok && strings.TrimSpace(raw.Text) == "" {
+			continue
+		}
+
+		if element != nil {
+			return nil, nil
+		}
+
+		if element, ok = node.(*html.ElementNode); !ok {
+			return nil, nil
+		}
+	}
+
+	return element, nil
+}
+
+func init() {
+	addLLMFlags(adminUpgradeCommand)
+	adminUpgradeCommand.Flags().Bool("dry-run", false, "Print the changes as unified diff instead of writing them")
+	rootCmd.AddCommand(adminUpgradeCommand)
+}
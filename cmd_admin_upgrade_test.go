@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/shopware/extension-verifier/internal/admintwiglinter"
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/shyim/go-version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixAdminTemplateCollectsReviewItems(t *testing.T) {
+	nodes, err := html.NewParser(`<div><sw-button variant="danger">Save</sw-button><sw-icon name="regular-times" small /></div>`)
+	assert.NoError(t, err)
+
+	items, err := fixAdminTemplate(nodes, admintwiglinter.GetFixers(version.Must(version.NewVersion("6.7.0.0"))))
+	assert.NoError(t, err)
+
+	// The parent element only changed because of its children and sw-icon is converted safely
+	assert.Len(t, items, 1)
+	assert.Equal(t, "sw-button", items[0].Error.Identifier)
+	assert.Equal(t, `<sw-button variant="danger">Save</sw-button>`, items[0].Original)
+	assert.Equal(t, "mt-button", items[0].Node.Tag)
+}
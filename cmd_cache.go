@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+)
+
+var cacheCommand = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of Shopware sources",
+}
+
+var cacheWarmCommand = &cobra.Command{
+	Use:   "warm [version...]",
+	Short: "Pre-populates the cache with the storefront sources of the given Shopware versions",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		provider, err := getShopwareSourceProvider(cmd)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range args {
+			dir, err := provider.Warm(cmd.Context(), version)
+			if err != nil {
+				return err
+			}
+
+			log.Info("Cached Shopware sources", "version", version, "path", dir)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	addShopwareSourceFlags(cacheWarmCommand)
+	cacheCommand.AddCommand(cacheWarmCommand)
+	rootCmd.AddCommand(cacheCommand)
+}
@@ -64,6 +64,8 @@ var checkCommand = &cobra.Command{
 			if err != nil {
 				return err
 			}
+
+			toolCfg.ArchivePath = args[0]
 		}
 
 		toolCfg.CheckAgainst = checkAgainst
@@ -108,8 +110,8 @@ func init() {
 	checkCommand.PersistentFlags().String("only", "", "Run only specific tools by name (comma-separated, e.g. phpstan,eslint)")
 	checkCommand.PreRunE = func(cmd *cobra.Command, args []string) error {
 		reporter, _ := cmd.Flags().GetString("reporter")
-		if reporter != "summary" && reporter != "json" && reporter != "github" && reporter != "junit" && reporter != "markdown" && reporter != "" {
-			return fmt.Errorf("invalid reporter format: %s. Must be either 'summary', 'json', 'github', 'junit' or 'markdown'", reporter)
+		if err := validateReporter(reporter); err != nil {
+			return err
 		}
 
 		mode, _ := cmd.Flags().GetString("check-against")
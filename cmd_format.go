@@ -1,6 +1,9 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"sort"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -26,6 +29,14 @@ var formatCommand = &cobra.Command{
 		}
 
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		checkMode, _ := cmd.Flags().GetBool("check")
+		reportingFormat, _ := cmd.Flags().GetString("reporter")
+
+		if reportingFormat == "" {
+			reportingFormat = detectDefaultReporter()
+		}
+
+		result := tool.NewCheck()
 
 		var gr errgroup.Group
 
@@ -40,7 +51,7 @@ var formatCommand = &cobra.Command{
 		for _, tool := range tools {
 			tool := tool
 			gr.Go(func() error {
-				return tool.Format(cmd.Context(), *toolCfg, dryRun)
+				return tool.Format(cmd.Context(), result, *toolCfg, dryRun || checkMode)
 			})
 		}
 
@@ -48,12 +59,41 @@ var formatCommand = &cobra.Command{
 			return err
 		}
 
+		sort.Slice(result.Results, func(i, j int) bool {
+			return result.Results[i].Path < result.Results[j].Path
+		})
+
+		if checkMode {
+			if err := doCheckReport(result, reportingFormat); err != nil {
+				return err
+			}
+
+			if len(result.Results) > 0 {
+				os.Exit(1)
+			}
+
+			return nil
+		}
+
+		if dryRun {
+			for _, r := range result.Results {
+				fmt.Print(r.Diff)
+			}
+		}
+
 		return nil
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(formatCommand)
-	formatCommand.PersistentFlags().Bool("dry-run", false, "Dry run the formatting")
+	formatCommand.PersistentFlags().Bool("dry-run", false, "Print the changes as unified diff instead of writing them")
+	formatCommand.PersistentFlags().Bool("check", false, "Report the files which are not formatted and exit with a non-zero code, nothing is written")
+	formatCommand.PersistentFlags().String("reporter", "", "Reporting format of --check (summary, json, github, junit, markdown)")
 	formatCommand.PersistentFlags().String("only", "", "Run only specific tools by name (comma-separated, e.g. phpstan,eslint)")
+	formatCommand.PreRunE = func(cmd *cobra.Command, args []string) error {
+		reporter, _ := cmd.Flags().GetString("reporter")
+
+		return validateReporter(reporter)
+	}
 }
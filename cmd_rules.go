@@ -0,0 +1,177 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shopware/extension-verifier/internal/tool"
+	"github.com/shyim/go-version"
+	"github.com/spf13/cobra"
+)
+
+var rulesCommand = &cobra.Command{
+	Use:   "rules",
+	Short: "Describe the rules reported by the checks",
+}
+
+var rulesListCommand = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the rules with their fix safety and Shopware versions",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		shopwareVersion, _ := cmd.Flags().GetString("shopware-version")
+
+		if format != "text" && format != "json" {
+			return fmt.Errorf("invalid format %q, must be text or json", format)
+		}
+
+		rules, err := filterRules(tool.GetRules(), shopwareVersion)
+
+		if err != nil {
+			return err
+		}
+
+		if format == "json" {
+			return json.NewEncoder(os.Stdout).Encode(rules)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "IDENTIFIER\tFIX\tVERSIONS\tTITLE")
+
+		for _, rule := range rules {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", rule.Identifier, rule.FixSafety, rule.Versions, rule.Title)
+		}
+
+		return w.Flush()
+	},
+}
+
+var rulesExplainCommand = &cobra.Command{
+	Use:   "explain [identifier]",
+	Short: "Prints the description and the examples of a rule",
+	Long:  "Prints the description and the examples of a rule. The identifier can be given without the prefix of the tool, like sw-button for admintwiglinter/sw-button.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		rule, ok := tool.FindRule(args[0])
+
+		if !ok {
+			return fmt.Errorf("unknown rule %s, run rules list for all rules", args[0])
+		}
+
+		fmt.Printf("%s\n%s\n\n", rule.Identifier, rule.Title)
+		fmt.Printf("%s\n\n", rule.Description)
+		fmt.Printf("Shopware versions: %s\n", rule.Versions)
+		fmt.Printf("Fix: %s\n", rule.FixSafety)
+
+		for _, example := range rule.Examples {
+			fmt.Printf("\nBefore:\n%s\n\nAfter:\n%s\n", indentDiff(example.Before, "    "), indentDiff(example.After, "    "))
+		}
+
+		return nil
+	},
+}
+
+var rulesDocsCommand = &cobra.Command{
+	Use:   "docs",
+	Short: "Generates the markdown documentation of the rules",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		docs := rulesMarkdown(tool.GetRules())
+
+		if output == "" {
+			_, err := os.Stdout.WriteString(docs)
+			return err
+		}
+
+		return os.WriteFile(output, []byte(docs), 0644)
+	},
+}
+
+// filterRules returns the rules which apply to the Shopware version, all rules without version.
+func filterRules(rules []tool.RuleDescriptor, shopwareVersion string) ([]tool.RuleDescriptor, error) {
+	if shopwareVersion == "" {
+		return rules, nil
+	}
+
+	v, err := version.NewVersion(shopwareVersion)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var filtered []tool.RuleDescriptor
+
+	for _, rule := range rules {
+		if rule.Versions != "" {
+			constraint, err := version.NewConstraint(rule.Versions)
+
+			if err != nil {
+				return nil, fmt.Errorf("invalid versions %s of rule %s: %w", rule.Versions, rule.Identifier, err)
+			}
+
+			if !constraint.Check(v) {
+				continue
+			}
+		}
+
+		filtered = append(filtered, rule)
+	}
+
+	return filtered, nil
+}
+
+// rulesMarkdown renders the documentation of the rules.
+func rulesMarkdown(rules []tool.RuleDescriptor) string {
+	var builder strings.Builder
+
+	builder.WriteString("# Rules\n\n")
+	builder.WriteString("<!-- Generated by sw-extension-verifier rules docs, do not edit -->\n\n")
+	builder.WriteString("| Identifier | Fix | Shopware versions | Title |\n")
+	builder.WriteString("| --- | --- | --- | --- |\n")
+
+	for _, rule := range rules {
+		builder.WriteString(fmt.Sprintf("| [%s](#%s) | %s | `%s` | %s |\n", rule.Identifier, markdownAnchor(rule.Identifier), rule.FixSafety, rule.Versions, rule.Title))
+	}
+
+	for _, rule := range rules {
+		builder.WriteString(fmt.Sprintf("\n## %s\n\n**%s**\n\n%s\n\n", rule.Identifier, rule.Title, rule.Description))
+		builder.WriteString(fmt.Sprintf("- Shopware versions: `%s`\n- Fix: %s\n", rule.Versions, rule.FixSafety))
+
+		for _, example := range rule.Examples {
+			builder.WriteString(fmt.Sprintf("\nBefore:\n\n```html\n%s\n```\n\nAfter:\n\n```html\n%s\n```\n", example.Before, example.After))
+		}
+	}
+
+	return builder.String()
+}
+
+// markdownAnchor returns the anchor of a heading as generated by GitHub.
+func markdownAnchor(heading string) string {
+	var anchor strings.Builder
+
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			anchor.WriteRune(r)
+		case r == ' ':
+			anchor.WriteRune('-')
+		}
+	}
+
+	return anchor.String()
+}
+
+func init() {
+	rulesListCommand.Flags().String("format", "text", "Output format (text, json)")
+	rulesListCommand.Flags().String("shopware-version", "", "Only list the rules which apply to the Shopware version")
+	rulesDocsCommand.Flags().String("output", "", "Write the documentation to the file instead of stdout")
+	rulesCommand.AddCommand(rulesListCommand)
+	rulesCommand.AddCommand(rulesExplainCommand)
+	rulesCommand.AddCommand(rulesDocsCommand)
+	rootCmd.AddCommand(rulesCommand)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/shopware/extension-verifier/internal/tool"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRulesDocsAreUpToDate(t *testing.T) {
+	docs, err := os.ReadFile("docs/rules.md")
+	assert.NoError(t, err)
+
+	// Regenerate with: sw-extension-verifier rules docs --output docs/rules.md
+	assert.Equal(t, rulesMarkdown(tool.GetRules()), string(docs))
+}
+
+func TestFilterRules(t *testing.T) {
+	rules := []tool.RuleDescriptor{
+		{Identifier: "a", Versions: ">=6.7.0"},
+		{Identifier: "b"},
+	}
+
+	filtered, err := filterRules(rules, "6.6.10.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []tool.RuleDescriptor{{Identifier: "b"}}, filtered)
+
+	filtered, err = filterRules(rules, "6.7.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, rules, filtered)
+
+	_, err = filterRules(rules, "invalid")
+	assert.Error(t, err)
+}
+
+func TestMarkdownAnchor(t *testing.T) {
+	assert.Equal(t, "admintwiglintersw-button", markdownAnchor("admintwiglinter/sw-button"))
+}
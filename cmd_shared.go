@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/shopware/extension-verifier/internal/llm"
+	"github.com/shopware/extension-verifier/internal/source"
 	"github.com/shopware/extension-verifier/internal/tool"
 	"github.com/shopware/shopware-cli/extension"
+	"github.com/spf13/cobra"
 )
 
 func filterTools(tools []tool.Tool, only string) ([]tool.Tool, error) {
@@ -59,3 +62,106 @@ func getToolConfig(path string) (*tool.ToolConfig, error) {
 
 	return toolCfg, nil
 }
+
+func addShopwareSourceFlags(cmd *cobra.Command) {
+	cmd.Flags().String("shopware-source", "git", "Comma separated list of sources to load Shopware from in order (path:<dir>, archive:<file> or git), git is always the last fallback, paths can contain {version}")
+	cmd.Flags().String("cache-dir", source.DefaultCacheDir(), "Directory to cache Shopware sources")
+}
+
+func getShopwareSourceProvider(cmd *cobra.Command) (source.ChainProvider, error) {
+	spec, _ := cmd.Flags().GetString("shopware-source")
+	cacheDir, _ := cmd.Flags().GetString("cache-dir")
+
+	return source.NewProvider(spec, cacheDir)
+}
+
+func addLLMFlags(cmd *cobra.Command) {
+	cmd.Flags().String("model", "", "The model to use for the upgrade, defaults to the model of the provider")
+	cmd.Flags().String("provider", "ollama", "The provider to use for the upgrade, either a built-in provider (ollama, openai, anthropic, gemini, openrouter) or a profile of the llm.providers config section")
+	cmd.Flags().Float64("temperature", 0, "Sampling temperature, defaults to the temperature of the provider")
+	cmd.Flags().Int("max-tokens", 0, "Maximum number of tokens to generate, defaults to the limit of the provider")
+	cmd.Flags().Int("seed", 0, "Seed for deterministic sampling, if supported by the provider")
+	cmd.Flags().String("llm-cache", "", "Record LLM responses in this directory and reuse them for identical prompts")
+	cmd.Flags().String("llm-replay", "", "Serve LLM responses only from this directory of recorded responses, fails on prompts which have not been recorded")
+	cmd.Flags().Int("llm-retries", llm.DefaultClientConfig().MaxRetries, "Retries of LLM requests failing with a rate limit or server error")
+	cmd.Flags().Duration("llm-timeout", llm.DefaultClientConfig().Timeout, "Timeout of a single LLM request")
+	cmd.Flags().Int("concurrency", llm.DefaultClientConfig().Concurrency, "Number of files and LLM requests processed in parallel")
+}
+
+// getLLMClient creates the client of the selected provider and the options for its requests.
+func getLLMClient(cmd *cobra.Command, profiles map[string]llm.ProviderProfile) (llm.LLMClient, *llm.LLMOptions, error) {
+	provider, _ := cmd.Flags().GetString("provider")
+	cacheDir, _ := cmd.Flags().GetString("llm-cache")
+	replayDir, _ := cmd.Flags().GetString("llm-replay")
+
+	profile, err := llm.ResolveProfile(provider, profiles)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	options := &llm.LLMOptions{}
+	options.Model, _ = cmd.Flags().GetString("model")
+	options.MaxTokens, _ = cmd.Flags().GetInt("max-tokens")
+
+	if cmd.Flags().Changed("temperature") {
+		temperature, _ := cmd.Flags().GetFloat64("temperature")
+		options.Temperature = &temperature
+	}
+
+	if cmd.Flags().Changed("seed") {
+		seed, _ := cmd.Flags().GetInt("seed")
+		options.Seed = &seed
+	}
+
+	profile.ApplyDefaults(options)
+
+	if options.Model == "" {
+		return nil, nil, fmt.Errorf("no model configured for provider %s, please pass --model", provider)
+	}
+
+	if replayDir != "" {
+		return llm.NewReplayClient(provider, replayDir), options, nil
+	}
+
+	config := llm.DefaultClientConfig()
+	config.MaxRetries, _ = cmd.Flags().GetInt("llm-retries")
+	config.Timeout, _ = cmd.Flags().GetDuration("llm-timeout")
+	config.Concurrency, _ = cmd.Flags().GetInt("concurrency")
+
+	client, err := llm.NewClient(profile, config)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cacheDir != "" {
+		return llm.NewCachedClient(client, provider, cacheDir), options, nil
+	}
+
+	return client, options, nil
+}
+
+// extractCode returns the content of the code fence of a LLM answer.
+func extractCode(text string) (string, bool) {
+	if thinkEndIndex := strings.Index(text, "</think>"); thinkEndIndex != -1 {
+		text = text[thinkEndIndex+len("</think>"):]
+	}
+
+	start := strings.Index(text, "```")
+	end := strings.LastIndex(text, "```")
+
+	if start == -1 || end <= start {
+		return "", false
+	}
+
+	// Skip the language of the code fence
+	code := text[start+3 : end]
+	newline := strings.IndexByte(code, '\n')
+
+	if newline == -1 {
+		return "", false
+	}
+
+	return code[newline+1:], true
+}
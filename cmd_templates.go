@@ -0,0 +1,222 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/charmbracelet/log"
+	"github.com/shopware/extension-verifier/internal/source"
+	"github.com/shopware/extension-verifier/internal/tool"
+	"github.com/shopware/extension-verifier/internal/twig"
+	"github.com/shopware/shopware-cli/extension"
+	"github.com/spf13/cobra"
+)
+
+var templatesCommand = &cobra.Command{
+	Use:   "templates",
+	Short: "Analyze the storefront templates of an extension",
+}
+
+var templatesGraphCommand = &cobra.Command{
+	Use:   "graph [path]",
+	Short: "Prints the references between the storefront templates of the extension and the core templates",
+	Long: `Prints the extends, include and embed references, including their Shopware variants like sw_extends, between the storefront templates of the extension and the core templates.
+
+With --old-version and --new-version the blocks of the referenced core templates are compared between both Shopware versions
+and the templates affected by the core changes are marked.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		oldVersion, _ := cmd.Flags().GetString("old-version")
+		newVersion, _ := cmd.Flags().GetString("new-version")
+
+		if format != "dot" && format != "json" {
+			return fmt.Errorf("invalid format %q, must be dot or json", format)
+		}
+
+		if (oldVersion == "") != (newVersion == "") {
+			return fmt.Errorf("--old-version and --new-version must be used together")
+		}
+
+		ext, err := extension.GetExtensionByFolder(args[0])
+
+		if err != nil {
+			return err
+		}
+
+		toolCfg, err := tool.ConvertExtensionToToolConfig(ext)
+
+		if err != nil {
+			return err
+		}
+
+		templates, err := collectGraphTemplates(ext, toolCfg)
+
+		if err != nil {
+			return err
+		}
+
+		graph := twig.NewGraph(templates)
+
+		if oldVersion != "" {
+			provider, err := getShopwareSourceProvider(cmd)
+
+			if err != nil {
+				return err
+			}
+
+			oldDir, err := provider.Resolve(cmd.Context(), oldVersion)
+
+			if err != nil {
+				return err
+			}
+
+			newDir, err := provider.Resolve(cmd.Context(), newVersion)
+
+			if err != nil {
+				return err
+			}
+
+			if err := graph.Analyze(coreTemplateLoader(oldDir), coreTemplateLoader(newDir)); err != nil {
+				return err
+			}
+
+			affected := graph.Affected()
+
+			for _, tpl := range affected {
+				log.Info("Affected template", "file", tpl.File, "changed_blocks", len(tpl.Impact.ChangedBlocks), "removed_blocks", len(tpl.Impact.RemovedBlocks), "removed_templates", len(tpl.Impact.RemovedTemplates), "added", tpl.Impact.Added, "removed", tpl.Impact.Removed)
+			}
+
+			log.Info("Analyzed core changes", "old", oldVersion, "new", newVersion, "affected", len(affected), "templates", len(templates))
+		}
+
+		if format == "json" {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+
+			return encoder.Encode(graph)
+		}
+
+		fmt.Print(graph.DOT())
+
+		return nil
+	},
+}
+
+// collectGraphTemplates parses the storefront templates of the extension. The templates are named like
+// they are referenced in other templates, e.g. @MyExtension/storefront/page/index.html.twig.
+func collectGraphTemplates(ext extension.Extension, toolCfg *tool.ToolConfig) ([]*twig.GraphTemplate, error) {
+	name, err := ext.GetName()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var templates []*twig.GraphTemplate
+
+	for _, sourceDirectory := range toolCfg.SourceDirectories {
+		viewsFolder := path.Join(sourceDirectory, "Resources", "views")
+		twigFolder := path.Join(viewsFolder, "storefront")
+
+		if _, err := os.Stat(twigFolder); os.IsNotExist(err) {
+			continue
+		}
+
+		err = filepath.Walk(twigFolder, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() || filepath.Ext(file) != ".twig" {
+				return nil
+			}
+
+			content, err := os.ReadFile(file)
+
+			if err != nil {
+				return err
+			}
+
+			ast, err := twig.ParseTemplate(string(content))
+
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", file, err)
+			}
+
+			viewPath, err := filepath.Rel(viewsFolder, file)
+
+			if err != nil {
+				return err
+			}
+
+			relativePath, err := filepath.Rel(toolCfg.RootDir, file)
+
+			if err != nil {
+				return err
+			}
+
+			templates = append(templates, &twig.GraphTemplate{
+				Name:       "@" + name + "/" + filepath.ToSlash(viewPath),
+				File:       relativePath,
+				References: ast.References(),
+			})
+
+			return nil
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return templates, nil
+}
+
+// coreTemplateLoader loads the templates of the resolved Shopware sources in root and keeps them parsed.
+func coreTemplateLoader(root string) twig.TemplateLoader {
+	parsed := map[string]twig.NodeList{}
+
+	return func(name string) (twig.NodeList, error) {
+		if ast, ok := parsed[name]; ok {
+			return ast, nil
+		}
+
+		content, err := os.ReadFile(source.TemplatePath(root, name))
+
+		if os.IsNotExist(err) {
+			parsed[name] = nil
+			return nil, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		ast, err := twig.ParseTemplate(string(content))
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse core template %s: %w", name, err)
+		}
+
+		// An empty template exists as well
+		if ast == nil {
+			ast = twig.NodeList{}
+		}
+
+		parsed[name] = ast
+
+		return ast, nil
+	}
+}
+
+func init() {
+	templatesGraphCommand.Flags().String("format", "dot", "Output format (dot, json)")
+	templatesGraphCommand.Flags().String("old-version", "", "Shopware version the extension has been built for")
+	templatesGraphCommand.Flags().String("new-version", "", "Shopware version to find the affected templates for")
+	addShopwareSourceFlags(templatesGraphCommand)
+	templatesCommand.AddCommand(templatesGraphCommand)
+	rootCmd.AddCommand(templatesCommand)
+}
@@ -1,30 +1,35 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/shopware/extension-verifier/internal/diff"
 	"github.com/shopware/extension-verifier/internal/llm"
+	"github.com/shopware/extension-verifier/internal/source"
 	"github.com/shopware/extension-verifier/internal/tool"
 	"github.com/shopware/extension-verifier/internal/twig"
 	"github.com/shopware/shopware-cli/extension"
 	"github.com/shopware/shopware-cli/logging"
 	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
 )
 
 const systemPrompt = `
-You are a helper agent to help to upgrade Twig templates. I will give you the old and new template happend in the Software and as third the extended template. Apply the changes happen between old and new template to the extended template.
-- Do only the necessary changes to the extended template.
-- Do only modify the content inside the block and dont add new blocks
-- Please also only output the modified extended template nothing more.
+You are a helper agent to help to upgrade Twig templates. I will give you the content of a block in the old and new template of the Software and as third the content of the same block in my template, which is a modified copy of the old one. Apply the changes happen between old and new block to my block.
+- Do only the necessary changes to my block.
+- Do not add or remove {% block %} tags.
+- Please only output the modified content of my block inside a twig code fence, nothing more.
 - Adjust also HTML elements to be more accessibility friendly.
-- If in a {% block %} is {{ parent() }}, ignore it and dont modify the content of the block
 `
 
 var twigUpgradeCommand = &cobra.Command{
@@ -51,173 +56,539 @@ var twigUpgradeCommand = &cobra.Command{
 			return err
 		}
 
-		client, err := llm.NewLLMClient(cmd.Flag("provider").Value.String())
+		strategy := cmd.Flag("strategy").Value.String()
+
+		if strategy != "llm" && strategy != "merge" {
+			return fmt.Errorf("invalid strategy %q, must be llm or merge", strategy)
+		}
+
+		llmFallback, _ := cmd.Flags().GetBool("llm-fallback")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		reportFormat, _ := cmd.Flags().GetString("report")
+
+		if reportFormat != "" && reportFormat != "json" && reportFormat != "markdown" {
+			return fmt.Errorf("invalid report format %q, must be json or markdown", reportFormat)
+		}
+
+		var client llm.LLMClient
+		options := &llm.LLMOptions{}
+
+		if strategy == "llm" || llmFallback {
+			client, options, err = getLLMClient(cmd, toolCfg.Verifier.LLM.Providers)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		options.SystemPrompt = systemPrompt
+
+		provider, err := getShopwareSourceProvider(cmd)
 
 		if err != nil {
 			return err
 		}
 
-		options := &llm.LLMOptions{
-			Model:        cmd.Flag("model").Value.String(),
-			SystemPrompt: systemPrompt,
+		oldVersion, err := provider.Resolve(cmd.Context(), args[1])
+
+		if err != nil {
+			return err
+		}
+
+		newVersion, err := provider.Resolve(cmd.Context(), args[2])
+
+		if err != nil {
+			return err
 		}
 
+		var templates []string
+
 		for _, sourceDirectory := range toolCfg.SourceDirectories {
 			twigFolder := path.Join(sourceDirectory, "Resources", "views", "storefront")
 
 			if _, err := os.Stat(twigFolder); os.IsNotExist(err) {
+				continue
+			}
+
+			err = filepath.Walk(twigFolder, func(file string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+
+				if !info.IsDir() && filepath.Ext(file) == ".twig" {
+					templates = append(templates, file)
+				}
+
 				return nil
+			})
+
+			if err != nil {
+				return err
 			}
+		}
+
+		var report []*twigUpgradeReportEntry
+		var mu sync.Mutex
 
-			oldVersion, err := cloneShopwareStorefront(args[1])
+		upgradeFile := func(file string) error {
+			info, err := os.Stat(file)
 
 			if err != nil {
 				return err
 			}
 
-			defer func() {
-				if err := os.RemoveAll(oldVersion); err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to remove old version directory: %v\n", err)
-				}
-			}()
+			content, err := os.ReadFile(file)
+
+			if err != nil {
+				return err
+			}
 
-			newVersion, err := cloneShopwareStorefront(args[2])
+			ast, err := twig.ParseTemplate(string(content))
 
 			if err != nil {
 				return err
 			}
 
-			defer func() {
-				if err := os.RemoveAll(newVersion); err != nil {
-					fmt.Fprintf(os.Stderr, "Failed to remove new version directory: %v\n", err)
-				}
-			}()
+			extends := ast.Extends()
 
-			err = filepath.Walk(twigFolder, func(file string, info os.FileInfo, _ error) error {
-				if info.IsDir() {
-					return nil
-				}
+			if extends == nil {
+				return nil
+			}
 
-				if filepath.Ext(file) != ".twig" {
-					return nil
-				}
+			tpl := extends.Template
 
-				content, err := os.ReadFile(file)
+			oldTemplateText, err := os.ReadFile(source.TemplatePath(oldVersion, tpl))
 
-				if err != nil {
-					return err
-				}
+			if err != nil {
+				log.Warn("Template not found in old version", "template", tpl, "file", file)
+				return nil
+			}
 
-				ast, err := twig.ParseTemplate(string(content))
+			newTemplateText, err := os.ReadFile(source.TemplatePath(newVersion, tpl))
 
-				if err != nil {
-					return err
-				}
+			if err != nil {
+				log.Warn("Template not found in new version", "template", tpl, "file", file)
+				return nil
+			}
 
-				extends := ast.Extends()
+			log.Info("Processing file", "file", file)
 
-				if extends == nil {
-					return nil
-				}
+			relativePath, err := filepath.Rel(toolCfg.RootDir, file)
 
-				tpl := extends.Template
+			if err != nil {
+				return err
+			}
 
-				if tpl[0] == '@' {
-					tplParts := strings.Split(tpl, "/")
-					tplParts = tplParts[1:]
-					tpl = strings.Join(tplParts, "/")
-				}
+			entry := &twigUpgradeReportEntry{
+				File:       relativePath,
+				Template:   tpl,
+				OldVersion: args[1],
+				NewVersion: args[2],
+				Strategy:   strategy,
+			}
+			entry.ParentAdded, entry.ParentRemoved = diff.Stat(string(oldTemplateText), string(newTemplateText))
 
-				oldTemplateText, err := os.ReadFile(path.Join(oldVersion, "Resources", "views", tpl))
+			mu.Lock()
+			report = append(report, entry)
+			mu.Unlock()
 
-				if err != nil {
-					fmt.Printf("Template %s not found in old version\n", tpl)
-					return nil
-				}
+			if client != nil {
+				entry.Model = options.Model
+			}
 
-				newTemplateText, err := os.ReadFile(path.Join(newVersion, "Resources", "views", tpl))
+			ctx, usage := llm.TrackUsage(cmd.Context())
+			blockNames := ast.BlockNames()
 
-				if err != nil {
-					fmt.Printf("Template %s not found in new version\n", tpl)
-					return nil
-				}
+			var text string
 
-				var str strings.Builder
-				str.WriteString("This was the old template:\n")
-				str.WriteString("```twig\n")
-				str.WriteString(string(oldTemplateText))
-				str.WriteString("\n```\n")
-				str.WriteString("and this is the new one:\n")
-				str.WriteString("```twig\n")
-				str.WriteString(string(newTemplateText))
-				str.WriteString("\n```\n")
-				str.WriteString("and this is my template:\n")
-				str.WriteString("```twig\n")
-				str.WriteString(string(content))
-				str.WriteString("\n```")
+			if strategy == "merge" {
+				text, err = mergeTemplate(ctx, file, ast, string(oldTemplateText), string(newTemplateText), client, options, entry)
+			} else {
+				text, err = upgradeTemplate(ctx, file, ast, string(oldTemplateText), string(newTemplateText), client, options, entry)
+			}
 
-				log.Info("Processing file", "file", file)
+			entry.Usage = usage.Usage()
 
-				logging.FromContext(cmd.Context()).Debugf("Input to LLM for file %s:\n%s\n", file, str.String())
+			if err != nil {
+				return err
+			}
 
-				text, err := client.Generate(cmd.Context(), str.String(), options)
+			entry.Valid = true
 
-				if err != nil {
-					return err
-				}
+			if text == "" || strings.TrimSpace(text) == strings.TrimSpace(string(content)) {
+				return nil
+			}
 
-				if strings.Contains(text, "</think>") {
-					thinkEndIndex := strings.Index(text, "</think>")
-					if thinkEndIndex != -1 {
-						text = text[thinkEndIndex+len("</think>"):]
-					}
-				}
+			if err := validateUpgradedTemplate(text, blockNames, string(oldTemplateText), string(newTemplateText)); err != nil {
+				log.Warn("Rejected upgraded template", "file", file, "error", err)
+				entry.Valid = false
+				entry.Error = err.Error()
+				return nil
+			}
+
+			entry.Changed = true
+
+			if dryRun {
+				unified := diff.Unified("a/"+relativePath, "b/"+relativePath, string(content), text)
 
-				start := strings.Index(text, "```twig")
-				end := strings.LastIndex(text, "```")
+				mu.Lock()
+				defer mu.Unlock()
 
-				if start == -1 || end == -1 {
-					return nil
+				// The report is printed to stdout, so the diffs become part of it
+				if reportFormat != "" {
+					entry.Diff = unified
+				} else {
+					fmt.Print(unified)
 				}
 
-				text = strings.TrimPrefix(text[start+7:end], "\n")
+				return nil
+			}
+
+			target := file
+
+			if outputDir != "" {
+				target = filepath.Join(outputDir, relativePath)
 
-				contentStr := string(content)
-				if strings.TrimSpace(text) == strings.TrimSpace(contentStr) {
-					return nil
+				if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+					return err
 				}
+			}
+
+			return os.WriteFile(target, []byte(text), info.Mode().Perm())
+		}
+
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
 
-				return os.WriteFile(file, []byte(text), os.ModePerm)
+		var gr errgroup.Group
+		gr.SetLimit(max(concurrency, 1))
+
+		for _, file := range templates {
+			gr.Go(func() error {
+				return upgradeFile(file)
 			})
+		}
 
-			if err != nil {
-				return err
-			}
+		if err := gr.Wait(); err != nil {
+			return err
+		}
+
+		sort.Slice(report, func(i, j int) bool {
+			return report[i].File < report[j].File
+		})
+
+		if client != nil {
+			log.Info("LLM usage", "usage", client.Usage().String())
+		}
+
+		switch reportFormat {
+		case "json":
+			return doTwigUpgradeJSONReport(report)
+		case "markdown":
+			return doTwigUpgradeMarkdownReport(report)
 		}
 
 		return nil
 	},
 }
 
-func cloneShopwareStorefront(version string) (string, error) {
-	tempDir, err := os.MkdirTemp(os.TempDir(), "shopware")
+// upgradeTemplate lets the LLM apply the changes between the old and new core template to every block
+// of the extension template copying a changed core block.
+func upgradeTemplate(ctx context.Context, file string, ast twig.NodeList, oldTemplateText, newTemplateText string, client llm.LLMClient, options *llm.LLMOptions, entry *twigUpgradeReportEntry) (string, error) {
+	oldAst, err := twig.ParseTemplate(oldTemplateText)
 
 	if err != nil {
 		return "", err
 	}
 
-	git := exec.Command("git", "-c", "advice.detachedHead=false", "clone", "-q", "--branch", "v"+version, "https://github.com/shopware/storefront", tempDir, "--depth", "1")
-	output, err := git.CombinedOutput()
+	newAst, err := twig.ParseTemplate(newTemplateText)
 
 	if err != nil {
-		log.Print(string(output))
+		return "", err
+	}
+
+	blocks := twig.PairBlocks(oldAst, newAst, ast)
+
+	if len(blocks) == 0 {
+		return "", nil
+	}
+
+	for _, block := range blocks {
+		entry.ChangedBlocks = append(entry.ChangedBlocks, block.Name)
+
+		children, err := upgradeBlock(ctx, file, block, client, options)
+
+		if err != nil {
+			return "", err
+		}
+
+		if children == nil {
+			log.Warn("LLM returned no valid block content, keeping the block", "file", file, "block", block.Name)
+			continue
+		}
+
+		log.Info("Upgraded block", "file", file, "block", block.Name)
+		block.Block.Children = children
+	}
+
+	return ast.Dump(), nil
+}
+
+// mergeTemplate applies the changes between the old and new core template to the blocks of the extension
+// template with a three-way merge. Conflicting blocks are resolved by the LLM when a client is given,
+// otherwise they are written with conflict markers.
+func mergeTemplate(ctx context.Context, file string, ast twig.NodeList, oldTemplateText, newTemplateText string, client llm.LLMClient, options *llm.LLMOptions, entry *twigUpgradeReportEntry) (string, error) {
+	oldAst, err := twig.ParseTemplate(oldTemplateText)
+
+	if err != nil {
+		return "", err
+	}
+
+	newAst, err := twig.ParseTemplate(newTemplateText)
+
+	if err != nil {
+		return "", err
+	}
+
+	merges := twig.MergeBlocks(oldAst, newAst, ast)
+
+	if len(merges) == 0 {
+		return "", nil
+	}
+
+	for _, merge := range merges {
+		entry.ChangedBlocks = append(entry.ChangedBlocks, merge.Name)
+
+		if !merge.Conflict {
+			log.Info("Merged block", "file", file, "block", merge.Name)
+			continue
+		}
+
+		if client != nil {
+			children, err := upgradeBlock(ctx, file, merge, client, options)
+
+			if err != nil {
+				return "", err
+			}
+
+			if children != nil {
+				log.Info("Resolved conflicting block with LLM", "file", file, "block", merge.Name)
+				merge.Block.Children = children
+				continue
+			}
+		}
+
+		log.Warn("Conflicting block, please resolve the conflict markers", "file", file, "block", merge.Name)
+		entry.Conflicts = append(entry.Conflicts, merge.Name)
+		merge.Block.Children = twig.NodeList{&twig.TextNode{Text: merge.Merged}}
+	}
+
+	return ast.Dump(), nil
+}
+
+// upgradeBlock asks the LLM to apply the core changes to a block of the extension.
+// It returns nil when the answer is no valid block content.
+func upgradeBlock(ctx context.Context, file string, merge twig.BlockMerge, client llm.LLMClient, options *llm.LLMOptions) (twig.NodeList, error) {
+	var str strings.Builder
+	str.WriteString(fmt.Sprintf("This was the content of the block %s in the old template:\n", merge.Name))
+	str.WriteString("```twig\n")
+	str.WriteString(merge.Old)
+	str.WriteString("\n```\n")
+	str.WriteString("and this is the new one:\n")
+	str.WriteString("```twig\n")
+	str.WriteString(merge.New)
+	str.WriteString("\n```\n")
+	str.WriteString("and this is the content of the block in my template:\n")
+	str.WriteString("```twig\n")
+	str.WriteString(merge.Extension)
+	str.WriteString("\n```")
+
+	logging.FromContext(ctx).Debugf("Input to LLM for block %s in file %s:\n%s\n", merge.Name, file, str.String())
+
+	text, err := client.Generate(ctx, str.String(), options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	code, ok := extractCode(text)
+
+	if !ok {
+		return nil, nil
+	}
+
+	children, err := twig.ParseTemplate(code)
+
+	if err != nil {
+		return nil, nil
+	}
+
+	// The block tags are part of the answer
+	if nodes := children.RemoveWhitespace(); len(nodes) == 1 {
+		if block, ok := nodes[0].(*twig.BlockNode); ok && block.Name == merge.Name {
+			return block.Children, nil
+		}
+	}
+
+	return children, nil
+}
+
+// validateUpgradedTemplate ensures the upgraded template can be parsed and contains the same blocks as before.
+// Blocks may only be added when the new core template has them, and only be removed when the core removed them
+// between the old and new version.
+func validateUpgradedTemplate(text string, blockNames []string, oldTemplateText, newTemplateText string) error {
+	ast, err := twig.ParseTemplate(text)
+
+	if err != nil {
+		return fmt.Errorf("invalid twig: %w", err)
+	}
+
+	oldCore, err := coreBlockNames(oldTemplateText)
+
+	if err != nil {
+		return err
+	}
+
+	newCore, err := coreBlockNames(newTemplateText)
+
+	if err != nil {
+		return err
+	}
+
+	before := map[string]int{}
+	for _, name := range blockNames {
+		before[name]++
+	}
+
+	for _, name := range ast.BlockNames() {
+		if before[name] == 0 && !newCore[name] {
+			return fmt.Errorf("block %s has been added", name)
+		}
+
+		before[name]--
+	}
+
+	for _, name := range blockNames {
+		if before[name] > 0 && !(oldCore[name] && !newCore[name]) {
+			return fmt.Errorf("block %s has been removed", name)
+		}
+	}
+
+	return nil
+}
+
+func coreBlockNames(templateText string) (map[string]bool, error) {
+	ast, err := twig.ParseTemplate(templateText)
+
+	if err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, name := range ast.BlockNames() {
+		names[name] = true
+	}
+
+	return names, nil
+}
+
+// twigUpgradeReportEntry describes the upgrade of one extension template for the review report.
+type twigUpgradeReportEntry struct {
+	File     string `json:"file"`
+	Template string `json:"template"`
+	// Shopware versions of the parent template and the lines changed between both
+	OldVersion    string `json:"old_version"`
+	NewVersion    string `json:"new_version"`
+	ParentAdded   int    `json:"parent_added_lines"`
+	ParentRemoved int    `json:"parent_removed_lines"`
+	Strategy      string `json:"strategy"`
+	// Blocks changed in the parent template and overridden by the extension
+	ChangedBlocks []string  `json:"changed_blocks"`
+	Conflicts     []string  `json:"conflicts,omitempty"`
+	Model         string    `json:"model,omitempty"`
+	Usage         llm.Usage `json:"usage"`
+	Changed       bool      `json:"changed"`
+	Valid         bool      `json:"valid"`
+	Error         string    `json:"error,omitempty"`
+	// Unified diff of the upgrade in a dry run
+	Diff string `json:"diff,omitempty"`
+}
+
+func doTwigUpgradeJSONReport(report []*twigUpgradeReportEntry) error {
+	j, err := json.MarshalIndent(report, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stdout.Write(j); err != nil {
+		return fmt.Errorf("failed to write JSON output: %w", err)
+	}
+
+	return nil
+}
+
+func doTwigUpgradeMarkdownReport(report []*twigUpgradeReportEntry) error {
+	var builder strings.Builder
+
+	builder.WriteString("# Twig Upgrade\n\n")
+
+	builder.WriteString("| File | Parent | Changed blocks | Conflicts | Model | Tokens | Changed | Valid |\n")
+	builder.WriteString("| --- | --- | --- | --- | --- | --- | --- | --- |\n")
+
+	for _, entry := range report {
+		valid := "yes"
+
+		if !entry.Valid {
+			valid = "no: " + entry.Error
+		}
+
+		changed := "no"
+
+		if entry.Changed {
+			changed = "yes"
+		}
+
+		builder.WriteString(fmt.Sprintf("| %s | %s %s → %s (+%d -%d) | %s | %s | %s | %d | %s | %s |\n",
+			entry.File,
+			entry.Template,
+			entry.OldVersion,
+			entry.NewVersion,
+			entry.ParentAdded,
+			entry.ParentRemoved,
+			strings.Join(entry.ChangedBlocks, ", "),
+			strings.Join(entry.Conflicts, ", "),
+			entry.Model,
+			entry.Usage.Total(),
+			changed,
+			valid,
+		))
+	}
+
+	builder.WriteString("\n")
+
+	for _, entry := range report {
+		if entry.Diff == "" {
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("## %s\n\n```diff\n%s```\n\n", entry.File, entry.Diff))
+	}
+
+	if _, err := os.Stdout.Write([]byte(builder.String())); err != nil {
+		return fmt.Errorf("failed to write markdown output: %w", err)
 	}
 
-	return tempDir, err
+	return nil
 }
 
 func init() {
-	twigUpgradeCommand.Flags().String("model", "gemma3:4b", "The model to use for the upgrade")
-	twigUpgradeCommand.Flags().String("provider", "ollama", "The provider to use for the upgrade")
+	addLLMFlags(twigUpgradeCommand)
+	twigUpgradeCommand.Flags().String("strategy", "llm", "The upgrade strategy: llm rewrites the overridden blocks changed in the core, merge applies the core changes with a three-way merge of the blocks")
+	twigUpgradeCommand.Flags().Bool("llm-fallback", false, "Resolve conflicting blocks of the merge strategy with the LLM")
+	twigUpgradeCommand.Flags().Bool("dry-run", false, "Print the changes as unified diff instead of writing them")
+	twigUpgradeCommand.Flags().String("output-dir", "", "Write the upgraded templates into this directory instead of the extension")
+	twigUpgradeCommand.Flags().String("report", "", "Print a report of all upgraded templates (json, markdown)")
+	addShopwareSourceFlags(twigUpgradeCommand)
 	rootCmd.AddCommand(twigUpgradeCommand)
 }
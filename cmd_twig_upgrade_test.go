@@ -0,0 +1,29 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateUpgradedTemplate(t *testing.T) {
+	blocks := []string{"page", "page_title", "page_custom"}
+	oldCore := `{% block page %}{% block page_title %}{% endblock %}{% endblock %}`
+	newCore := `{% block page %}{% block page_title %}{% endblock %}{% block page_subtitle %}{% endblock %}{% endblock %}`
+
+	assert.NoError(t, validateUpgradedTemplate(`{% block page %}{% block page_title %}Title{% endblock %}{% block page_custom %}{% endblock %}{% endblock %}`, blocks, oldCore, newCore))
+
+	// The core added a nested block which the three-way merge copies into the template
+	assert.NoError(t, validateUpgradedTemplate(`{% block page %}{% block page_title %}Title{% endblock %}{% block page_subtitle %}{% endblock %}{% block page_custom %}{% endblock %}{% endblock %}`, blocks, oldCore, newCore))
+
+	assert.EqualError(t, validateUpgradedTemplate(`{% block page %}{% block page_title %}{% endblock %}{% block page_custom %}{% endblock %}{% block custom %}{% endblock %}{% endblock %}`, blocks, oldCore, newCore), "block custom has been added")
+	assert.EqualError(t, validateUpgradedTemplate(`{% block page %}{% block page_custom %}{% endblock %}{% endblock %}`, blocks, oldCore, newCore), "block page_title has been removed")
+
+	// Custom blocks of the extension must be kept
+	assert.EqualError(t, validateUpgradedTemplate(`{% block page %}{% block page_title %}Title{% endblock %}{% endblock %}`, blocks, oldCore, newCore), "block page_custom has been removed")
+
+	// Blocks removed in the core may be dropped
+	assert.NoError(t, validateUpgradedTemplate(`{% block page %}{% block page_custom %}{% endblock %}{% endblock %}`, blocks, oldCore, `{% block page %}{% endblock %}`))
+
+	assert.ErrorContains(t, validateUpgradedTemplate(`{% block page %}`, blocks, oldCore, newCore), "invalid twig")
+}
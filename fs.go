@@ -99,5 +99,10 @@ func copyFile(src, dst string) error {
 		return fmt.Errorf("failed to get source file info: %w", err)
 	}
 
-	return os.Chmod(dst, sourceInfo.Mode())
+	if err := os.Chmod(dst, sourceInfo.Mode()); err != nil {
+		return err
+	}
+
+	// Keep the modification time, checks compare the age of sources and builds
+	return os.Chtimes(dst, sourceInfo.ModTime(), sourceInfo.ModTime())
 }
@@ -0,0 +1,71 @@
+package admintwiglinter
+
+// ComponentMigration describes how the API of a removed administration component maps to its replacement.
+type ComponentMigration struct {
+	Replacement string
+	Changes     []string
+}
+
+// ComponentMigrations are keyed by the removed component, which is also the identifier of its CheckError.
+var ComponentMigrations = map[string]ComponentMigration{
+	"sw-alert": {
+		Replacement: "mt-banner",
+		Changes: []string{
+			"variant success is renamed to positive, error to critical and warning to attention, info stays the same",
+		},
+	},
+	"sw-button": {
+		Replacement: "mt-button",
+		Changes: []string{
+			"variant danger is renamed to critical",
+			"variant ghost is replaced by the boolean ghost property, ghost-danger by variant critical with ghost",
+			"the variants contrast and context are removed",
+			"the router-link property is removed, navigate with @click and $router.push instead",
+		},
+	},
+	"sw-card": {
+		Replacement: "mt-card",
+		Changes: []string{
+			"the aiBadge property is removed, render sw-ai-copilot-badge in the title slot instead",
+			"the contentPadding property is removed",
+		},
+	},
+	"sw-colorpicker": {
+		Replacement: "mt-colorpicker",
+		Changes: []string{
+			"value is renamed to model-value, v-model:value to v-model and update:value to update:model-value",
+			"the label slot is replaced by the label property",
+		},
+	},
+	"sw-datepicker": {
+		Replacement: "mt-datepicker",
+		Changes: []string{
+			"value is renamed to model-value, v-model:value to v-model and update:value to update:model-value",
+			"the label slot is replaced by the label property",
+		},
+	},
+	"sw-number-field": {
+		Replacement: "mt-number-field",
+		Changes: []string{
+			"value is renamed to model-value and v-model:value to v-model",
+			"the update:value event is replaced by the change event",
+			"the label slot is replaced by the label property",
+		},
+	},
+	"sw-password-field": {
+		Replacement: "mt-password-field",
+		Changes: []string{
+			"value is renamed to model-value, v-model:value to v-model and update:value to update:model-value",
+			"size medium is renamed to default",
+			"the isInvalid property and the base-field-mounted event are removed",
+			"the label and hint slots are replaced by the label and hint properties",
+		},
+	},
+	"sw-textarea-field": {
+		Replacement: "mt-textarea",
+		Changes: []string{
+			"value is renamed to model-value, v-model:value to v-model and update:value to update:model-value",
+			"the label slot is replaced by the label property",
+		},
+	},
+}
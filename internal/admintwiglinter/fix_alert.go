@@ -30,6 +30,22 @@ func (a AlertFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (a AlertFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-alert",
+		Title:       "Migrate sw-alert to mt-banner",
+		Description: "sw-alert is removed in Shopware 6.7. The fixer replaces it with mt-banner and renames the variants success, error and warning to positive, critical and attention.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-alert variant="success">Saved</sw-alert>`,
+				After:  `<mt-banner variant="positive">Saved</mt-banner>`,
+			},
+		},
+	}
+}
+
 func (a AlertFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-alert" {
@@ -35,6 +35,22 @@ func (b ButtonFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (b ButtonFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-button",
+		Title:       "Migrate sw-button to mt-button",
+		Description: "sw-button is removed in Shopware 6.7. The fixer replaces it with mt-button, renames the danger variant to critical and replaces the ghost variants by the ghost property. Navigation with router-link has to be reviewed.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-button variant="danger">Delete</sw-button>`,
+				After:  `<mt-button variant="critical">Delete</mt-button>`,
+			},
+		},
+	}
+}
+
 func (b ButtonFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-button" {
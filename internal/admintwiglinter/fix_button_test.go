@@ -17,6 +17,19 @@ func TestButtonFixer(t *testing.T) {
 			before:      `<sw-button>Save</sw-button>`,
 			after:       `<mt-button>Save</mt-button>`,
 		},
+		{
+			description: "component inside twig tags",
+			before:      `<div>{% if product %}<sw-button>Save</sw-button>{% else %}{% for action in actions %}<sw-button>Run</sw-button>{% endfor %}{% endif %}</div>`,
+			after: `<div>
+    {% if product %}
+        <mt-button>Save</mt-button>
+    {% else %}
+        {% for action in actions %}
+            <mt-button>Run</mt-button>
+        {% endfor %}
+    {% endif %}
+</div>`,
+		},
 		{
 			description: "remove variant ghost and add ghost attribute",
 			before:      `<sw-button variant="ghost">Save</sw-button>`,
@@ -32,6 +32,22 @@ func (c CardFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (c CardFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-card",
+		Title:       "Migrate sw-card to mt-card",
+		Description: "sw-card is removed in Shopware 6.7. The fixer replaces it with mt-card, removes the contentPadding property and moves the aiBadge into the title slot.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-card contentPadding="true">Content</sw-card>`,
+				After:  "<mt-card>Content</mt-card>",
+			},
+		},
+	}
+}
+
 func (c CardFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-card" {
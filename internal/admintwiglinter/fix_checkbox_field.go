@@ -34,6 +34,22 @@ func (c CheckboxFieldFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (c CheckboxFieldFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-checkbox-field",
+		Title:       "Migrate sw-checkbox-field to mt-checkbox",
+		Description: "sw-checkbox-field is removed in Shopware 6.7. The fixer replaces it with mt-checkbox and binds the checked property instead of value.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-checkbox-field v-model="active"/>`,
+				After:  `<mt-checkbox v-model:checked="active"/>`,
+			},
+		},
+	}
+}
+
 func (c CheckboxFieldFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-checkbox-field" {
@@ -75,7 +91,7 @@ func (c CheckboxFieldFixer) Fix(nodes []html.Node) error {
 							if attr.Key == "#label" || attr.Key == "v-slot:label" {
 								var sb strings.Builder
 								for _, inner := range elem.Children {
-									sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+									sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 								}
 								labelText = sb.String()
 								goto SkipChild
@@ -32,6 +32,22 @@ func (c ColorpickerFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (c ColorpickerFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-colorpicker",
+		Title:       "Migrate sw-colorpicker to mt-colorpicker",
+		Description: "sw-colorpicker is removed in Shopware 6.7. The fixer replaces it with mt-colorpicker, binds model-value instead of value and moves the label slot into the label property.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-colorpicker v-model:value="color"/>`,
+				After:  `<mt-colorpicker v-model="color"/>`,
+			},
+		},
+	}
+}
+
 func (c ColorpickerFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-colorpicker" {
@@ -71,7 +87,7 @@ func (c ColorpickerFixer) Fix(nodes []html.Node) error {
 								if attr.Key == "#label" {
 									var sb strings.Builder
 									for _, inner := range elem.Children {
-										sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+										sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 									}
 									label = sb.String()
 								}
@@ -32,6 +32,22 @@ func (d DatepickerFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (d DatepickerFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-datepicker",
+		Title:       "Migrate sw-datepicker to mt-datepicker",
+		Description: "sw-datepicker is removed in Shopware 6.7. The fixer replaces it with mt-datepicker, binds model-value instead of value and moves the label slot into the label property.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: "<sw-datepicker><template #label>Release date</template></sw-datepicker>",
+				After:  `<mt-datepicker label="Release date"></mt-datepicker>`,
+			},
+		},
+	}
+}
+
 func (d DatepickerFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-datepicker" {
@@ -73,7 +89,7 @@ func (d DatepickerFixer) Fix(nodes []html.Node) error {
 								if attr.Key == "#label" {
 									var sb strings.Builder
 									for _, inner := range elem.Children {
-										sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+										sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 									}
 									label = sb.String()
 									goto SkipChild
@@ -32,6 +32,22 @@ func (e EmailFieldFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (e EmailFieldFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-email-field",
+		Title:       "Migrate sw-email-field to mt-email-field",
+		Description: "sw-email-field is removed in Shopware 6.7. The fixer replaces it with mt-email-field, binds model-value instead of value and moves the label slot into the label property.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-email-field v-model:value="email"/>`,
+				After:  `<mt-email-field v-model="email"/>`,
+			},
+		},
+	}
+}
+
 func (e EmailFieldFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-email-field" {
@@ -77,7 +93,7 @@ func (e EmailFieldFixer) Fix(nodes []html.Node) error {
 							if attr.Key == "#label" {
 								var sb strings.Builder
 								for _, inner := range elem.Children {
-									sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+									sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 								}
 								label = sb.String()
 								goto SkipChild
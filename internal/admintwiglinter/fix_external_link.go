@@ -30,6 +30,22 @@ func (e ExternalLinkFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (e ExternalLinkFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-external-link",
+		Title:       "Migrate sw-external-link to mt-external-link",
+		Description: "sw-external-link is removed in Shopware 6.7. The fixer replaces it with mt-external-link and removes the icon property, the icon is always shown.",
+		Versions:    shopware67Versions,
+		Safety:      FixSafe,
+		Examples: []Example{
+			{
+				Before: "<sw-external-link icon>Documentation</sw-external-link>",
+				After:  "<mt-external-link>Documentation</mt-external-link>",
+			},
+		},
+	}
+}
+
 func (e ExternalLinkFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-external-link" {
@@ -32,6 +32,25 @@ func (i IconFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (i IconFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-icon",
+		Title:       "Migrate sw-icon to mt-icon",
+		Description: "sw-icon is removed in Shopware 6.7. The fixer replaces it with mt-icon and converts the small and large properties to the size property, icons without size get the former default of 24px.",
+		Versions:    shopware67Versions,
+		Safety:      FixSafe,
+		Examples: []Example{
+			{
+				Before: `<sw-icon name="regular-times-s" small/>`,
+				After: `<mt-icon
+    name="regular-times-s"
+    size="16px"
+/>`,
+			},
+		},
+	}
+}
+
 func (i IconFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-icon" {
@@ -30,6 +30,22 @@ func (l LoaderFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (l LoaderFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-loader",
+		Title:       "Migrate sw-loader to mt-loader",
+		Description: "sw-loader is removed in Shopware 6.7. The fixer replaces it with mt-loader.",
+		Versions:    shopware67Versions,
+		Safety:      FixSafe,
+		Examples: []Example{
+			{
+				Before: "<sw-loader />",
+				After:  "<mt-loader/>",
+			},
+		},
+	}
+}
+
 func (l LoaderFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-loader" {
@@ -32,6 +32,22 @@ func (n NumberFieldFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (n NumberFieldFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-number-field",
+		Title:       "Migrate sw-number-field to mt-number-field",
+		Description: "sw-number-field is removed in Shopware 6.7. The fixer replaces it with mt-number-field, binds model-value instead of value and moves the label slot into the label property. The update:value event is replaced by the change event.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-number-field v-model:value="stock"/>`,
+				After:  `<mt-number-field v-model="stock"/>`,
+			},
+		},
+	}
+}
+
 func (n NumberFieldFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-number-field" {
@@ -74,7 +90,7 @@ func (n NumberFieldFixer) Fix(nodes []html.Node) error {
 							if attr.Key == "#label" {
 								var sb strings.Builder
 								for _, inner := range elem.Children {
-									sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+									sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 								}
 								label = sb.String()
 								goto SkipChild
@@ -32,6 +32,22 @@ func (p PasswordFieldFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (p PasswordFieldFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-password-field",
+		Title:       "Migrate sw-password-field to mt-password-field",
+		Description: "sw-password-field is removed in Shopware 6.7. The fixer replaces it with mt-password-field, binds model-value instead of value and moves the label and hint slots into properties.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-password-field v-model:value="password"/>`,
+				After:  `<mt-password-field v-model="password"/>`,
+			},
+		},
+	}
+}
+
 func (p PasswordFieldFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-password-field" {
@@ -80,7 +96,7 @@ func (p PasswordFieldFixer) Fix(nodes []html.Node) error {
 							if attr.Key == "#label" {
 								var sb strings.Builder
 								for _, inner := range elem.Children {
-									sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+									sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 								}
 								label = strings.Replace(sb.String(), "Label", "label", 1)
 								goto SkipChild
@@ -88,7 +104,7 @@ func (p PasswordFieldFixer) Fix(nodes []html.Node) error {
 							if attr.Key == "#hint" {
 								var sb strings.Builder
 								for _, inner := range elem.Children {
-									sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+									sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 								}
 								hint = strings.Replace(sb.String(), "Hint", "hint", 1)
 								goto SkipChild
@@ -30,6 +30,22 @@ func (p ProgressBarFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (p ProgressBarFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-progress-bar",
+		Title:       "Migrate sw-progress-bar to mt-progress-bar",
+		Description: "sw-progress-bar is removed in Shopware 6.7. The fixer replaces it with mt-progress-bar and binds model-value instead of value.",
+		Versions:    shopware67Versions,
+		Safety:      FixSafe,
+		Examples: []Example{
+			{
+				Before: `<sw-progress-bar @update:value="onProgress"/>`,
+				After:  `<mt-progress-bar @update:model-value="onProgress"/>`,
+			},
+		},
+	}
+}
+
 func (p ProgressBarFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-progress-bar" {
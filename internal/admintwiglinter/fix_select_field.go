@@ -33,6 +33,22 @@ func (s SelectFieldFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (s SelectFieldFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-select-field",
+		Title:       "Migrate sw-select-field to mt-select",
+		Description: "sw-select-field is removed in Shopware 6.7. The fixer replaces it with mt-select, binds model-value instead of value and converts the options. Options passed as slot content have to be reviewed.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-select-field v-model:value="selected"/>`,
+				After:  `<mt-select v-model="selected"/>`,
+			},
+		},
+	}
+}
+
 func (s SelectFieldFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-select-field" {
@@ -83,7 +99,7 @@ func (s SelectFieldFixer) Fix(nodes []html.Node) error {
 								if attr.Key == "#label" || attr.Key == "v-slot:label" {
 									var sb strings.Builder
 									for _, inner := range elem.Children {
-										sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+										sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 									}
 									labelText = sb.String()
 									goto SkipChild
@@ -105,7 +121,7 @@ func (s SelectFieldFixer) Fix(nodes []html.Node) error {
 						// Get option label from inner text.
 						var sb strings.Builder
 						for _, inner := range elem.Children {
-							sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+							sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 						}
 						opt["label"] = sb.String()
 						optionObjects = append(optionObjects, opt)
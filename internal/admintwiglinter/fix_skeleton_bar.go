@@ -30,6 +30,22 @@ func (s SkeletonBarFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (s SkeletonBarFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-skeleton-bar",
+		Title:       "Migrate sw-skeleton-bar to mt-skeleton-bar",
+		Description: "sw-skeleton-bar is removed in Shopware 6.7. The fixer replaces it with mt-skeleton-bar.",
+		Versions:    shopware67Versions,
+		Safety:      FixSafe,
+		Examples: []Example{
+			{
+				Before: "<sw-skeleton-bar>Loading</sw-skeleton-bar>",
+				After:  "<mt-skeleton-bar>Loading</mt-skeleton-bar>",
+			},
+		},
+	}
+}
+
 func (s SkeletonBarFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-skeleton-bar" {
@@ -32,6 +32,25 @@ func (s SwitchFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (s SwitchFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-switch-field",
+		Title:       "Migrate sw-switch-field to mt-switch",
+		Description: "sw-switch-field is removed in Shopware 6.7. The fixer replaces it with mt-switch, binds checked instead of value, renames noMarginTop to removeTopMargin and moves the label slot into the label property.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-switch-field v-model:value="active" noMarginTop/>`,
+				After: `<mt-switch
+    v-model="active"
+    removeTopMargin
+/>`,
+			},
+		},
+	}
+}
+
 func (s SwitchFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-switch-field" {
@@ -72,7 +91,7 @@ func (s SwitchFixer) Fix(nodes []html.Node) error {
 							if attr.Key == "#label" {
 								var sb strings.Builder
 								for _, inner := range elem.Children {
-									sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+									sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 								}
 								labelText = sb.String()
 								goto SkipChild
@@ -32,6 +32,22 @@ func (t TextFieldFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (t TextFieldFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-text-field",
+		Title:       "Migrate sw-text-field to mt-text-field",
+		Description: "sw-text-field is removed in Shopware 6.7. The fixer replaces it with mt-text-field, binds model-value instead of value and moves the label slot into the label property.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-text-field v-model:value="name"/>`,
+				After:  `<mt-text-field v-model="name"/>`,
+			},
+		},
+	}
+}
+
 func (t TextFieldFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-text-field" {
@@ -78,7 +94,7 @@ func (t TextFieldFixer) Fix(nodes []html.Node) error {
 							if attr.Key == "#label" {
 								var sb strings.Builder
 								for _, inner := range elem.Children {
-									sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+									sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 								}
 								label = sb.String()
 								goto SkipChild
@@ -32,6 +32,22 @@ func (t TextareaFieldFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (t TextareaFieldFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-textarea-field",
+		Title:       "Migrate sw-textarea-field to mt-textarea",
+		Description: "sw-textarea-field is removed in Shopware 6.7. The fixer replaces it with mt-textarea, binds model-value instead of value and moves the label slot into the label property.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: "<sw-textarea-field><template #label>Description</template></sw-textarea-field>",
+				After:  `<mt-textarea label="Description"></mt-textarea>`,
+			},
+		},
+	}
+}
+
 func (t TextareaFieldFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-textarea-field" {
@@ -71,7 +87,7 @@ func (t TextareaFieldFixer) Fix(nodes []html.Node) error {
 							if attr.Key == "#label" {
 								var sb strings.Builder
 								for _, inner := range element.Children {
-									sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+									sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 								}
 								label = sb.String()
 								goto SkipChild
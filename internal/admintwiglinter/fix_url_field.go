@@ -32,6 +32,22 @@ func (u UrlFieldFixer) Supports(v *version.Version) bool {
 	return shopware67Constraint.Check(v)
 }
 
+func (u UrlFieldFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-url-field",
+		Title:       "Migrate sw-url-field to mt-url-field",
+		Description: "sw-url-field is removed in Shopware 6.7. The fixer replaces it with mt-url-field, binds model-value instead of value and moves the label and hint slots into properties.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-url-field v-model:value="url"/>`,
+				After:  `<mt-url-field v-model="url"/>`,
+			},
+		},
+	}
+}
+
 func (u UrlFieldFixer) Fix(nodes []html.Node) error {
 	html.TraverseNode(nodes, func(node *html.ElementNode) {
 		if node.Tag == "sw-url-field" {
@@ -71,7 +87,7 @@ func (u UrlFieldFixer) Fix(nodes []html.Node) error {
 							if attr.Key == "#label" {
 								var sb strings.Builder
 								for _, inner := range elem.Children {
-									sb.WriteString(strings.TrimSpace(inner.Dump(0)))
+									sb.WriteString(strings.TrimSpace(inner.Dump(0, html.DefaultFormatOptions())))
 								}
 								label = sb.String()
 								goto SkipChild
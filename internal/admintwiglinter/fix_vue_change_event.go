@@ -0,0 +1,96 @@
+package admintwiglinter
+
+import (
+	"fmt"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/shyim/go-version"
+)
+
+type ChangeEventFixer struct{}
+
+func init() {
+	AddFixer(ChangeEventFixer{})
+}
+
+// changeListener returns the model of the component when the listener handles the change event of its v-model.
+func changeListener(node *html.ElementNode, attr html.Attribute) (vueModel, bool) {
+	model, ok := vueModelComponents[node.Tag]
+	if !ok || model.event != "change" {
+		return vueModel{}, false
+	}
+
+	event, _, ok := vueListener(attr.Key)
+
+	return model, ok && event == "change"
+}
+
+// listensToUpdate reports whether the element already has a listener for the update event of the prop.
+func listensToUpdate(node *html.ElementNode, prop string) bool {
+	return hasAttribute(node, func(attr html.Attribute) bool {
+		event, _, ok := vueListener(attr.Key)
+		return ok && event == "update:"+prop
+	})
+}
+
+func (c ChangeEventFixer) Check(nodes []html.Node) []CheckError {
+	var errs []CheckError
+	html.TraverseNode(nodes, func(node *html.ElementNode) {
+		for _, attrNode := range node.Attributes {
+			attr, ok := attrNode.(html.Attribute)
+			if !ok {
+				continue
+			}
+
+			if model, ok := changeListener(node, attr); ok {
+				errs = append(errs, CheckError{
+					Message:    fmt.Sprintf("%s emits update:%s instead of change in Vue 3, use @update:%s instead.", node.Tag, model.prop, model.prop),
+					Severity:   "error",
+					Identifier: "vue-change-event",
+					Line:       node.Line,
+				})
+			}
+		}
+	})
+	return errs
+}
+
+func (c ChangeEventFixer) Supports(version *version.Version) bool {
+	return shopware67Constraint.Check(version)
+}
+
+func (c ChangeEventFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "vue-change-event",
+		Title:       "Listen to update events instead of change",
+		Description: "Administration components which used the change event for v-model in Vue 2 emit update:value in Vue 3. The fixer renames the change listeners of these components, unless the update event is handled already.",
+		Versions:    shopware67Versions,
+		Safety:      FixSafe,
+		Examples: []Example{
+			{
+				Before: `<sw-single-select @change="onChangeManufacturer"/>`,
+				After:  `<sw-single-select @update:value="onChangeManufacturer"/>`,
+			},
+		},
+	}
+}
+
+func (c ChangeEventFixer) Fix(nodes []html.Node) error {
+	html.TraverseNode(nodes, func(node *html.ElementNode) {
+		model, ok := vueModelComponents[node.Tag]
+
+		// Both listeners cannot be merged automatically
+		if !ok || listensToUpdate(node, model.prop) {
+			return
+		}
+
+		updateAttributes(node, func(attr html.Attribute) (html.Attribute, bool) {
+			if _, ok := changeListener(node, attr); ok {
+				_, modifiers, _ := vueListener(attr.Key)
+				attr.Key = "@update:" + model.prop + modifiers
+			}
+			return attr, true
+		})
+	})
+	return nil
+}
@@ -0,0 +1,57 @@
+package admintwiglinter
+
+import (
+	"testing"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeEventFixer(t *testing.T) {
+	cases := []struct {
+		description string
+		before      string
+		after       string
+	}{
+		{
+			description: "replace change listener",
+			before:      `<sw-single-select @change="onChange"/>`,
+			after:       `<sw-single-select @update:value="onChange"/>`,
+		},
+		{
+			description: "replace v-on listener with modifiers",
+			before:      `<sw-entity-multi-select v-on:change.once="onChange"/>`,
+			after:       `<sw-entity-multi-select @update:entityCollection.once="onChange"/>`,
+		},
+		{
+			description: "keep change listener of components emitting input",
+			before:      `<sw-text-editor @change="onChange"/>`,
+			after:       `<sw-text-editor @change="onChange"/>`,
+		},
+		{
+			description: "keep both listeners when the update event is handled already",
+			before:      `<sw-single-select @change="onChange" @update:value="onUpdate"/>`,
+			after: `<sw-single-select
+    @change="onChange"
+    @update:value="onUpdate"
+/>`,
+		},
+	}
+
+	for _, c := range cases {
+		newStr, err := runFixerOnString(ChangeEventFixer{}, c.before)
+		assert.NoError(t, err, c.description)
+		assert.Equal(t, c.after, newStr, c.description)
+	}
+}
+
+func TestChangeEventFixerCheck(t *testing.T) {
+	nodes, err := html.NewParser("<div>\n    <sw-single-select @change=\"onChange\"/>\n    <select @change=\"onChange\"></select>\n</div>")
+	assert.NoError(t, err)
+
+	errs := ChangeEventFixer{}.Check(nodes)
+
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "vue-change-event", errs[0].Identifier)
+	assert.Equal(t, 2, errs[0].Line)
+}
@@ -0,0 +1,58 @@
+package admintwiglinter
+
+import (
+	"fmt"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/shyim/go-version"
+)
+
+type FilterFixer struct{}
+
+func init() {
+	AddFixer(FilterFixer{})
+}
+
+func (f FilterFixer) Check(nodes []html.Node) []CheckError {
+	var errs []CheckError
+	eachVueExpression(nodes, func(key, expression string, line int) {
+		if key != "" && !isVueBinding(key) {
+			return
+		}
+
+		for _, name := range vueFilters(expression) {
+			errs = append(errs, CheckError{
+				Message:    fmt.Sprintf("The filter %s is removed in Vue 3, call Shopware.Filter.getByName('%s') in a computed property instead.", name, name),
+				Severity:   "error",
+				Identifier: "vue-filter",
+				Line:       line,
+			})
+		}
+	})
+	return errs
+}
+
+func (f FilterFixer) Supports(version *version.Version) bool {
+	return shopware67Constraint.Check(version)
+}
+
+func (f FilterFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "vue-filter",
+		Title:       "Replace filters by function calls",
+		Description: "Filters are removed in Vue 3. The filter has to be provided to the component, like with a computed property returning Shopware.Filter.getByName('currency'), and called as function in the template.",
+		Versions:    shopware67Versions,
+		Safety:      FixManual,
+		Examples: []Example{
+			{
+				Before: "<span>{{ price | currency('EUR') }}</span>",
+				After:  "<span>{{ currencyFilter(price, 'EUR') }}</span>",
+			},
+		},
+	}
+}
+
+// Fix does not change the template, the filter has to be made available to the component first.
+func (f FilterFixer) Fix(nodes []html.Node) error {
+	return nil
+}
@@ -0,0 +1,44 @@
+package admintwiglinter
+
+import (
+	"testing"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVueFilters(t *testing.T) {
+	cases := []struct {
+		expression string
+		filters    []string
+	}{
+		{expression: " price | currency('EUR') ", filters: []string{"currency"}},
+		{expression: "date | date({ hour: '2-digit' }) | truncate", filters: []string{"date", "truncate"}},
+		{expression: "a || b", filters: nil},
+		{expression: "format(a | b)", filters: nil},
+		{expression: "'a | b' + label", filters: nil},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.filters, vueFilters(c.expression), c.expression)
+	}
+}
+
+func TestFilterFixerCheck(t *testing.T) {
+	nodes, err := html.NewParser("<div :title=\"name | truncate\" @click=\"a | b\">\n    {{ price | currency }}\n</div>")
+	assert.NoError(t, err)
+
+	errs := FilterFixer{}.Check(nodes)
+
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "vue-filter", errs[0].Identifier)
+	assert.Contains(t, errs[0].Message, "truncate")
+	assert.Equal(t, 1, errs[0].Line)
+	assert.Contains(t, errs[1].Message, "currency")
+	assert.Equal(t, 2, errs[1].Line)
+
+	// Filters are not fixed automatically
+	newStr, err := runFixerOnString(FilterFixer{}, "{{ price | currency }}")
+	assert.NoError(t, err)
+	assert.Equal(t, "{{ price | currency }}", newStr)
+}
@@ -0,0 +1,95 @@
+package admintwiglinter
+
+import (
+	"strings"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/shyim/go-version"
+)
+
+type ListenersFixer struct{}
+
+func init() {
+	AddFixer(ListenersFixer{})
+}
+
+// isListenersForwarding reports whether the attribute forwards all listeners to the element.
+func isListenersForwarding(attr html.Attribute) bool {
+	return (attr.Key == "v-on" || attr.Key == "v-on:") && strings.TrimSpace(attr.Value) == "$listeners"
+}
+
+// isAttrsForwarding reports whether the attribute forwards all attributes to the element.
+func isAttrsForwarding(attr html.Attribute) bool {
+	return (attr.Key == "v-bind" || attr.Key == "v-bind:") && strings.TrimSpace(attr.Value) == "$attrs"
+}
+
+func (l ListenersFixer) Check(nodes []html.Node) []CheckError {
+	var errs []CheckError
+	eachVueExpression(nodes, func(key, expression string, line int) {
+		for _, match := range vueInstancePropertyRegex.FindAllStringSubmatch(expression, -1) {
+			if match[1] != "listeners" {
+				continue
+			}
+
+			message := "$listeners is removed in Vue 3, listeners are part of $attrs as onEvent properties."
+			if strings.HasPrefix(key, "v-on") {
+				message = "$listeners is removed in Vue 3, listeners are forwarded with v-bind=\"$attrs\"."
+			}
+
+			errs = append(errs, CheckError{
+				Message:    message,
+				Severity:   "error",
+				Identifier: "vue-listeners",
+				Line:       line,
+			})
+		}
+	})
+	return errs
+}
+
+func (l ListenersFixer) Supports(version *version.Version) bool {
+	return shopware67Constraint.Check(version)
+}
+
+func (l ListenersFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "vue-listeners",
+		Title:       "Replace $listeners by $attrs",
+		Description: "$listeners is removed in Vue 3, the listeners are part of $attrs. The fixer replaces v-on=\"$listeners\" by v-bind=\"$attrs\", other usages have to be migrated to the onEvent properties of $attrs.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-button v-on="$listeners">Save</sw-button>`,
+				After:  `<sw-button v-bind="$attrs">Save</sw-button>`,
+			},
+		},
+	}
+}
+
+// Fix replaces v-on="$listeners" by v-bind="$attrs", other usages of $listeners have to be migrated manually.
+func (l ListenersFixer) Fix(nodes []html.Node) error {
+	html.TraverseNode(nodes, func(node *html.ElementNode) {
+		if !hasAttribute(node, isListenersForwarding) {
+			return
+		}
+
+		forwarded := hasAttribute(node, isAttrsForwarding)
+
+		updateAttributes(node, func(attr html.Attribute) (html.Attribute, bool) {
+			if !isListenersForwarding(attr) {
+				return attr, true
+			}
+
+			if forwarded {
+				return attr, false
+			}
+
+			forwarded = true
+			attr.Key = "v-bind"
+			attr.Value = "$attrs"
+			return attr, true
+		})
+	})
+	return nil
+}
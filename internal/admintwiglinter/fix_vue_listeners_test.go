@@ -0,0 +1,50 @@
+package admintwiglinter
+
+import (
+	"testing"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenersFixer(t *testing.T) {
+	cases := []struct {
+		description string
+		before      string
+		after       string
+	}{
+		{
+			description: "forward attributes instead of listeners",
+			before:      `<sw-button v-on="$listeners">Save</sw-button>`,
+			after:       `<sw-button v-bind="$attrs">Save</sw-button>`,
+		},
+		{
+			description: "remove listeners when attributes are forwarded",
+			before:      `<sw-button v-bind="$attrs" v-on="$listeners">Save</sw-button>`,
+			after:       `<sw-button v-bind="$attrs">Save</sw-button>`,
+		},
+		{
+			description: "keep other usages",
+			before:      `<sw-button v-if="$listeners.click">Save</sw-button>`,
+			after:       `<sw-button v-if="$listeners.click">Save</sw-button>`,
+		},
+	}
+
+	for _, c := range cases {
+		newStr, err := runFixerOnString(ListenersFixer{}, c.before)
+		assert.NoError(t, err, c.description)
+		assert.Equal(t, c.after, newStr, c.description)
+	}
+}
+
+func TestListenersFixerCheck(t *testing.T) {
+	nodes, err := html.NewParser("<div v-on=\"$listeners\">\n    {{ $listeners.click ? 'a' : 'b' }}\n</div>")
+	assert.NoError(t, err)
+
+	errs := ListenersFixer{}.Check(nodes)
+
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "vue-listeners", errs[0].Identifier)
+	assert.Contains(t, errs[0].Message, "v-bind=\"$attrs\"")
+	assert.Equal(t, 2, errs[1].Line)
+}
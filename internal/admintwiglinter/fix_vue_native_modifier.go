@@ -0,0 +1,85 @@
+package admintwiglinter
+
+import (
+	"strings"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/shyim/go-version"
+)
+
+type NativeModifierFixer struct{}
+
+func init() {
+	AddFixer(NativeModifierFixer{})
+}
+
+// withoutNativeModifier returns the listener attribute without the .native modifier.
+func withoutNativeModifier(key string) (string, bool) {
+	_, modifiers, ok := vueListener(key)
+	if !ok || !strings.Contains(modifiers+".", ".native.") {
+		return key, false
+	}
+
+	prefix := strings.TrimSuffix(key, modifiers)
+
+	var kept []string
+	for _, modifier := range strings.Split(modifiers[1:], ".") {
+		if modifier != "native" {
+			kept = append(kept, modifier)
+		}
+	}
+
+	if len(kept) == 0 {
+		return prefix, true
+	}
+
+	return prefix + "." + strings.Join(kept, "."), true
+}
+
+func (n NativeModifierFixer) Check(nodes []html.Node) []CheckError {
+	var errs []CheckError
+	html.TraverseNode(nodes, func(node *html.ElementNode) {
+		if hasAttribute(node, func(attr html.Attribute) bool {
+			_, ok := withoutNativeModifier(attr.Key)
+			return ok
+		}) {
+			errs = append(errs, CheckError{
+				Message:    "The .native modifier is removed in Vue 3, listeners which are not declared in emits are added to the root element of the component.",
+				Severity:   "error",
+				Identifier: "vue-native-modifier",
+				Line:       node.Line,
+			})
+		}
+	})
+	return errs
+}
+
+func (n NativeModifierFixer) Supports(version *version.Version) bool {
+	return shopware67Constraint.Check(version)
+}
+
+func (n NativeModifierFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "vue-native-modifier",
+		Title:       "Remove the .native modifier",
+		Description: "The .native modifier is removed in Vue 3. Listeners which a component does not declare in emits are added to its root element, the fixer removes the modifier. Components which emit an event with the same name need a review.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-card @click.native="onClick"></sw-card>`,
+				After:  `<sw-card @click="onClick"></sw-card>`,
+			},
+		},
+	}
+}
+
+func (n NativeModifierFixer) Fix(nodes []html.Node) error {
+	html.TraverseNode(nodes, func(node *html.ElementNode) {
+		updateAttributes(node, func(attr html.Attribute) (html.Attribute, bool) {
+			attr.Key, _ = withoutNativeModifier(attr.Key)
+			return attr, true
+		})
+	})
+	return nil
+}
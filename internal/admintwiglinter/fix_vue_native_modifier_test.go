@@ -0,0 +1,42 @@
+package admintwiglinter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNativeModifierFixer(t *testing.T) {
+	cases := []struct {
+		description string
+		before      string
+		after       string
+	}{
+		{
+			description: "remove native modifier",
+			before:      `<sw-card @click.native="onClick"></sw-card>`,
+			after:       `<sw-card @click="onClick"></sw-card>`,
+		},
+		{
+			description: "keep other modifiers",
+			before:      `<sw-card v-on:keydown.native.enter.prevent="onEnter"></sw-card>`,
+			after:       `<sw-card v-on:keydown.enter.prevent="onEnter"></sw-card>`,
+		},
+		{
+			description: "ignore attributes which are no listeners",
+			before:      `<sw-card :title.native="title"></sw-card>`,
+			after:       `<sw-card :title.native="title"></sw-card>`,
+		},
+		{
+			description: "ignore modifiers starting with native",
+			before:      `<sw-card @click.nativeonly="onClick"></sw-card>`,
+			after:       `<sw-card @click.nativeonly="onClick"></sw-card>`,
+		},
+	}
+
+	for _, c := range cases {
+		newStr, err := runFixerOnString(NativeModifierFixer{}, c.before)
+		assert.NoError(t, err, c.description)
+		assert.Equal(t, c.after, newStr, c.description)
+	}
+}
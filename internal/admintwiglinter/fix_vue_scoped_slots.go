@@ -0,0 +1,61 @@
+package admintwiglinter
+
+import (
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/shyim/go-version"
+)
+
+type ScopedSlotsFixer struct{}
+
+func init() {
+	AddFixer(ScopedSlotsFixer{})
+}
+
+func (s ScopedSlotsFixer) Check(nodes []html.Node) []CheckError {
+	var errs []CheckError
+	eachVueExpression(nodes, func(key, expression string, line int) {
+		for _, match := range vueInstancePropertyRegex.FindAllStringSubmatch(expression, -1) {
+			if match[1] == "scopedSlots" {
+				errs = append(errs, CheckError{
+					Message:    "$scopedSlots is removed in Vue 3, all slots are functions in $slots.",
+					Severity:   "error",
+					Identifier: "vue-scoped-slots",
+					Line:       line,
+				})
+			}
+		}
+	})
+	return errs
+}
+
+func (s ScopedSlotsFixer) Supports(version *version.Version) bool {
+	return shopware67Constraint.Check(version)
+}
+
+func (s ScopedSlotsFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "vue-scoped-slots",
+		Title:       "Replace $scopedSlots by $slots",
+		Description: "$scopedSlots is removed in Vue 3, all slots are functions in $slots.",
+		Versions:    shopware67Versions,
+		Safety:      FixSafe,
+		Examples: []Example{
+			{
+				Before: `<div v-if="$scopedSlots.footer"></div>`,
+				After:  `<div v-if="$slots.footer"></div>`,
+			},
+		},
+	}
+}
+
+func (s ScopedSlotsFixer) Fix(nodes []html.Node) error {
+	updateVueExpressions(nodes, func(key, expression string) string {
+		return vueInstancePropertyRegex.ReplaceAllStringFunc(expression, func(match string) string {
+			if match == "$scopedSlots" {
+				return "$slots"
+			}
+			return match
+		})
+	})
+	return nil
+}
@@ -0,0 +1,39 @@
+package admintwiglinter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScopedSlotsFixer(t *testing.T) {
+	cases := []struct {
+		description string
+		before      string
+		after       string
+	}{
+		{
+			description: "replace in directives",
+			before:      `<div v-if="$scopedSlots.footer"></div>`,
+			after:       `<div v-if="$slots.footer"></div>`,
+		},
+		{
+			description: "replace in template expressions",
+			before:      `<div>{{ Object.keys($scopedSlots).length }}</div>`,
+			after: `<div>
+    {{ Object.keys($slots).length }}
+</div>`,
+		},
+		{
+			description: "keep plain attributes",
+			before:      `<div title="$scopedSlots"></div>`,
+			after:       `<div title="$scopedSlots"></div>`,
+		},
+	}
+
+	for _, c := range cases {
+		newStr, err := runFixerOnString(ScopedSlotsFixer{}, c.before)
+		assert.NoError(t, err, c.description)
+		assert.Equal(t, c.after, newStr, c.description)
+	}
+}
@@ -0,0 +1,148 @@
+package admintwiglinter
+
+import (
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/shyim/go-version"
+)
+
+type SlotFixer struct{}
+
+func init() {
+	AddFixer(SlotFixer{})
+}
+
+// isDeprecatedSlotAttribute reports whether the attribute belongs to the slot syntax of Vue 2.
+func isDeprecatedSlotAttribute(key string) bool {
+	switch key {
+	case "slot", ":slot", "v-bind:slot", "slot-scope", "scope":
+		return true
+	}
+	return false
+}
+
+// slotDirective returns the v-slot shorthand replacing the deprecated slot attributes of the element.
+func slotDirective(node *html.ElementNode) (html.Attribute, bool) {
+	name := ""
+	scope := ""
+	found := false
+
+	for _, attrNode := range node.Attributes {
+		attr, ok := attrNode.(html.Attribute)
+		if !ok {
+			continue
+		}
+
+		switch attr.Key {
+		case "slot":
+			name = attr.Value
+		case ":slot", "v-bind:slot":
+			name = "[" + attr.Value + "]"
+		case "slot-scope":
+			scope = attr.Value
+		case "scope":
+			// scope is only a slot scope on templates
+			if node.Tag != "template" {
+				continue
+			}
+			scope = attr.Value
+		default:
+			continue
+		}
+
+		found = true
+	}
+
+	if name == "" {
+		name = "default"
+	}
+
+	return html.Attribute{Key: "#" + name, Value: scope}, found
+}
+
+func (s SlotFixer) Check(nodes []html.Node) []CheckError {
+	var errs []CheckError
+	html.TraverseNode(nodes, func(node *html.ElementNode) {
+		if _, ok := slotDirective(node); ok {
+			errs = append(errs, CheckError{
+				Message:    "The slot and slot-scope attributes are removed in Vue 3, use v-slot on a template instead.",
+				Severity:   "error",
+				Identifier: "vue-slot",
+				Line:       node.Line,
+			})
+		}
+	})
+	return errs
+}
+
+func (s SlotFixer) Supports(version *version.Version) bool {
+	return shopware67Constraint.Check(version)
+}
+
+func (s SlotFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "vue-slot",
+		Title:       "Use v-slot instead of slot and slot-scope",
+		Description: "The slot and slot-scope attributes are removed in Vue 3. The fixer replaces them by the v-slot shorthand, elements which are no template are wrapped in a template with their v-if condition. Wrapped elements have to be reviewed, as v-else chains with their siblings and the DOM structure change.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-data-grid><template slot="actions" slot-scope="{ item }">{{ item.name }}</template></sw-data-grid>`,
+				After: `<sw-data-grid>
+    <template #actions="{ item }">{{ item.name }}</template>
+</sw-data-grid>`,
+			},
+		},
+	}
+}
+
+func (s SlotFixer) Fix(nodes []html.Node) error {
+	html.TraverseChildren(nodes, func(list html.NodeList) {
+		for i, child := range list {
+			node, ok := child.(*html.ElementNode)
+			if !ok {
+				continue
+			}
+
+			directive, ok := slotDirective(node)
+			if !ok {
+				continue
+			}
+
+			if node.Tag == "template" {
+				// The directive takes the place of the first slot attribute
+				added := false
+				updateAttributes(node, func(attr html.Attribute) (html.Attribute, bool) {
+					if !isDeprecatedSlotAttribute(attr.Key) {
+						return attr, true
+					}
+					if added {
+						return attr, false
+					}
+					added = true
+					return directive, true
+				})
+				continue
+			}
+
+			// v-slot is only allowed on templates, the element is wrapped. Conditions stay on the slot
+			// so it is only passed when the element is rendered.
+			wrapper := &html.ElementNode{Tag: "template", Children: html.NodeList{node}, Line: node.Line}
+
+			updateAttributes(node, func(attr html.Attribute) (html.Attribute, bool) {
+				switch attr.Key {
+				case "v-if", "v-else-if", "v-else":
+					wrapper.Attributes = append(wrapper.Attributes, attr)
+					return attr, false
+				case "slot", ":slot", "v-bind:slot", "slot-scope":
+					return attr, false
+				}
+				return attr, true
+			})
+
+			wrapper.Attributes = append(html.NodeList{directive}, wrapper.Attributes...)
+			list[i] = wrapper
+		}
+	})
+	return nil
+}
@@ -0,0 +1,105 @@
+package admintwiglinter
+
+import (
+	"testing"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlotFixer(t *testing.T) {
+	cases := []struct {
+		description string
+		before      string
+		after       string
+	}{
+		{
+			description: "named slot on template",
+			before:      `<sw-card><template slot="toolbar">Toolbar</template></sw-card>`,
+			after: `<sw-card>
+    <template #toolbar>Toolbar</template>
+</sw-card>`,
+		},
+		{
+			description: "scoped slot on template",
+			before:      `<sw-data-grid><template slot="actions" slot-scope="{ item }">{{ item.name }}</template></sw-data-grid>`,
+			after: `<sw-data-grid>
+    <template #actions="{ item }">{{ item.name }}</template>
+</sw-data-grid>`,
+		},
+		{
+			description: "default slot with scope attribute",
+			before:      `<sw-data-grid><template scope="props">{{ props.item }}</template></sw-data-grid>`,
+			after: `<sw-data-grid>
+    <template #default="props">{{ props.item }}</template>
+</sw-data-grid>`,
+		},
+		{
+			description: "dynamic slot name",
+			before:      `<sw-card><template :slot="name">Content</template></sw-card>`,
+			after: `<sw-card>
+    <template #[name]>Content</template>
+</sw-card>`,
+		},
+		{
+			description: "wrap element with slot attribute",
+			before:      `<sw-card><div slot="footer" class="footer" v-if="showFooter">Footer</div></sw-card>`,
+			after: `<sw-card>
+    <template
+        #footer
+        v-if="showFooter"
+    >
+        <div class="footer">Footer</div>
+    </template>
+</sw-card>`,
+		},
+		{
+			description: "wrap element inside twig block",
+			before:      `<sw-card>{% block footer %}<sw-button slot="footer">Save</sw-button>{% endblock %}</sw-card>`,
+			after: `<sw-card>
+    {% block footer %}
+        <template #footer>
+            <sw-button>Save</sw-button>
+        </template>
+    {% endblock %}
+</sw-card>`,
+		},
+	}
+
+	for _, c := range cases {
+		newStr, err := runFixerOnString(SlotFixer{}, c.before)
+		assert.NoError(t, err, c.description)
+		assert.Equal(t, c.after, newStr, c.description)
+	}
+}
+
+func TestSlotFixerKeepsSourceOfWrappedElements(t *testing.T) {
+	before := `<sw-card>
+  <div
+      slot="footer"
+      v-if="showFooter"
+      class="footer">
+        <span>{{ footer }}</span>
+  </div>
+  <template slot="toolbar">Toolbar</template>
+</sw-card>`
+
+	doc, err := html.ParseDocument(before)
+	assert.NoError(t, err)
+	assert.NoError(t, SlotFixer{}.Fix(doc.Nodes))
+
+	after, err := doc.Dump()
+	assert.NoError(t, err)
+	assert.Equal(t, `<sw-card>
+  <template
+      #footer
+      v-if="showFooter"
+  >
+  <div
+      class="footer">
+        <span>{{ footer }}</span>
+  </div>
+  </template>
+  <template #toolbar>Toolbar</template>
+</sw-card>`, after)
+}
@@ -0,0 +1,78 @@
+package admintwiglinter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/shyim/go-version"
+)
+
+type VModelFixer struct{}
+
+func init() {
+	AddFixer(VModelFixer{})
+}
+
+// isPlainVModel reports whether the attribute is v-model without argument, like v-model or v-model.trim.
+func isPlainVModel(key string) bool {
+	return key == "v-model" || strings.HasPrefix(key, "v-model.")
+}
+
+func (v VModelFixer) Check(nodes []html.Node) []CheckError {
+	var errs []CheckError
+	html.TraverseNode(nodes, func(node *html.ElementNode) {
+		model, ok := vueModelComponents[node.Tag]
+		if !ok {
+			return
+		}
+
+		if hasAttribute(node, func(attr html.Attribute) bool { return isPlainVModel(attr.Key) }) {
+			errs = append(errs, CheckError{
+				Message:    fmt.Sprintf("v-model on %s binds model-value in Vue 3, use v-model:%s instead.", node.Tag, model.prop),
+				Severity:   "error",
+				Identifier: "vue-v-model",
+				Line:       node.Line,
+			})
+		}
+	})
+	return errs
+}
+
+func (v VModelFixer) Supports(version *version.Version) bool {
+	return shopware67Constraint.Check(version)
+}
+
+func (v VModelFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "vue-v-model",
+		Title:       "Bind v-model to the model prop",
+		Description: "In Vue 3 v-model binds model-value and listens to update:model-value. Administration components which keep their value prop need the prop as argument, like v-model:value.",
+		Versions:    shopware67Versions,
+		Safety:      FixSafe,
+		Examples: []Example{
+			{
+				Before: `<sw-single-select v-model="product.manufacturerId"/>`,
+				After:  `<sw-single-select v-model:value="product.manufacturerId"/>`,
+			},
+		},
+	}
+}
+
+func (v VModelFixer) Fix(nodes []html.Node) error {
+	html.TraverseNode(nodes, func(node *html.ElementNode) {
+		model, ok := vueModelComponents[node.Tag]
+		if !ok {
+			return
+		}
+
+		updateAttributes(node, func(attr html.Attribute) (html.Attribute, bool) {
+			if isPlainVModel(attr.Key) {
+				// Modifiers like .trim are kept behind the argument
+				attr.Key = "v-model:" + model.prop + strings.TrimPrefix(attr.Key, "v-model")
+			}
+			return attr, true
+		})
+	})
+	return nil
+}
@@ -0,0 +1,47 @@
+package admintwiglinter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVModelFixer(t *testing.T) {
+	cases := []struct {
+		description string
+		before      string
+		after       string
+	}{
+		{
+			description: "add value argument",
+			before:      `<sw-single-select v-model="product.manufacturerId"/>`,
+			after:       `<sw-single-select v-model:value="product.manufacturerId"/>`,
+		},
+		{
+			description: "keep modifiers",
+			before:      `<sw-code-editor v-model.trim="script"/>`,
+			after:       `<sw-code-editor v-model:value.trim="script"/>`,
+		},
+		{
+			description: "use the model prop of the component",
+			before:      `<sw-entity-multi-id-select v-model="ids"/>`,
+			after:       `<sw-entity-multi-id-select v-model:ids="ids"/>`,
+		},
+		{
+			description: "keep v-model with argument",
+			before:      `<sw-single-select v-model:value="id"/>`,
+			after:       `<sw-single-select v-model:value="id"/>`,
+		},
+		{
+			description: "ignore other components",
+			before:      `<input v-model="name"/>`,
+			after:       `<input v-model="name"/>`,
+		},
+	}
+
+	for _, c := range cases {
+		newStr, err := runFixerOnString(VModelFixer{}, c.before)
+		assert.NoError(t, err, c.description)
+		assert.Equal(t, c.after, newStr, c.description)
+	}
+}
@@ -32,6 +32,22 @@ func (p PopoverFixer) Supports(version *version.Version) bool {
 	return shopware67Constraint.Check(version)
 }
 
+func (p PopoverFixer) Metadata() Metadata {
+	return Metadata{
+		Identifier:  "sw-popover",
+		Title:       "Migrate sw-popover to mt-floating-ui",
+		Description: "sw-popover is deprecated in Shopware 6.7. The fixer replaces it with mt-floating-ui, the v-if condition becomes the isOpened property and the zIndex and resizeWidth properties are removed.",
+		Versions:    shopware67Versions,
+		Safety:      FixNeedsReview,
+		Examples: []Example{
+			{
+				Before: `<sw-popover v-if="showMenu"></sw-popover>`,
+				After:  `<mt-floating-ui :isOpened="showMenu"></mt-floating-ui>`,
+			},
+		},
+	}
+}
+
 func (p PopoverFixer) Fix(node []html.Node) error {
 	html.TraverseNode(node, func(node *html.ElementNode) {
 		if node.Tag == "sw-popover" {
@@ -20,7 +20,7 @@ func runFixerOnString(fixer AdminTwigFixer, content string) (string, error) {
 	var buf strings.Builder
 
 	for _, node := range nodes {
-		buf.WriteString(node.Dump(0))
+		buf.WriteString(node.Dump(0, html.DefaultFormatOptions()))
 	}
 
 	return buf.String(), nil
@@ -1,11 +1,15 @@
 package admintwiglinter
 
 import (
+	"sort"
+
 	"github.com/shopware/extension-verifier/internal/html"
 	"github.com/shyim/go-version"
 )
 
-var shopware67Constraint = version.MustConstraints(version.NewConstraint(">=6.7.0"))
+const shopware67Versions = ">=6.7.0"
+
+var shopware67Constraint = version.MustConstraints(version.NewConstraint(shopware67Versions))
 
 var availableFixers = []AdminTwigFixer{}
 
@@ -31,8 +35,61 @@ func GetFixers(version *version.Version) []AdminTwigFixer {
 	return fixers
 }
 
+// AllFixers returns the fixers of all Shopware versions sorted by their identifier.
+func AllFixers() []AdminTwigFixer {
+	fixers := append([]AdminTwigFixer(nil), availableFixers...)
+
+	sort.Slice(fixers, func(i, j int) bool {
+		return fixers[i].Metadata().Identifier < fixers[j].Metadata().Identifier
+	})
+
+	return fixers
+}
+
+// GetFixer returns the fixer reporting the identifier.
+func GetFixer(identifier string) (AdminTwigFixer, bool) {
+	for _, fixer := range availableFixers {
+		if fixer.Metadata().Identifier == identifier {
+			return fixer, true
+		}
+	}
+
+	return nil, false
+}
+
+// FixSafety tells whether the changes of a fixer can be applied without review.
+type FixSafety string
+
+const (
+	// FixSafe changes keep the behaviour of the template
+	FixSafe FixSafety = "safe"
+	// FixNeedsReview changes migrate the template, but the behaviour might differ
+	FixNeedsReview FixSafety = "needs-review"
+	// FixManual findings are only reported, they have to be migrated manually
+	FixManual FixSafety = "manual"
+)
+
+// Example shows a template before and after the fix. For manual fixes After is the migration by hand.
+type Example struct {
+	Before string
+	After  string
+}
+
+// Metadata describes the rule of a fixer.
+type Metadata struct {
+	// Identifier of the CheckErrors of the fixer
+	Identifier  string
+	Title       string
+	Description string
+	// Shopware versions the fixer applies to, like >=6.7.0
+	Versions string
+	Safety   FixSafety
+	Examples []Example
+}
+
 type AdminTwigFixer interface {
 	Check(node []html.Node) []CheckError
 	Supports(version *version.Version) bool
 	Fix(node []html.Node) error
+	Metadata() Metadata
 }
@@ -0,0 +1,55 @@
+package admintwiglinter
+
+import (
+	"testing"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixerMetadata(t *testing.T) {
+	identifiers := map[string]bool{}
+
+	for _, fixer := range AllFixers() {
+		metadata := fixer.Metadata()
+
+		assert.NotEmpty(t, metadata.Identifier)
+		assert.False(t, identifiers[metadata.Identifier], "duplicate identifier %s", metadata.Identifier)
+		identifiers[metadata.Identifier] = true
+
+		assert.NotEmpty(t, metadata.Title, metadata.Identifier)
+		assert.NotEmpty(t, metadata.Description, metadata.Identifier)
+		assert.Contains(t, []FixSafety{FixSafe, FixNeedsReview, FixManual}, metadata.Safety, metadata.Identifier)
+		assert.NotEmpty(t, metadata.Examples, metadata.Identifier)
+
+		found, ok := GetFixer(metadata.Identifier)
+		assert.True(t, ok, metadata.Identifier)
+		assert.Equal(t, fixer, found, metadata.Identifier)
+	}
+}
+
+func TestFixerExamples(t *testing.T) {
+	for _, fixer := range AllFixers() {
+		metadata := fixer.Metadata()
+
+		for _, example := range metadata.Examples {
+			nodes, err := html.NewParser(example.Before)
+			assert.NoError(t, err, metadata.Identifier)
+
+			// The example is reported with the identifier of the fixer
+			errs := fixer.Check(nodes)
+			assert.NotEmpty(t, errs, metadata.Identifier)
+			for _, err := range errs {
+				assert.Equal(t, metadata.Identifier, err.Identifier)
+			}
+
+			if metadata.Safety == FixManual {
+				continue
+			}
+
+			fixed, err := runFixerOnString(fixer, example.Before)
+			assert.NoError(t, err, metadata.Identifier)
+			assert.Equal(t, example.After, fixed, metadata.Identifier)
+		}
+	}
+}
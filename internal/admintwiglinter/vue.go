@@ -0,0 +1,167 @@
+package admintwiglinter
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/shopware/extension-verifier/internal/html"
+)
+
+// vueModel is the prop and the event of the v-model of a component in Vue 2.
+type vueModel struct {
+	prop  string
+	event string
+}
+
+// vueModelComponents are the administration components which are kept in 6.7 and bind v-model to their own prop in Vue 3.
+// Removed components are migrated by their own fixer.
+var vueModelComponents = map[string]vueModel{
+	"sw-single-select":          {prop: "value", event: "change"},
+	"sw-multi-select":           {prop: "value", event: "change"},
+	"sw-entity-single-select":   {prop: "value", event: "change"},
+	"sw-entity-multi-select":    {prop: "entityCollection", event: "change"},
+	"sw-entity-multi-id-select": {prop: "ids", event: "change"},
+	"sw-multi-tag-select":       {prop: "value", event: "change"},
+	"sw-tagged-field":           {prop: "value", event: "change"},
+	"sw-radio-field":            {prop: "value", event: "change"},
+	"sw-boolean-radio-group":    {prop: "value", event: "change"},
+	"sw-compact-colorpicker":    {prop: "value", event: "input"},
+	"sw-text-editor":            {prop: "value", event: "input"},
+	"sw-code-editor":            {prop: "value", event: "input"},
+}
+
+var (
+	vueInstancePropertyRegex = regexp.MustCompile(`\$(listeners|scopedSlots)\b`)
+	identifierRegex          = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*`)
+)
+
+// vueListener splits a listener attribute like @click.stop or v-on:click into the event and its modifiers.
+func vueListener(key string) (event string, modifiers string, ok bool) {
+	switch {
+	case strings.HasPrefix(key, "@"):
+		key = key[1:]
+	case strings.HasPrefix(key, "v-on:"):
+		key = key[5:]
+	default:
+		return "", "", false
+	}
+
+	if i := strings.IndexByte(key, '.'); i != -1 {
+		return key[:i], key[i:], true
+	}
+
+	return key, "", true
+}
+
+// isVueDirective reports whether the value of the attribute is a javascript expression.
+func isVueDirective(key string) bool {
+	return strings.HasPrefix(key, ":") || strings.HasPrefix(key, "@") || strings.HasPrefix(key, "#") || strings.HasPrefix(key, "v-")
+}
+
+// isVueBinding reports whether the attribute binds a value, only bindings and template expressions could have filters in Vue 2.
+func isVueBinding(key string) bool {
+	return strings.HasPrefix(key, ":") || strings.HasPrefix(key, "v-bind:")
+}
+
+// vueFilters returns the names of the filters applied in the expression, like currency in price | currency('EUR').
+// Pipes in strings, brackets and logical ors are no filters.
+func vueFilters(expression string) []string {
+	var filters []string
+	depth := 0
+
+	for i := 0; i < len(expression); i++ {
+		switch c := expression[i]; c {
+		case '\'', '"', '`':
+			end := strings.IndexByte(expression[i+1:], c)
+			if end == -1 {
+				return filters
+			}
+			i += end + 1
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case '|':
+			if depth != 0 || (i+1 < len(expression) && expression[i+1] == '|') || (i > 0 && expression[i-1] == '|') {
+				continue
+			}
+
+			if name := identifierRegex.FindString(strings.TrimLeft(expression[i+1:], " \t\r\n")); name != "" {
+				filters = append(filters, name)
+			}
+		}
+	}
+
+	return filters
+}
+
+// updateAttributes replaces the attributes of the element with the result of update, attributes for which
+// update returns false are removed. Twig tags between the attributes are kept.
+func updateAttributes(node *html.ElementNode, update func(attr html.Attribute) (html.Attribute, bool)) {
+	var newAttrs html.NodeList
+
+	for _, attrNode := range node.Attributes {
+		attr, ok := attrNode.(html.Attribute)
+		if !ok {
+			newAttrs = append(newAttrs, attrNode)
+			continue
+		}
+
+		if attr, keep := update(attr); keep {
+			newAttrs = append(newAttrs, attr)
+		}
+	}
+
+	node.Attributes = newAttrs
+}
+
+// hasAttribute reports whether the element has an attribute for which match returns true.
+func hasAttribute(node *html.ElementNode, match func(attr html.Attribute) bool) bool {
+	for _, attrNode := range node.Attributes {
+		if attr, ok := attrNode.(html.Attribute); ok && match(attr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// eachVueExpression calls f for the javascript expressions of the template, which are the values of directives and
+// the template expressions. The key is the attribute of a directive and empty for template expressions.
+func eachVueExpression(nodes []html.Node, f func(key, expression string, line int)) {
+	html.TraverseNode(nodes, func(node *html.ElementNode) {
+		for _, attrNode := range node.Attributes {
+			if attr, ok := attrNode.(html.Attribute); ok && isVueDirective(attr.Key) {
+				f(attr.Key, attr.Value, node.Line)
+			}
+		}
+	})
+
+	html.TraverseChildren(nodes, func(list html.NodeList) {
+		for _, child := range list {
+			if expression, ok := child.(*html.TemplateExpressionNode); ok {
+				f("", expression.Expression, expression.Line)
+			}
+		}
+	})
+}
+
+// updateVueExpressions replaces the javascript expressions of the template by the result of update.
+func updateVueExpressions(nodes []html.Node, update func(key, expression string) string) {
+	html.TraverseNode(nodes, func(node *html.ElementNode) {
+		updateAttributes(node, func(attr html.Attribute) (html.Attribute, bool) {
+			if isVueDirective(attr.Key) {
+				attr.Value = update(attr.Key, attr.Value)
+			}
+			return attr, true
+		})
+	})
+
+	html.TraverseChildren(nodes, func(list html.NodeList) {
+		for _, child := range list {
+			if expression, ok := child.(*html.TemplateExpressionNode); ok {
+				expression.Expression = update("", expression.Expression)
+			}
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package diff
+
+import "strings"
+
+// Lines splits s into lines, the line endings are kept.
+func Lines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(s, "\n")
+
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// commonLines returns for every line of a the index of the matching line in b
+// or -1, based on the longest common subsequence of both. Lines are compared with equal.
+func commonLines(a, b []string, equal func(a, b string) bool) []int {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if equal(a[i], b[j]) {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	matches := make([]int, len(a))
+	i, j := 0, 0
+
+	for i < len(a) {
+		switch {
+		case j < len(b) && equal(a[i], b[j]):
+			matches[i] = j
+			i++
+			j++
+		case j < len(b) && lengths[i][j+1] >= lengths[i+1][j]:
+			j++
+		default:
+			matches[i] = -1
+			i++
+		}
+	}
+
+	return matches
+}
+
+// sameLine compares two lines ignoring a missing line ending at the end of the text.
+// Merges use it, as the content of blocks often ends without a line ending.
+func sameLine(a, b string) bool {
+	return strings.TrimSuffix(a, "\n") == strings.TrimSuffix(b, "\n")
+}
+
+func sameLines(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !sameLine(a[i], b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
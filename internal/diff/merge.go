@@ -0,0 +1,75 @@
+package diff
+
+import "strings"
+
+// Merge3 applies the changes between base and theirs to ours. Regions changed on both
+// sides in different ways are marked with conflict markers using the given labels.
+// The returned bool is false when the result contains conflicts.
+func Merge3(base, ours, theirs string, oursLabel, theirsLabel string) (string, bool) {
+	baseLines, ourLines, theirLines := Lines(base), Lines(ours), Lines(theirs)
+	ourMatches := commonLines(baseLines, ourLines, sameLine)
+	theirMatches := commonLines(baseLines, theirLines, sameLine)
+
+	out := &lineWriter{}
+	clean := true
+	i, a, b := 0, 0, 0
+
+	for {
+		// Find the next base line which is unchanged on both sides
+		k := i
+		for k < len(baseLines) && (ourMatches[k] == -1 || theirMatches[k] == -1) {
+			k++
+		}
+
+		ka, kb := len(ourLines), len(theirLines)
+		if k < len(baseLines) {
+			ka, kb = ourMatches[k], theirMatches[k]
+		}
+
+		baseChunk, ourChunk, theirChunk := baseLines[i:k], ourLines[a:ka], theirLines[b:kb]
+
+		switch {
+		case sameLines(baseChunk, ourChunk):
+			out.write(theirChunk...)
+		case sameLines(baseChunk, theirChunk), sameLines(ourChunk, theirChunk):
+			out.write(ourChunk...)
+		default:
+			clean = false
+			out.write("<<<<<<< " + oursLabel + "\n")
+			out.write(ourChunk...)
+			out.write("=======\n")
+			out.write(theirChunk...)
+			out.write(">>>>>>> " + theirsLabel + "\n")
+		}
+
+		if k == len(baseLines) {
+			break
+		}
+
+		out.write(ourLines[ka])
+		i, a, b = k+1, ka+1, kb+1
+	}
+
+	result := strings.TrimSuffix(out.String(), "\n")
+
+	if strings.HasSuffix(ours, "\n") {
+		result += "\n"
+	}
+
+	return result, clean
+}
+
+// lineWriter joins lines and adds missing line endings between them.
+type lineWriter struct {
+	strings.Builder
+}
+
+func (w *lineWriter) write(lines ...string) {
+	for _, line := range lines {
+		if w.Len() > 0 && !strings.HasSuffix(w.String(), "\n") {
+			w.WriteString("\n")
+		}
+
+		w.WriteString(line)
+	}
+}
@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge3(t *testing.T) {
+	cases := []struct {
+		description string
+		base        string
+		ours        string
+		theirs      string
+		expected    string
+		clean       bool
+	}{
+		{
+			description: "only theirs changed",
+			base:        "a\nb\nc\n",
+			ours:        "a\nb\nc\n",
+			theirs:      "a\nB\nc\n",
+			expected:    "a\nB\nc\n",
+			clean:       true,
+		},
+		{
+			description: "only ours changed",
+			base:        "a\nb\nc\n",
+			ours:        "a\nb\nc\nd\n",
+			theirs:      "a\nb\nc\n",
+			expected:    "a\nb\nc\nd\n",
+			clean:       true,
+		},
+		{
+			description: "different regions changed",
+			base:        "a\nb\nc\nd\ne\n",
+			ours:        "a\nours\nc\nd\ne\n",
+			theirs:      "a\nb\nc\ntheirs\ne\n",
+			expected:    "a\nours\nc\ntheirs\ne\n",
+			clean:       true,
+		},
+		{
+			description: "same change on both sides",
+			base:        "a\nb\n",
+			ours:        "a\nc\n",
+			theirs:      "a\nc\n",
+			expected:    "a\nc\n",
+			clean:       true,
+		},
+		{
+			description: "conflicting change",
+			base:        "a\nb\nc\n",
+			ours:        "a\nours\nc\n",
+			theirs:      "a\ntheirs\nc\n",
+			expected:    "a\n<<<<<<< extension\nours\n=======\ntheirs\n>>>>>>> shopware\nc\n",
+			clean:       false,
+		},
+		{
+			description: "missing line ending",
+			base:        "a\nb",
+			ours:        "a\nb\nc",
+			theirs:      "A\nb",
+			expected:    "A\nb\nc",
+			clean:       true,
+		},
+	}
+
+	for _, tc := range cases {
+		merged, clean := Merge3(tc.base, tc.ours, tc.theirs, "extension", "shopware")
+
+		assert.Equal(t, tc.expected, merged, tc.description)
+		assert.Equal(t, tc.clean, clean, tc.description)
+	}
+}
@@ -0,0 +1,160 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const unifiedContext = 3
+
+type edit struct {
+	kind byte
+	line string
+}
+
+// edits returns the edit script transforming a into b. Lines are compared exactly,
+// so a changed line ending at the end of the text is an edit as well.
+func edits(a, b []string) []edit {
+	matches := commonLines(a, b, func(a, b string) bool { return a == b })
+	script := make([]edit, 0, len(a)+len(b))
+	j := 0
+
+	for i, line := range a {
+		if matches[i] == -1 {
+			script = append(script, edit{kind: '-', line: line})
+			continue
+		}
+
+		for ; j < matches[i]; j++ {
+			script = append(script, edit{kind: '+', line: b[j]})
+		}
+
+		script = append(script, edit{kind: ' ', line: line})
+		j++
+	}
+
+	for ; j < len(b); j++ {
+		script = append(script, edit{kind: '+', line: b[j]})
+	}
+
+	return script
+}
+
+// Stat returns the number of added and removed lines between a and b.
+func Stat(a, b string) (int, int) {
+	added, removed := 0, 0
+
+	for _, e := range edits(Lines(a), Lines(b)) {
+		switch e.kind {
+		case '+':
+			added++
+		case '-':
+			removed++
+		}
+	}
+
+	return added, removed
+}
+
+// Unified returns the changes between a and b in the unified diff format.
+// The result is empty when both are equal.
+func Unified(fromName, toName, a, b string) string {
+	script := edits(Lines(a), Lines(b))
+
+	var out strings.Builder
+
+	for start := 0; start < len(script); {
+		// Find the next change
+		for start < len(script) && script[start].kind == ' ' {
+			start++
+		}
+
+		if start == len(script) {
+			break
+		}
+
+		// Extend the hunk until a run of unchanged lines is long enough to split
+		end := start
+		for end < len(script) {
+			if script[end].kind != ' ' {
+				end++
+				continue
+			}
+
+			run := end
+			for run < len(script) && script[run].kind == ' ' {
+				run++
+			}
+
+			if run == len(script) || run-end > 2*unifiedContext {
+				break
+			}
+
+			end = run
+		}
+
+		hunkStart := max(start-unifiedContext, 0)
+		hunkEnd := min(end+unifiedContext, len(script))
+
+		if out.Len() == 0 {
+			out.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+		}
+
+		writeHunk(&out, script, hunkStart, hunkEnd)
+		start = hunkEnd
+	}
+
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, script []edit, start, end int) {
+	oldLine, newLine := 1, 1
+
+	for _, e := range script[:start] {
+		if e.kind != '+' {
+			oldLine++
+		}
+		if e.kind != '-' {
+			newLine++
+		}
+	}
+
+	oldCount, newCount := 0, 0
+
+	for _, e := range script[start:end] {
+		if e.kind != '+' {
+			oldCount++
+		}
+		if e.kind != '-' {
+			newCount++
+		}
+	}
+
+	// An empty range starts at the line before
+	if oldCount == 0 {
+		oldLine--
+	}
+	if newCount == 0 {
+		newLine--
+	}
+
+	out.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(oldLine, oldCount), hunkRange(newLine, newCount)))
+
+	for _, e := range script[start:end] {
+		out.WriteByte(e.kind)
+		out.WriteString(e.line)
+
+		if !strings.HasSuffix(e.line, "\n") {
+			out.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a range of a hunk header, the count is omitted for a single line.
+func hunkRange(line, count int) string {
+	if count == 1 {
+		return fmt.Sprint(line)
+	}
+
+	return fmt.Sprintf("%d,%d", line, count)
+}
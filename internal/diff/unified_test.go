@@ -0,0 +1,68 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnified(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+
+	assert.Equal(t, `--- a/file
++++ b/file
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`, Unified("a/file", "b/file", a, b))
+
+	assert.Equal(t, "", Unified("a", "b", a, a))
+}
+
+func TestUnifiedNoNewline(t *testing.T) {
+	assert.Equal(t, `--- a
++++ b
+@@ -1 +1 @@
+-foo
+\ No newline at end of file
++bar
+\ No newline at end of file
+`, Unified("a", "b", "foo", "bar"))
+}
+
+func TestUnifiedFinalNewline(t *testing.T) {
+	assert.Equal(t, `--- a
++++ b
+@@ -1,2 +1,2 @@
+ foo
+-bar
++bar
+\ No newline at end of file
+`, Unified("a", "b", "foo\nbar\n", "foo\nbar"))
+
+	assert.Equal(t, `--- a
++++ b
+@@ -1 +1 @@
+-<div></div>
+\ No newline at end of file
++<div></div>
+`, Unified("a", "b", "<div></div>", "<div></div>\n"))
+}
+
+func TestStat(t *testing.T) {
+	added, removed := Stat("a\nb\nc\n", "a\nc\nd\ne\n")
+
+	assert.Equal(t, 2, added)
+	assert.Equal(t, 1, removed)
+}
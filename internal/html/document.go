@@ -0,0 +1,550 @@
+package html
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Edit replaces the source between Start and End with Text. An insertion has an empty range.
+type Edit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// ApplyEdits applies the edits to the source, only deletions may overlap.
+func ApplyEdits(source string, edits []Edit) (string, error) {
+	sorted := append([]Edit(nil), edits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Start != sorted[j].Start {
+			return sorted[i].Start < sorted[j].Start
+		}
+		return sorted[i].End < sorted[j].End
+	})
+
+	var builder strings.Builder
+	last := 0
+
+	for _, edit := range sorted {
+		if edit.End < edit.Start || edit.End > len(source) {
+			return "", fmt.Errorf("invalid edit from pos %d to %d", edit.Start, edit.End)
+		}
+
+		if edit.Start < last {
+			// Deletions of adjacent nodes can share the whitespace between them
+			if edit.Text != "" {
+				return "", fmt.Errorf("overlapping edit at pos %d", edit.Start)
+			}
+			last = max(last, edit.End)
+			continue
+		}
+
+		builder.WriteString(source[last:edit.Start])
+		builder.WriteString(edit.Text)
+		last = edit.End
+	}
+
+	builder.WriteString(source[last:])
+
+	return builder.String(), nil
+}
+
+// Document is a template parsed in concrete syntax tree mode. It keeps the source and the parsed state of every node,
+// so changes to the nodes are written back as minimal edits to the source. Unchanged nodes keep their formatting,
+// use NodeList.Dump to format a template.
+type Document struct {
+	Nodes NodeList
+	// Options for printing changed nodes, the indentation of the surrounding source is kept
+	Options FormatOptions
+	source  string
+	root    body
+	origins map[Node]*origin
+}
+
+// origin is the parsed state of a node.
+type origin struct {
+	span Span
+	// Canonical form of everything besides the children, like the condition of an if
+	header string
+	bodies []body
+	// Tag name of elements, block name of blocks
+	tag         string
+	selfClosing bool
+	attributes  NodeList
+}
+
+// body is a parsed list of child nodes and the source range between the tags around it.
+type body struct {
+	nodes NodeList
+	start int
+	end   int
+}
+
+// ParseDocument parses the input and keeps the source to write changes back with minimal edits.
+func ParseDocument(input string) (*Document, error) {
+	nodes, err := NewParser(input)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Document{
+		Nodes:   nodes,
+		Options: DefaultFormatOptions(),
+		source:  input,
+		root:    body{nodes: append(NodeList(nil), nodes...), start: 0, end: len(input)},
+		origins: map[Node]*origin{},
+	}
+
+	d.record(nodes)
+
+	return d, nil
+}
+
+// Source returns the parsed source.
+func (d *Document) Source() string {
+	return d.source
+}
+
+// Dump returns the source with the changes to the nodes applied.
+func (d *Document) Dump() (string, error) {
+	return ApplyEdits(d.source, d.Edits())
+}
+
+// Edits returns the changes to the nodes since parsing as edits to the source.
+func (d *Document) Edits() []Edit {
+	var edits []Edit
+	d.diffBody(&edits, d.Nodes, d.root, "")
+	return edits
+}
+
+func (d *Document) record(nodes NodeList) {
+	for _, node := range nodes {
+		o := &origin{span: spanOf(node), header: headerOf(node)}
+
+		switch n := node.(type) {
+		case *ElementNode:
+			o.tag = n.Tag
+			o.selfClosing = n.SelfClosing
+			o.attributes = append(NodeList(nil), n.Attributes...)
+			if n.EndTag.Parsed() {
+				o.bodies = []body{{nodes: append(NodeList(nil), n.Children...), start: n.StartTag.End, end: n.EndTag.Start}}
+			}
+			d.record(n.Attributes)
+		case *TwigBlockNode:
+			o.tag = n.Name
+			o.bodies = []body{{nodes: append(NodeList(nil), n.Children...), start: n.StartTag.End, end: n.EndTag.Start}}
+		case *TwigIfNode:
+			for i, branch := range ifBranches(n) {
+				o.bodies = append(o.bodies, body{nodes: append(NodeList(nil), branch...), start: n.Tags[i].End, end: n.Tags[i+1].Start})
+			}
+		case *TwigForNode:
+			for i, branch := range forBranches(n) {
+				o.bodies = append(o.bodies, body{nodes: append(NodeList(nil), branch...), start: n.Tags[i].End, end: n.Tags[i+1].Start})
+			}
+		case *TwigSetNode:
+			if len(n.Tags) == 2 {
+				o.bodies = []body{{nodes: append(NodeList(nil), n.Children...), start: n.Tags[0].End, end: n.Tags[1].Start}}
+			}
+		}
+
+		// Attributes are values, they are compared by their span
+		if _, ok := node.(Attribute); !ok {
+			d.origins[node] = o
+		}
+
+		for _, b := range o.bodies {
+			d.record(b.nodes)
+		}
+	}
+}
+
+// diffBody adds the edits for a list of nodes, indent is the indentation of the line of the parent node.
+func (d *Document) diffBody(edits *[]Edit, nodes NodeList, parsed body, indent string) {
+	kept := map[Node]bool{}
+	last := -1
+	reordered := false
+
+	for _, node := range nodes {
+		if !d.isParsedIn(node, parsed.nodes) || kept[node] {
+			continue
+		}
+		kept[node] = true
+
+		if start := d.origins[node].span.Start; start < last {
+			reordered = true
+		} else {
+			last = start
+		}
+	}
+
+	if len(nodes) == 0 && len(parsed.nodes) > 0 {
+		*edits = append(*edits, Edit{Start: parsed.start, End: parsed.end})
+		return
+	}
+
+	multiline := strings.Contains(d.source[parsed.start:parsed.end], "\n")
+	childIndent := d.childIndent(parsed, indent)
+
+	if reordered {
+		text := nodes.Dump(0, d.Options)
+		if multiline {
+			text = "\n" + indentLines(text, childIndent) + "\n" + indent
+		}
+		*edits = append(*edits, Edit{Start: parsed.start, End: parsed.end, Text: text})
+		return
+	}
+
+	// Removed nodes keyed by their start, a node inserted there replaces the removed one
+	removed := map[int]Span{}
+	for _, node := range parsed.nodes {
+		if !kept[node] {
+			removed[d.origins[node].span.Start] = d.origins[node].span
+		}
+	}
+
+	position := parsed.start
+	inserted := map[Node]bool{}
+
+	for _, node := range nodes {
+		if kept[node] && !inserted[node] {
+			inserted[node] = true
+			d.diffNode(edits, node)
+			position = d.origins[node].span.End
+			if !isWhitespace(node) {
+				childIndent = lineIndent(d.source, d.origins[node].span.Start)
+			}
+			continue
+		}
+
+		if span, ok := removed[position]; ok {
+			delete(removed, position)
+			d.replace(edits, node, span)
+			position = span.End
+			continue
+		}
+
+		text := node.Dump(0, d.Options)
+		if multiline {
+			text = "\n" + childIndent + indentLines(text, childIndent)
+		}
+		*edits = append(*edits, Edit{Start: position, End: position, Text: text})
+	}
+
+	for _, span := range removed {
+		*edits = append(*edits, d.deletion(span))
+	}
+}
+
+// diffNode adds the edits for a node which is at its parsed place.
+func (d *Document) diffNode(edits *[]Edit, node Node) {
+	o := d.origins[node]
+
+	if spanOf(node) != o.span || headerOf(node) != o.header {
+		d.replace(edits, node, o.span)
+		return
+	}
+
+	element, isElement := node.(*ElementNode)
+
+	if isElement && (element.SelfClosing != o.selfClosing || (!element.EndTag.Parsed() && len(element.Children) > 0)) {
+		d.replace(edits, node, o.span)
+		return
+	}
+
+	if isElement {
+		d.diffStartTag(edits, element, o)
+	}
+
+	if block, ok := node.(*TwigBlockNode); ok && block.Name != o.tag {
+		*edits = append(*edits, Edit{Start: block.StartTag.Start, End: block.StartTag.End, Text: "{% block " + block.Name + " %}"})
+	}
+
+	indent := lineIndent(d.source, o.span.Start)
+	for i, current := range bodiesOf(node) {
+		d.diffBody(edits, current, o.bodies[i], indent)
+	}
+}
+
+// diffStartTag adds the edits for the tag name and the attributes of an element.
+func (d *Document) diffStartTag(edits *[]Edit, element *ElementNode, o *origin) {
+	parsed := map[int]Node{}
+	for _, attribute := range o.attributes {
+		parsed[spanOf(attribute).Start] = attribute
+	}
+
+	// Attributes are identified by their parsed span, created attributes have none
+	kept := map[int]bool{}
+	last := -1
+
+	for _, attribute := range element.Attributes {
+		span := spanOf(attribute)
+		original, ok := parsed[span.Start]
+
+		if !ok || !span.Parsed() || kept[span.Start] || !sameAttribute(original, attribute) {
+			continue
+		}
+
+		if span.Start < last {
+			// The attributes have been reordered, write the whole start tag
+			text := (&ElementNode{Tag: element.Tag, Attributes: element.Attributes, SelfClosing: element.SelfClosing}).Dump(0, d.Options)
+			text = strings.TrimSuffix(text, "</"+element.Tag+">")
+			*edits = append(*edits, Edit{Start: element.StartTag.Start, End: element.StartTag.End, Text: indentLines(text, lineIndent(d.source, element.StartTag.Start))})
+			d.renameEndTag(edits, element, o)
+			return
+		}
+
+		kept[span.Start] = true
+		last = span.Start
+	}
+
+	nameStart := element.StartTag.Start + strings.Index(d.source[element.StartTag.Start:], o.tag)
+	nameEnd := nameStart + len(o.tag)
+
+	if element.Tag != o.tag {
+		*edits = append(*edits, Edit{Start: nameStart, End: nameEnd, Text: element.Tag})
+		d.renameEndTag(edits, element, o)
+	}
+
+	// New attributes are written on their own line when the parsed ones are
+	indent := ""
+	if len(o.attributes) > 0 {
+		first := spanOf(o.attributes[0]).Start
+		if strings.Contains(d.source[nameEnd:first], "\n") {
+			indent = lineIndent(d.source, first)
+		}
+	}
+
+	previous := nameEnd
+	for _, attribute := range o.attributes {
+		span := spanOf(attribute)
+		if !kept[span.Start] {
+			// Remove the attribute with the whitespace before it
+			*edits = append(*edits, Edit{Start: previous, End: span.End})
+		}
+		previous = span.End
+	}
+
+	position := nameEnd
+	written := map[int]bool{}
+
+	for _, attribute := range element.Attributes {
+		span := spanOf(attribute)
+
+		if kept[span.Start] && !written[span.Start] {
+			written[span.Start] = true
+			position = span.End
+
+			if _, isIf := attribute.(*TwigIfNode); isIf {
+				d.diffNode(edits, attribute)
+			} else if attribute != parsed[span.Start] {
+				*edits = append(*edits, Edit{Start: span.Start, End: span.End, Text: attribute.Dump(0, d.Options)})
+			}
+			continue
+		}
+
+		text := " " + attribute.Dump(0, d.Options)
+		if indent != "" {
+			text = "\n" + indent + indentLines(attribute.Dump(0, d.Options), indent)
+		}
+		*edits = append(*edits, Edit{Start: position, End: position, Text: text})
+	}
+}
+
+// renameEndTag adds the edit for the tag name of the end tag.
+func (d *Document) renameEndTag(edits *[]Edit, element *ElementNode, o *origin) {
+	if element.Tag == o.tag || !element.EndTag.Parsed() {
+		return
+	}
+
+	nameStart := element.EndTag.Start + strings.Index(d.source[element.EndTag.Start:], o.tag)
+	*edits = append(*edits, Edit{Start: nameStart, End: nameStart + len(o.tag), Text: element.Tag})
+}
+
+// replace adds an edit writing the whole node at the parsed span.
+func (d *Document) replace(edits *[]Edit, node Node, span Span) {
+	if d.wrap(edits, node, span) {
+		return
+	}
+
+	*edits = append(*edits, Edit{Start: span.Start, End: span.End, Text: indentLines(node.Dump(0, d.Options), lineIndent(d.source, span.Start))})
+}
+
+// wrap adds the edits for a new element wrapping the parsed node at span. Only the tags of the wrapper are
+// inserted, the wrapped node keeps its source and indentation.
+func (d *Document) wrap(edits *[]Edit, node Node, span Span) bool {
+	element, ok := node.(*ElementNode)
+	if !ok || element.SelfClosing || len(element.Children) != 1 {
+		return false
+	}
+
+	if _, parsed := d.origins[element]; parsed {
+		return false
+	}
+
+	child := element.Children[0]
+	if o, parsed := d.origins[child]; !parsed || o.span != span {
+		return false
+	}
+
+	startTag := (&ElementNode{Tag: element.Tag, Attributes: element.Attributes}).Dump(0, d.Options)
+	startTag = strings.TrimSuffix(startTag, "</"+element.Tag+">")
+	endTag := "</" + element.Tag + ">"
+
+	// A node on its own line gets the tags on their own lines
+	indent := lineIndent(d.source, span.Start)
+	lineStart := strings.LastIndex(d.source[:span.Start], "\n") + 1
+	if strings.TrimSpace(d.source[lineStart:span.Start]) == "" {
+		startTag = indentLines(startTag, indent) + "\n" + indent
+		endTag = "\n" + indent + endTag
+	}
+
+	*edits = append(*edits, Edit{Start: span.Start, End: span.Start, Text: startTag})
+	d.diffNode(edits, child)
+	*edits = append(*edits, Edit{Start: span.End, End: span.End, Text: endTag})
+
+	return true
+}
+
+// deletion removes a span, a node on its own line is removed together with the line.
+func (d *Document) deletion(span Span) Edit {
+	lineStart := strings.LastIndex(d.source[:span.Start], "\n") + 1
+	lineEnd := strings.Index(d.source[span.End:], "\n")
+	if lineEnd == -1 {
+		lineEnd = len(d.source) - span.End
+	}
+
+	if strings.TrimSpace(d.source[lineStart:span.Start]) == "" && strings.TrimSpace(d.source[span.End:span.End+lineEnd]) == "" && lineStart > 0 {
+		return Edit{Start: lineStart - 1, End: span.End + lineEnd}
+	}
+
+	return Edit{Start: span.Start, End: span.End}
+}
+
+// childIndent guesses the indentation of the nodes of a body by the first node which starts a line.
+func (d *Document) childIndent(parsed body, indent string) string {
+	for _, node := range parsed.nodes {
+		span := d.origins[node].span
+		lineStart := strings.LastIndex(d.source[:span.Start], "\n") + 1
+
+		if !isWhitespace(node) && strings.TrimSpace(d.source[lineStart:span.Start]) == "" && lineStart > parsed.start {
+			return d.source[lineStart:span.Start]
+		}
+	}
+
+	if parsed.start == 0 {
+		return indent
+	}
+
+	return indent + d.Options.indentUnit()
+}
+
+func (d *Document) isParsedIn(node Node, nodes NodeList) bool {
+	if _, ok := d.origins[node]; !ok {
+		return false
+	}
+
+	for _, parsed := range nodes {
+		if parsed == node {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sameAttribute reports whether both nodes are the same attribute of the start tag, which might have been changed.
+func sameAttribute(parsed, current Node) bool {
+	switch parsed.(type) {
+	case Attribute:
+		_, ok := current.(Attribute)
+		return ok
+	default:
+		return parsed == current
+	}
+}
+
+func isWhitespace(node Node) bool {
+	raw, ok := node.(*RawNode)
+	return ok && strings.TrimSpace(raw.Text) == ""
+}
+
+func spanOf(node Node) Span {
+	if s, ok := node.(interface{ span() Span }); ok {
+		return s.span()
+	}
+	return Span{}
+}
+
+// headerOf returns the canonical form of a node without its children.
+func headerOf(node Node) string {
+	switch n := node.(type) {
+	case *ElementNode, *TwigBlockNode:
+		return ""
+	case *TwigIfNode:
+		return fmt.Sprintf("%s\x00%s\x00%d\x00%t", n.Condition, strings.Join(n.ElseIfConditions, "\x00"), len(n.ElseIfChildren), n.ElseChildren != nil)
+	case *TwigForNode:
+		return fmt.Sprintf("%s\x00%t", n.Expression, n.ElseChildren != nil)
+	case *TwigSetNode:
+		return fmt.Sprintf("%s\x00%s", n.Name, n.Value)
+	}
+	return node.Dump(0, DefaultFormatOptions())
+}
+
+func ifBranches(node *TwigIfNode) []NodeList {
+	branches := append([]NodeList{node.Children}, node.ElseIfChildren...)
+	// The tags are if, elseif..., else and endif
+	if len(node.Tags) == len(node.ElseIfChildren)+3 {
+		branches = append(branches, node.ElseChildren)
+	}
+	return branches
+}
+
+func forBranches(node *TwigForNode) []NodeList {
+	// The tags are for, else and endfor
+	if len(node.Tags) == 3 {
+		return []NodeList{node.Children, node.ElseChildren}
+	}
+	return []NodeList{node.Children}
+}
+
+func bodiesOf(node Node) []NodeList {
+	switch n := node.(type) {
+	case *ElementNode:
+		if n.EndTag.Parsed() {
+			return []NodeList{n.Children}
+		}
+	case *TwigBlockNode:
+		return []NodeList{n.Children}
+	case *TwigIfNode:
+		return ifBranches(n)
+	case *TwigForNode:
+		return forBranches(n)
+	case *TwigSetNode:
+		if len(n.Tags) == 2 {
+			return []NodeList{n.Children}
+		}
+	}
+	return nil
+}
+
+// lineIndent returns the whitespace at the start of the line containing pos.
+func lineIndent(source string, pos int) string {
+	lineStart := strings.LastIndex(source[:pos], "\n") + 1
+	end := lineStart
+	for end < len(source) && (source[end] == ' ' || source[end] == '\t') {
+		end++
+	}
+	return source[lineStart:end]
+}
+
+// indentLines prefixes all lines but the first with indent.
+func indentLines(text, indent string) string {
+	lines := strings.Split(text, "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] != "" {
+			lines[i] = indent + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
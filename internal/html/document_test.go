@@ -0,0 +1,258 @@
+package html
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentKeepsSource(t *testing.T) {
+	input := `{% block sw_product %}
+    <sw-button   variant="primary"
+        @click="onClick">Click  me</sw-button>
+
+
+    <!--comment-->
+    {% if foo %}<br>{% else %}{{ bar }}{% endif %}
+{% endblock %}
+`
+
+	doc, err := ParseDocument(input)
+	assert.NoError(t, err)
+	assert.Empty(t, doc.Edits())
+
+	output, err := doc.Dump()
+	assert.NoError(t, err)
+	assert.Equal(t, input, output)
+}
+
+func TestDocumentMinimalEdits(t *testing.T) {
+	cases := []struct {
+		description string
+		before      string
+		after       string
+		change      func(nodes NodeList)
+	}{
+		{
+			description: "rename element and attribute",
+			before:      `<div><sw-select   @update:value="onUpdateValue"   class="a"></sw-select></div>`,
+			after:       `<div><mt-select   @update:modelValue="onUpdateValue"   class="a"></mt-select></div>`,
+			change: func(nodes NodeList) {
+				TraverseNode(nodes, func(node *ElementNode) {
+					if node.Tag != "sw-select" {
+						return
+					}
+					node.Tag = "mt-select"
+					for i, attr := range node.Attributes {
+						if attribute, ok := attr.(Attribute); ok && attribute.Key == "@update:value" {
+							attribute.Key = "@update:modelValue"
+							node.Attributes[i] = attribute
+						}
+					}
+				})
+			},
+		},
+		{
+			description: "remove and add attributes",
+			before: `<sw-card
+    title="Foo"
+    contentPadding
+    class="card"
+>Hello</sw-card>`,
+			after: `<sw-card
+    title="Foo"
+    class="card"
+    positionIdentifier="card"
+>Hello</sw-card>`,
+			change: func(nodes NodeList) {
+				node := nodes[0].(*ElementNode)
+				node.Attributes = NodeList{node.Attributes[0], node.Attributes[2], Attribute{Key: "positionIdentifier", Value: "card"}}
+			},
+		},
+		{
+			description: "add attribute to inline start tag",
+			before:      `<sw-icon name="regular-times"/>`,
+			after:       `<sw-icon name="regular-times" size="16px"/>`,
+			change: func(nodes NodeList) {
+				node := nodes[0].(*ElementNode)
+				node.Attributes = append(node.Attributes, Attribute{Key: "size", Value: "16px"})
+			},
+		},
+		{
+			description: "remove and insert children",
+			before: `<sw-card>
+    <template #toolbar>
+        <sw-button>Save</sw-button>
+    </template>
+    <p>Hello   World</p>
+</sw-card>`,
+			after: `<sw-card>
+    <slot name="title">
+        <sw-ai-copilot-badge></sw-ai-copilot-badge>
+    </slot>
+    <p>Hello   World</p>
+</sw-card>`,
+			change: func(nodes NodeList) {
+				node := nodes[0].(*ElementNode)
+				var children NodeList
+				for _, child := range node.Children {
+					if element, ok := child.(*ElementNode); ok && element.Tag == "template" {
+						continue
+					}
+					children = append(children, child)
+				}
+				slot := &ElementNode{
+					Tag:        "slot",
+					Attributes: NodeList{Attribute{Key: "name", Value: "title"}},
+					Children:   NodeList{&ElementNode{Tag: "sw-ai-copilot-badge"}},
+				}
+				node.Children = append(NodeList{slot}, children...)
+			},
+		},
+		{
+			description: "remove all children",
+			before: `<sw-text-field label="Name">
+    <template #label>Label</template>
+</sw-text-field>`,
+			after: `<sw-text-field label="Name"></sw-text-field>`,
+			change: func(nodes NodeList) {
+				nodes[0].(*ElementNode).Children = nil
+			},
+		},
+		{
+			description: "replace element",
+			before: `{% block content %}
+    <sw-text-field   label="Name" />
+    <sw-switch-field   label="Active" />
+{% endblock %}`,
+			after: `{% block content %}
+    <mt-text-field
+        label="Name"
+        placeholder="Name"
+    />
+    <sw-switch-field   label="Active" />
+{% endblock %}`,
+			change: func(nodes NodeList) {
+				node := nodes[0].(*TwigBlockNode).Children[0].(*ElementNode)
+				*node = ElementNode{
+					Tag:         "mt-text-field",
+					Attributes:  NodeList{Attribute{Key: "label", Value: "Name"}, Attribute{Key: "placeholder", Value: "Name"}},
+					SelfClosing: true,
+				}
+			},
+		},
+		{
+			description: "change if condition",
+			before: `{% block content %}
+    {% if   foo %}<b>bar</b>{% endif %}
+{% endblock %}`,
+			after: `{% block content %}
+    {% if bar %}
+        <b>bar</b>
+    {% endif %}
+{% endblock %}`,
+			change: func(nodes NodeList) {
+				for _, child := range nodes[0].(*TwigBlockNode).Children {
+					if ifNode, ok := child.(*TwigIfNode); ok {
+						ifNode.Condition = "bar"
+					}
+				}
+			},
+		},
+		{
+			description: "rename block",
+			before:      `{% block  old_name %}<div   class="a"></div>{% endblock %}`,
+			after:       `{% block new_name %}<div   class="a"></div>{% endblock %}`,
+			change: func(nodes NodeList) {
+				nodes[0].(*TwigBlockNode).Name = "new_name"
+			},
+		},
+		{
+			description: "wrap element keeping its source",
+			before: `<sw-card>
+    <div slot="footer">Footer</div>
+    <span>Text</span>
+</sw-card>`,
+			after: `<sw-card>
+    <template #footer>
+    <div>Footer</div>
+    </template>
+    <span>Text</span>
+</sw-card>`,
+			change: func(nodes NodeList) {
+				card := nodes[0].(*ElementNode)
+				for i, child := range card.Children {
+					if element, ok := child.(*ElementNode); ok && element.Tag == "div" {
+						element.Attributes = nil
+						card.Children[i] = &ElementNode{Tag: "template", Attributes: NodeList{Attribute{Key: "#footer"}}, Children: NodeList{element}}
+					}
+				}
+			},
+		},
+		{
+			description: "replace element in place",
+			before: `<sw-card>
+    <div>Footer</div>
+    <span>Text</span>
+</sw-card>`,
+			after: `<sw-card>
+    <p>Footer</p>
+    <span>Text</span>
+</sw-card>`,
+			change: func(nodes NodeList) {
+				card := nodes[0].(*ElementNode)
+				for i, child := range card.Children {
+					if element, ok := child.(*ElementNode); ok && element.Tag == "div" {
+						card.Children[i] = &ElementNode{Tag: "p", Children: element.Children}
+					}
+				}
+			},
+		},
+	}
+
+	for _, c := range cases {
+		doc, err := ParseDocument(c.before)
+		assert.NoError(t, err, c.description)
+
+		c.change(doc.Nodes)
+
+		output, err := doc.Dump()
+		assert.NoError(t, err, c.description)
+		assert.Equal(t, c.after, output, c.description)
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	output, err := ApplyEdits("<a><b></b><c></c></a>", []Edit{
+		{Start: 10, End: 17},
+		{Start: 3, End: 10},
+		{Start: 3, End: 3, Text: "<d/>"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "<a><d/></a>", output)
+
+	_, err = ApplyEdits("<a></a>", []Edit{{Start: 0, End: 3, Text: "<b>"}, {Start: 1, End: 2, Text: "c"}})
+	assert.Error(t, err)
+}
+
+func TestDocumentEditsInsideTwigTags(t *testing.T) {
+	source := `<div>
+    {% for item in items %}
+        <sw-button label="Save"/>
+    {% endfor %}
+</div>`
+
+	doc, err := ParseDocument(source)
+	assert.NoError(t, err)
+
+	TraverseNode(doc.Nodes, func(node *ElementNode) {
+		if node.Tag == "sw-button" {
+			node.Tag = "mt-button"
+		}
+	})
+
+	dumped, err := doc.Dump()
+	assert.NoError(t, err)
+	assert.Equal(t, strings.Replace(source, "sw-button", "mt-button", 1), dumped)
+}
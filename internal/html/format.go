@@ -0,0 +1,89 @@
+package html
+
+import "strings"
+
+type IndentStyle string
+
+const (
+	IndentSpace IndentStyle = "space"
+	IndentTab   IndentStyle = "tab"
+)
+
+// AttributeWrap decides which attributes of a start tag get their own line.
+type AttributeWrap string
+
+const (
+	// A single attribute stays on the line of the tag while it fits, multiple attributes get a line each
+	AttributeWrapAuto AttributeWrap = "auto"
+	// Every attribute gets a line
+	AttributeWrapAlways AttributeWrap = "always"
+	// All attributes stay on the line of the tag while the start tag fits into the print width
+	AttributeWrapFit AttributeWrap = "fit"
+)
+
+// SelfClosingStyle decides how elements without children are closed.
+type SelfClosingStyle string
+
+const (
+	// <br/>
+	SelfClosingCompact SelfClosingStyle = "compact"
+	// <br />
+	SelfClosingSpaced SelfClosingStyle = "spaced"
+	// <br>, other elements are closed like compact
+	SelfClosingVoid SelfClosingStyle = "void"
+)
+
+// FormatOptions control how nodes are printed by Dump.
+type FormatOptions struct {
+	IndentStyle IndentStyle
+	// Number of spaces per level, ignored for tabs
+	IndentSize int
+	// Maximum length of a line with a start tag and its attributes
+	PrintWidth int
+	// Template expressions longer than this are printed on their own line
+	ExpressionWidth int
+	// Maximum length of all template expressions of an element to keep them on one line
+	InlineExpressionsWidth int
+	AttributeWrap          AttributeWrap
+	SelfClosing            SelfClosingStyle
+	// Separate the children of a twig block with an empty line
+	BlankLineBetweenBlockChildren bool
+	// Separate adjacent <template> elements with an empty line
+	BlankLineBetweenTemplates bool
+}
+
+// DefaultFormatOptions returns the options of the Shopware administration.
+func DefaultFormatOptions() FormatOptions {
+	return FormatOptions{
+		IndentStyle:                   IndentSpace,
+		IndentSize:                    4,
+		PrintWidth:                    80,
+		ExpressionWidth:               30,
+		InlineExpressionsWidth:        100,
+		AttributeWrap:                 AttributeWrapAuto,
+		SelfClosing:                   SelfClosingCompact,
+		BlankLineBetweenBlockChildren: true,
+		BlankLineBetweenTemplates:     true,
+	}
+}
+
+// indentUnit returns the indentation of one level.
+func (o FormatOptions) indentUnit() string {
+	if o.IndentStyle == IndentTab {
+		return "\t"
+	}
+	return strings.Repeat(" ", o.IndentSize)
+}
+
+// selfClosingEnd returns the end of the start tag of a self-closing element.
+func (o FormatOptions) selfClosingEnd(tag string) string {
+	switch o.SelfClosing {
+	case SelfClosingSpaced:
+		return " />"
+	case SelfClosingVoid:
+		if isVoidElement(tag) {
+			return ">"
+		}
+	}
+	return "/>"
+}
@@ -0,0 +1,131 @@
+package html
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDumpWithFormatOptions(t *testing.T) {
+	input := `{% block a %}
+<sw-card title="Card" :isLoading="isLoading"><template #header><br/></template><template #footer>Footer</template></sw-card>
+<sw-button variant="primary"/>
+{% endblock %}`
+
+	cases := []struct {
+		name     string
+		options  func(options *FormatOptions)
+		expected string
+	}{
+		{
+			name:    "defaults",
+			options: func(options *FormatOptions) {},
+			expected: `{% block a %}
+    <sw-card
+        title="Card"
+        :isLoading="isLoading"
+    >
+        <template #header>
+            <br/>
+        </template>
+
+        <template #footer>Footer</template>
+    </sw-card>
+
+    <sw-button variant="primary"/>
+{% endblock %}`,
+		},
+		{
+			name: "tabs, fitting attributes and no blank lines",
+			options: func(options *FormatOptions) {
+				options.IndentStyle = IndentTab
+				options.AttributeWrap = AttributeWrapFit
+				options.SelfClosing = SelfClosingSpaced
+				options.BlankLineBetweenBlockChildren = false
+				options.BlankLineBetweenTemplates = false
+			},
+			expected: "{% block a %}\n" +
+				"\t<sw-card title=\"Card\" :isLoading=\"isLoading\">\n" +
+				"\t\t<template #header>\n" +
+				"\t\t\t<br />\n" +
+				"\t\t</template>\n" +
+				"\t\t<template #footer>Footer</template>\n" +
+				"\t</sw-card>\n" +
+				"\t<sw-button variant=\"primary\" />\n" +
+				"{% endblock %}",
+		},
+		{
+			name: "every attribute on its own line",
+			options: func(options *FormatOptions) {
+				options.IndentSize = 2
+				options.AttributeWrap = AttributeWrapAlways
+				options.SelfClosing = SelfClosingVoid
+			},
+			expected: `{% block a %}
+  <sw-card
+    title="Card"
+    :isLoading="isLoading"
+  >
+    <template
+      #header
+    >
+      <br>
+    </template>
+
+    <template
+      #footer
+    >Footer</template>
+  </sw-card>
+
+  <sw-button
+    variant="primary"
+  />
+{% endblock %}`,
+		},
+		{
+			name: "narrow print width",
+			options: func(options *FormatOptions) {
+				options.AttributeWrap = AttributeWrapFit
+				options.PrintWidth = 30
+			},
+			expected: `{% block a %}
+    <sw-card
+        title="Card"
+        :isLoading="isLoading"
+    >
+        <template #header>
+            <br/>
+        </template>
+
+        <template #footer>Footer</template>
+    </sw-card>
+
+    <sw-button variant="primary"/>
+{% endblock %}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			nodes, err := NewParser(input)
+			assert.NoError(t, err)
+
+			options := DefaultFormatOptions()
+			tc.options(&options)
+
+			assert.Equal(t, tc.expected, nodes.Dump(0, options))
+		})
+	}
+}
+
+func TestDumpExpressionWidths(t *testing.T) {
+	nodes, err := NewParser(`<span>{{ product.translated.name }}</span>`)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `<span>{{ product.translated.name }}</span>`, nodes.Dump(0, DefaultFormatOptions()))
+
+	options := DefaultFormatOptions()
+	options.ExpressionWidth = 20
+
+	assert.Equal(t, "<span>\n    {{ product.translated.name }}\n</span>", nodes.Dump(0, options))
+}
@@ -2,19 +2,38 @@ package html
 
 import (
 	"fmt"
+	"slices"
+	"sort"
 	"strings"
 	"unicode"
 )
 
+// Span is the byte range of a node in the parsed source, from the first byte up to the byte after the node.
+// Nodes which have been created instead of parsed have an empty span.
+type Span struct {
+	Start int
+	End   int
+}
+
+func (s Span) span() Span {
+	return s
+}
+
+// Parsed reports whether the span has been set by the parser.
+func (s Span) Parsed() bool {
+	return s.End > s.Start
+}
+
 // Attribute represents an HTML attribute with key and value.
 type Attribute struct {
+	Span
 	Key   string
 	Value string
 }
 
-func (a Attribute) Dump(indent int) string {
+func (a Attribute) Dump(indent int, options FormatOptions) string {
 	var builder strings.Builder
-	indentStr := indentConfig.GetIndent()
+	indentStr := options.indentUnit()
 
 	for i := 0; i < indent; i++ {
 		builder.WriteString(indentStr)
@@ -28,46 +47,16 @@ func (a Attribute) Dump(indent int) string {
 
 // Node is the interface for nodes in our AST.
 type Node interface {
-	Dump(indent int) string
+	Dump(indent int, options FormatOptions) string
 }
 
 type NodeList []Node
 
-// IndentConfig holds configuration for indentation in HTML output
-type IndentConfig struct {
-	SpaceIndent bool
-	IndentSize  int
-}
-
-// DefaultIndentConfig creates a default indentation config with spaces
-func DefaultIndentConfig() IndentConfig {
-	return IndentConfig{
-		SpaceIndent: true,
-		IndentSize:  4,
-	}
-}
-
-// GetIndent returns the indentation string based on configuration
-func (c IndentConfig) GetIndent() string {
-	if c.SpaceIndent {
-		return strings.Repeat(" ", c.IndentSize)
-	}
-	return "\t"
-}
-
-// The global indentation config that will be used by all nodes
-var indentConfig = DefaultIndentConfig()
-
-// SetIndentConfig updates the global indentation configuration
-func SetIndentConfig(config IndentConfig) {
-	indentConfig = config
-}
-
-func (nodeList NodeList) Dump(indent int) string {
+func (nodeList NodeList) Dump(indent int, options FormatOptions) string {
 	var builder strings.Builder
 	for i, node := range nodeList {
 		if _, ok := node.(*CommentNode); ok {
-			builder.WriteString(node.Dump(indent))
+			builder.WriteString(node.Dump(indent, options))
 			builder.WriteString("\n")
 			continue
 		}
@@ -77,12 +66,12 @@ func (nodeList NodeList) Dump(indent int) string {
 				builder.WriteString("\n")
 
 				// Add extra newline between template elements
-				if isTemplateElement(node) && i > 0 && isTemplateElement(nodeList[i-1]) {
+				if options.BlankLineBetweenTemplates && isTemplateElement(node) && isTemplateElement(nodeList[i-1]) {
 					builder.WriteString("\n")
 				}
 			}
 		}
-		builder.WriteString(node.Dump(indent))
+		builder.WriteString(node.Dump(indent, options))
 	}
 
 	// Remove trailing newlines
@@ -108,25 +97,27 @@ func isTemplateElement(node Node) bool {
 
 // RawNode holds unchanged text.
 type RawNode struct {
+	Span
 	Text string
 	Line int // added field
 }
 
 // Dump returns the raw text.
-func (r *RawNode) Dump(indent int) string {
+func (r *RawNode) Dump(indent int, options FormatOptions) string {
 	return r.Text
 }
 
 // CommentNode represents an HTML comment
 type CommentNode struct {
+	Span
 	Text string
 	Line int
 }
 
 // Dump returns the comment text with HTML comment syntax
-func (c *CommentNode) Dump(indent int) string {
+func (c *CommentNode) Dump(indent int, options FormatOptions) string {
 	var builder strings.Builder
-	indentStr := indentConfig.GetIndent()
+	indentStr := options.indentUnit()
 	for i := 0; i < indent; i++ {
 		builder.WriteString(indentStr)
 	}
@@ -138,28 +129,33 @@ func (c *CommentNode) Dump(indent int) string {
 
 // TemplateExpressionNode represents a {{...}} template expression
 type TemplateExpressionNode struct {
+	Span
 	Expression string
 	Line       int
 }
 
 // Dump returns the template expression with {{ }} delimiters
-func (t *TemplateExpressionNode) Dump(indent int) string {
+func (t *TemplateExpressionNode) Dump(indent int, options FormatOptions) string {
 	return "{{" + t.Expression + "}}"
 }
 
 // ElementNode represents an HTML element.
 type ElementNode struct {
+	Span
 	Tag         string
 	Attributes  NodeList
 	Children    NodeList
 	SelfClosing bool
 	Line        int // added field
+	// Spans of the start and end tag, the end tag is empty for void and self-closing elements
+	StartTag Span
+	EndTag   Span
 }
 
 // Dump returns the HTML representation of the element and its children.
-func (e *ElementNode) Dump(indent int) string {
+func (e *ElementNode) Dump(indent int, options FormatOptions) string {
 	var builder strings.Builder
-	indentStr := indentConfig.GetIndent()
+	indentStr := options.indentUnit()
 
 	// Add initial indentation
 	for i := 0; i < indent; i++ {
@@ -172,18 +168,20 @@ func (e *ElementNode) Dump(indent int) string {
 
 	// Add attributes
 	if len(e.Attributes) > 0 {
-		if len(e.Attributes) == 1 {
-			attributeStr := e.Attributes[0].Dump(indent + 1)
+		if inline, ok := e.inlineAttributes(indent, options); ok {
+			builder.WriteString(inline)
+		} else if len(e.Attributes) == 1 && options.AttributeWrap != AttributeWrapAlways {
+			attributeStr := e.Attributes[0].Dump(indent+1, options)
 			_, isIfNode := e.Attributes[0].(*TwigIfNode)
 
-			if len(attributeStr) > 80 || isIfNode {
+			if len(attributeStr) > options.PrintWidth || isIfNode {
 				builder.WriteString("\n")
 				builder.WriteString(attributeStr)
 				builder.WriteString("\n")
 				attributesDidNewLine = true
 			} else {
 				if !isIfNode {
-					attributeStr = e.Attributes[0].Dump(0)
+					attributeStr = e.Attributes[0].Dump(0, options)
 				}
 				builder.WriteString(" ")
 				builder.WriteString(attributeStr)
@@ -192,7 +190,7 @@ func (e *ElementNode) Dump(indent int) string {
 			for _, attr := range e.Attributes {
 				builder.WriteString("\n")
 				attributesDidNewLine = true
-				builder.WriteString(attr.Dump(indent + 1))
+				builder.WriteString(attr.Dump(indent+1, options))
 			}
 			builder.WriteString("\n")
 		}
@@ -206,7 +204,12 @@ func (e *ElementNode) Dump(indent int) string {
 
 	// Handle self-closing tags
 	if e.SelfClosing {
-		builder.WriteString("/>")
+		if attributesDidNewLine {
+			// The closing of the tag starts the line
+			builder.WriteString(strings.TrimPrefix(options.selfClosingEnd(e.Tag), " "))
+		} else {
+			builder.WriteString(options.selfClosingEnd(e.Tag))
+		}
 		return builder.String()
 	}
 
@@ -224,7 +227,7 @@ func (e *ElementNode) Dump(indent int) string {
 		for _, child := range e.Children {
 			if tplExpr, ok := child.(*TemplateExpressionNode); ok {
 				multipleTemplateExpressions++
-				if len(tplExpr.Dump(0)) > 30 {
+				if len(tplExpr.Dump(0, options)) > options.ExpressionWidth {
 					hasLongTemplateExpression = true
 				}
 			} else if _, ok := child.(*RawNode); !ok {
@@ -241,11 +244,11 @@ func (e *ElementNode) Dump(indent int) string {
 			totalLength := 0
 			for _, child := range e.Children {
 				if tplExpr, ok := child.(*TemplateExpressionNode); ok {
-					totalLength += len(tplExpr.Dump(indent + 1))
+					totalLength += len(tplExpr.Dump(indent+1, options))
 				}
 			}
 			// If the combined length is short, keep them on the same line
-			if totalLength <= 100 {
+			if totalLength <= options.InlineExpressionsWidth {
 				multipleShortTemplateExpressions = true
 			}
 		}
@@ -260,7 +263,7 @@ func (e *ElementNode) Dump(indent int) string {
 						for j := 0; j < indent+1; j++ {
 							builder.WriteString(indentStr)
 						}
-						builder.WriteString(child.Dump(indent+1) + "\n")
+						builder.WriteString(child.Dump(indent+1, options) + "\n")
 					} else if raw, ok := child.(*RawNode); ok {
 						trimmed := strings.TrimSpace(raw.Text)
 						if trimmed != "" {
@@ -270,7 +273,7 @@ func (e *ElementNode) Dump(indent int) string {
 							builder.WriteString(trimmed + "\n")
 						}
 					} else {
-						builder.WriteString(child.Dump(indent + 1))
+						builder.WriteString(child.Dump(indent+1, options))
 					}
 				}
 				for i := 0; i < indent; i++ {
@@ -279,7 +282,7 @@ func (e *ElementNode) Dump(indent int) string {
 			} else {
 				// For simple content, keep on the same line
 				for _, child := range e.Children {
-					builder.WriteString(child.Dump(indent))
+					builder.WriteString(child.Dump(indent, options))
 				}
 			}
 		} else {
@@ -300,17 +303,17 @@ func (e *ElementNode) Dump(indent int) string {
 				builder.WriteString("\n")
 
 				// Add an extra newline between template elements
-				if i > 0 && isTemplateElement(child) && isTemplateElement(nonEmptyChildren[i-1]) {
+				if options.BlankLineBetweenTemplates && i > 0 && isTemplateElement(child) && isTemplateElement(nonEmptyChildren[i-1]) {
 					builder.WriteString("\n")
 				}
 
 				if elementChild, ok := child.(*ElementNode); ok {
-					builder.WriteString(elementChild.Dump(indent + 1))
+					builder.WriteString(elementChild.Dump(indent+1, options))
 				} else {
 					for j := 0; j < indent+1; j++ {
 						builder.WriteString(indentStr)
 					}
-					builder.WriteString(strings.TrimSpace(child.Dump(indent + 1)))
+					builder.WriteString(strings.TrimSpace(child.Dump(indent+1, options)))
 				}
 			}
 			builder.WriteString("\n")
@@ -324,17 +327,46 @@ func (e *ElementNode) Dump(indent int) string {
 	return builder.String()
 }
 
+// inlineAttributes returns the attributes for the line of the tag, when the wrap policy fits them there.
+func (e *ElementNode) inlineAttributes(indent int, options FormatOptions) (string, bool) {
+	if options.AttributeWrap != AttributeWrapFit {
+		return "", false
+	}
+
+	var builder strings.Builder
+	for _, attribute := range e.Attributes {
+		if _, ok := attribute.(Attribute); !ok {
+			return "", false
+		}
+		builder.WriteString(" " + attribute.Dump(0, options))
+	}
+
+	inline := builder.String()
+	// The line also holds the indentation, the tag name and the end of the start tag
+	width := len(strings.Repeat(options.indentUnit(), indent)) + len(e.Tag) + len(inline) + 3
+
+	if strings.Contains(inline, "\n") || width > options.PrintWidth {
+		return "", false
+	}
+
+	return inline, true
+}
+
 // TwigBlockNode represents a twig block
 type TwigBlockNode struct {
+	Span
 	Name     string
 	Children NodeList
 	Line     int
+	// Spans of the block and endblock tags
+	StartTag Span
+	EndTag   Span
 }
 
 // Dump returns the twig block with proper formatting
-func (t *TwigBlockNode) Dump(indent int) string {
+func (t *TwigBlockNode) Dump(indent int, options FormatOptions) string {
 	var builder strings.Builder
-	indentStr := indentConfig.GetIndent()
+	indentStr := options.indentUnit()
 	for i := 0; i < indent; i++ {
 		builder.WriteString(indentStr)
 	}
@@ -348,7 +380,7 @@ func (t *TwigBlockNode) Dump(indent int) string {
 				nonEmptyChildren = append(nonEmptyChildren, raw)
 			}
 		} else if twigBlock, ok := child.(*TwigBlockNode); ok {
-			if strings.TrimSpace(twigBlock.Dump(0)) != "" {
+			if strings.TrimSpace(twigBlock.Dump(0, options)) != "" {
 				nonEmptyChildren = append(nonEmptyChildren, twigBlock)
 			}
 		} else {
@@ -360,16 +392,16 @@ func (t *TwigBlockNode) Dump(indent int) string {
 		builder.WriteString("\n")
 		for i, child := range nonEmptyChildren {
 			if elementChild, ok := child.(*ElementNode); ok {
-				builder.WriteString(elementChild.Dump(indent + 1))
+				builder.WriteString(elementChild.Dump(indent+1, options))
 			} else {
-				builder.WriteString(child.Dump(indent + 1))
+				builder.WriteString(child.Dump(indent+1, options))
 			}
 
 			_, isComment := child.(*CommentNode)
 
 			if i < len(nonEmptyChildren)-1 {
 				// Add an extra newline between elements
-				if isComment {
+				if isComment || !options.BlankLineBetweenBlockChildren {
 					builder.WriteString("\n")
 				} else {
 					builder.WriteString("\n\n")
@@ -392,150 +424,50 @@ func (t *TwigBlockNode) Dump(indent int) string {
 
 // TwigIfNode represents a Twig if block
 type TwigIfNode struct {
+	Span
 	Condition        string
 	Children         NodeList
 	ElseIfConditions []string
 	ElseIfChildren   []NodeList
 	ElseChildren     NodeList
 	Line             int
+	// Spans of the if, elseif, else and endif tags in the order of the template
+	Tags []Span
 }
 
 // Dump returns the twig if block with proper formatting
-func (t *TwigIfNode) Dump(indent int) string {
+func (t *TwigIfNode) Dump(indent int, options FormatOptions) string {
 	var builder strings.Builder
-	indentStr := indentConfig.GetIndent()
+	indentStr := strings.Repeat(options.indentUnit(), indent)
 
-	for i := 0; i < indent; i++ {
-		builder.WriteString(indentStr)
-	}
-
-	builder.WriteString("{% if " + t.Condition + " %}")
-
-	// Filter out empty nodes and normalize newlines for if branch
-	var nonEmptyChildren NodeList
-	for _, child := range t.Children {
-		if raw, ok := child.(*RawNode); ok {
-			if strings.TrimSpace(raw.Text) != "" {
-				nonEmptyChildren = append(nonEmptyChildren, raw)
-			}
-		} else {
-			nonEmptyChildren = append(nonEmptyChildren, child)
-		}
-	}
-
-	if len(nonEmptyChildren) > 0 {
-		builder.WriteString("\n")
-		for i, child := range nonEmptyChildren {
-			if elementChild, ok := child.(*ElementNode); ok {
-				builder.WriteString(elementChild.Dump(indent + 1))
-			} else {
-				for i := 0; i < indent+1; i++ {
-					builder.WriteString(indentStr)
-				}
-				builder.WriteString(strings.TrimSpace(child.Dump(indent + 1)))
-			}
-			if i < len(nonEmptyChildren)-1 {
-				// Add an extra newline between elements
-				builder.WriteString("\n")
-			}
-		}
-		builder.WriteString("\n")
-	}
+	builder.WriteString(indentStr + "{% if " + t.Condition + " %}")
+	dumpBranch(&builder, t.Children, indent, options)
 
 	// Handle elseif branches if they exist
 	for i, condition := range t.ElseIfConditions {
-		for i := 0; i < indent; i++ {
-			builder.WriteString(indentStr)
-		}
-		builder.WriteString("{% elseif " + condition + " %}")
-
-		// Filter out empty nodes and normalize newlines for elseif branch
-		nonEmptyChildren = NodeList{}
-		for _, child := range t.ElseIfChildren[i] {
-			if raw, ok := child.(*RawNode); ok {
-				if strings.TrimSpace(raw.Text) != "" {
-					nonEmptyChildren = append(nonEmptyChildren, raw)
-				}
-			} else {
-				nonEmptyChildren = append(nonEmptyChildren, child)
-			}
-		}
-
-		if len(nonEmptyChildren) > 0 {
-			builder.WriteString("\n")
-			for j, child := range nonEmptyChildren {
-				if elementChild, ok := child.(*ElementNode); ok {
-					builder.WriteString(elementChild.Dump(indent + 1))
-				} else {
-					for i := 0; i < indent+1; i++ {
-						builder.WriteString(indentStr)
-					}
-					builder.WriteString(strings.TrimSpace(child.Dump(indent + 1)))
-				}
-				if j < len(nonEmptyChildren)-1 {
-					// Add an extra newline between elements
-					builder.WriteString("\n")
-				}
-			}
-			builder.WriteString("\n")
-		}
+		builder.WriteString(indentStr + "{% elseif " + condition + " %}")
+		dumpBranch(&builder, t.ElseIfChildren[i], indent, options)
 	}
 
 	// Handle else branch if it exists
 	if len(t.ElseChildren) > 0 {
-		for i := 0; i < indent; i++ {
-			builder.WriteString(indentStr)
-		}
-		builder.WriteString("{% else %}")
-
-		// Filter out empty nodes and normalize newlines for else branch
-		var nonEmptyElseChildren NodeList
-		for _, child := range t.ElseChildren {
-			if raw, ok := child.(*RawNode); ok {
-				if strings.TrimSpace(raw.Text) != "" {
-					nonEmptyElseChildren = append(nonEmptyElseChildren, raw)
-				}
-			} else {
-				nonEmptyElseChildren = append(nonEmptyElseChildren, child)
-			}
-		}
-
-		if len(nonEmptyElseChildren) > 0 {
-			builder.WriteString("\n")
-			for i, child := range nonEmptyElseChildren {
-				if elementChild, ok := child.(*ElementNode); ok {
-					builder.WriteString(elementChild.Dump(indent + 1))
-				} else {
-					for i := 0; i < indent+1; i++ {
-						builder.WriteString(indentStr)
-					}
-					builder.WriteString(strings.TrimSpace(child.Dump(indent + 1)))
-				}
-				if i < len(nonEmptyElseChildren)-1 {
-					// Add an extra newline between elements
-					builder.WriteString("\n")
-				}
-			}
-			builder.WriteString("\n")
-		}
+		builder.WriteString(indentStr + "{% else %}")
+		dumpBranch(&builder, t.ElseChildren, indent, options)
 	}
 
-	for i := 0; i < indent; i++ {
-		builder.WriteString(indentStr)
-	}
-
-	builder.WriteString("{% endif %}")
+	builder.WriteString(indentStr + "{% endif %}")
 	return builder.String()
 }
 
 // ParentNode represents a twig parent() call
 type ParentNode struct {
+	Span
 	Line int
 }
 
-func (p *ParentNode) Dump(indent int) string {
+func (p *ParentNode) Dump(indent int, options FormatOptions) string {
 	var builder strings.Builder
-	indentStr := indentConfig.GetIndent()
+	indentStr := options.indentUnit()
 	for i := 0; i < indent; i++ {
 		builder.WriteString(indentStr)
 	}
@@ -545,18 +477,67 @@ func (p *ParentNode) Dump(indent int) string {
 	return builder.String()
 }
 
+// Diagnostic is a problem in the markup the parser has recovered from.
+type Diagnostic struct {
+	Message string
+	Line    int
+	Column  int
+}
+
+func (d Diagnostic) Error() string {
+	return fmt.Sprintf("line %d column %d: %s", d.Line, d.Column, d.Message)
+}
+
 // Parser holds the state for our simple parser.
 type Parser struct {
 	input  string
 	pos    int
 	length int
+	// Tags of the elements whose children are parsed
+	open []string
+	// Names of the twig tags whose bodies are parsed
+	twig        []string
+	diagnostics []Diagnostic
 }
 
-// NewParser creates a new parser for the given input.
+// NewParser creates a new parser for the given input. It fails on the first problem in the markup,
+// use Parse to continue with a partial tree.
 func NewParser(input string) (NodeList, error) {
+	nodes, diagnostics := Parse(input)
+
+	if len(diagnostics) > 0 {
+		return nodes, diagnostics[0]
+	}
+
+	return nodes, nil
+}
+
+// Parse parses the input and recovers from malformed markup like unclosed tags or stray end tags.
+// It returns the nodes which could be parsed and the problems found, ordered by their position.
+func Parse(input string) (NodeList, []Diagnostic) {
 	p := &Parser{input: input, pos: 0, length: len(input)}
 
-	return p.parseNodes("")
+	nodes := p.parseNodes()
+
+	sort.SliceStable(p.diagnostics, func(i, j int) bool {
+		if p.diagnostics[i].Line != p.diagnostics[j].Line {
+			return p.diagnostics[i].Line < p.diagnostics[j].Line
+		}
+		return p.diagnostics[i].Column < p.diagnostics[j].Column
+	})
+
+	return nodes, p.diagnostics
+}
+
+// report records a problem at pos.
+func (p *Parser) report(pos int, format string, args ...any) {
+	lineStart := strings.LastIndex(p.input[:pos], "\n") + 1
+
+	p.diagnostics = append(p.diagnostics, Diagnostic{
+		Message: fmt.Sprintf(format, args...),
+		Line:    p.getLineAt(pos),
+		Column:  pos - lineStart + 1,
+	})
 }
 
 // current returns the current byte (or zero if at the end).
@@ -589,177 +570,165 @@ func (p *Parser) getLineAt(pos int) int {
 	return strings.Count(p.input[:pos], "\n") + 1
 }
 
+// rawNode creates a RawNode for the text starting at pos.
+func (p *Parser) rawNode(pos int, text string) *RawNode {
+	return &RawNode{
+		Span: Span{Start: pos, End: pos + len(text)},
+		Text: text,
+		Line: p.getLineAt(pos),
+	}
+}
+
+// twigTagName returns the name of the twig tag at the current position, e.g. block for {% block name %}.
+func (p *Parser) twigTagName() string {
+	pos := p.pos + 2 // skip "{%"
+	for pos < p.length && (p.input[pos] == ' ' || p.input[pos] == '\n' || p.input[pos] == '\r' || p.input[pos] == '\t') {
+		pos++
+	}
+
+	start := pos
+	for pos < p.length && (unicode.IsLetter(rune(p.input[pos])) || unicode.IsDigit(rune(p.input[pos])) || p.input[pos] == '_') {
+		pos++
+	}
+
+	return p.input[start:pos]
+}
+
+// readTagEnd advances behind the "%}" of the twig tag starting at tagStart and returns the content before it.
+// A tag without end runs to the end of the input.
+func (p *Parser) readTagEnd(tagStart int, name string) string {
+	idx := strings.Index(p.input[p.pos:], "%}")
+
+	if idx == -1 {
+		p.report(tagStart, "unclosed %s tag", name)
+		content := p.input[p.pos:]
+		p.pos = p.length
+		return content
+	}
+
+	content := p.input[p.pos : p.pos+idx]
+	p.pos += idx + 2 // skip past "%}"
+
+	return content
+}
+
 // parseComment parses an HTML comment and returns a CommentNode
-func (p *Parser) parseComment() (*CommentNode, error) {
+func (p *Parser) parseComment() Node {
 	if p.peek(4) != "<!--" {
-		return nil, nil
+		return nil
 	}
 	startPos := p.pos
-	p.pos += 4 // skip "<!--"
 
-	start := p.pos
-	idx := strings.Index(p.input[p.pos:], "-->")
+	start := p.pos + 4 // skip "<!--"
+	idx := strings.Index(p.input[start:], "-->")
 	if idx == -1 {
-		return nil, fmt.Errorf("unterminated comment starting at pos %d", startPos)
+		p.report(startPos, "unterminated comment")
+		return nil
 	}
 
 	commentText := strings.TrimSpace(p.input[start : start+idx])
-	p.pos += idx + 3 // skip past "-->"
+	p.pos = start + idx + 3 // skip past "-->"
 
 	return &CommentNode{
+		Span: Span{Start: startPos, End: p.pos},
 		Text: commentText,
 		Line: p.getLineAt(startPos),
-	}, nil
+	}
 }
 
-// parseNodes parses a list of nodes until an optional stop tag (used for element children).
-func (p *Parser) parseNodes(stopTag string) (NodeList, error) {
-	var nodes NodeList
-	rawStart := p.pos
-
-	for p.pos < p.length {
-		// Check for endblock if we're parsing twig block children
-		if stopTag == "" && p.peek(2) == "{%" {
-			peek := p.input[p.pos:]
-			if strings.HasPrefix(peek, "{% endblock") {
-				break
-			}
+// parseTwigTag parses the twig tag at the current position. Terminators like endif are left to the tag they belong to.
+func (p *Parser) parseTwigTag() Node {
+	for _, parse := range []func() Node{
+		p.parseTwigDirective,
+		p.parseTwigBlock,
+		p.parseTwigIf,
+		p.parseTwigFor,
+		p.parseTwigSet,
+		p.parseTwigInclude,
+		p.parseTwigGenericTag,
+	} {
+		if node := parse(); node != nil {
+			return node
 		}
+	}
 
-		if p.peek(2) == "{%" {
-			if p.pos > rawStart {
-				text := p.input[rawStart:p.pos]
-				if strings.TrimSpace(text) != "" {
-					nodes = append(nodes, &RawNode{
-						Text: text,
-						Line: p.getLineAt(rawStart),
-					})
-				}
-			}
-
-			// Try parsing twig directives first
-			directive, err := p.parseTwigDirective()
-			if err != nil {
-				return nodes, err
-			}
-			if directive != nil {
-				nodes = append(nodes, directive)
-				rawStart = p.pos
-				continue
-			}
+	return nil
+}
 
-			// If not a directive, try parsing as a block
-			startPos := p.pos
-			block, err := p.parseTwigBlock()
-			if err != nil {
-				return nodes, err
-			}
-			if block != nil {
-				nodes = append(nodes, block)
-				rawStart = p.pos
-				continue
-			}
+// parseTwigTerminator handles a terminator like endif at the current position. It reports whether
+// it belongs to an open twig tag, otherwise it is reported and kept as text.
+func (p *Parser) parseTwigTerminator() bool {
+	name := p.twigTagName()
 
-			// If not a block, try parsing as an if statement
-			p.pos = startPos
-			ifNode, err := p.parseTwigIf()
-			if err != nil {
-				return nodes, err
-			}
-			if ifNode != nil {
-				nodes = append(nodes, ifNode)
-				rawStart = p.pos
-				continue
-			}
+	if p.closesTwig(name) {
+		return true
+	}
 
-			// If it wasn't a block or if statement, reset position and continue as raw text
-			p.pos = startPos
+	var openers []string
+	for opener, terminators := range twigTerminators {
+		if slices.Contains(terminators, name) {
+			openers = append(openers, opener)
 		}
+	}
+	sort.Strings(openers)
 
-		// Parse template expressions {{ ... }}
-		if p.peek(2) == "{{" {
-			if p.pos > rawStart {
-				text := p.input[rawStart:p.pos]
-				if text != "" {
-					nodes = append(nodes, &RawNode{
-						Text: text,
-						Line: p.getLineAt(rawStart),
-					})
-				}
-			}
+	p.report(p.pos, "unexpected %s without %s", name, strings.Join(openers, " or "))
 
-			expression, err := p.parseTemplateExpression()
-			if err != nil {
-				return nodes, err
-			}
+	return false
+}
 
-			nodes = append(nodes, expression)
-			rawStart = p.pos
-			continue
-		}
+// parseNodes parses a list of nodes until a terminator of an open twig tag or the end of the input.
+func (p *Parser) parseNodes() NodeList {
+	var nodes NodeList
+	rawStart := p.pos
 
-		if p.peek(4) == "<!--" {
-			if p.pos > rawStart {
-				text := p.input[rawStart:p.pos]
-				if strings.TrimSpace(text) != "" {
-					nodes = append(nodes, &RawNode{
-						Text: text,
-						Line: p.getLineAt(rawStart),
-					})
-				}
-			}
-			comment, err := p.parseComment()
-			if err != nil {
-				return nodes, err
-			}
-			nodes = append(nodes, comment)
-			rawStart = p.pos
-			continue
+	// Text which is only whitespace is dropped, besides before template expressions
+	addRaw := func(end int, keepWhitespace bool) {
+		text := p.input[rawStart:end]
+		if text != "" && (keepWhitespace || strings.TrimSpace(text) != "") {
+			nodes = append(nodes, p.rawNode(rawStart, text))
 		}
+	}
 
-		// If we're about to hit a closing tag for the current element, break.
-		if p.current() == '<' && p.peek(2) == "</" {
-			savedPos := p.pos
-			p.pos += 2
-			p.skipWhitespace()
-			closingTag := p.parseTagName()
-			p.pos = savedPos
-			if stopTag != "" && closingTag == stopTag {
+loop:
+	for p.pos < p.length {
+		start := p.pos
+		keepWhitespace := false
+		var node Node
+
+		switch {
+		case p.peek(2) == "{%":
+			// Check for the end of the twig tag whose children are parsed
+			if isTwigTerminator(p.twigTagName()) {
+				if p.parseTwigTerminator() {
+					break loop
+				}
 				break
 			}
+			node = p.parseTwigTag()
+		case p.peek(2) == "{{":
+			node = p.parseTemplateExpression()
+			keepWhitespace = true
+		case p.peek(4) == "<!--":
+			node = p.parseComment()
+		case p.current() == '<':
+			node = p.parseElement()
 		}
 
-		if p.current() == '<' && p.peek(2) != "<!--" {
-			if p.pos > rawStart {
-				text := p.input[rawStart:p.pos]
-				if strings.TrimSpace(text) != "" {
-					nodes = append(nodes, &RawNode{
-						Text: text,
-						Line: p.getLineAt(rawStart),
-					})
-				}
-			}
-			element, err := p.parseElement()
-			if err != nil {
-				return nodes, err
-			}
-			nodes = append(nodes, element)
-			rawStart = p.pos
-		} else {
-			p.pos++
+		// Anything else is kept as text
+		if node == nil {
+			p.pos = start + 1
+			continue
 		}
-	}
 
-	if rawStart < p.pos {
-		text := p.input[rawStart:p.pos]
-		if strings.TrimSpace(text) != "" {
-			nodes = append(nodes, &RawNode{
-				Text: text,
-				Line: p.getLineAt(rawStart),
-			})
-		}
+		addRaw(start, keepWhitespace)
+		nodes = append(nodes, node)
+		rawStart = p.pos
 	}
-	return nodes, nil
+
+	addRaw(p.pos, false)
+
+	return nodes
 }
 
 // isVoidElement returns true if the tag is a void element (e.g., <br> does not require a closing tag)
@@ -772,18 +741,27 @@ func isVoidElement(tag string) bool {
 }
 
 // parseElement parses an HTML element starting at the current position (assumes a '<').
-func (p *Parser) parseElement() (Node, error) {
+// It returns nil when the '<' does not start an element.
+func (p *Parser) parseElement() Node {
 	// Record start position for line number.
 	startPos := p.pos
 	if p.current() != '<' {
-		return nil, nil
+		return nil
 	}
 	p.pos++ // skip '<'
 	p.skipWhitespace()
 
+	if p.current() == '/' {
+		p.pos++ // skip '/'
+		p.skipWhitespace()
+		p.report(startPos, "unexpected closing tag </%s>", p.parseTagName())
+		return nil
+	}
+
 	tagName := p.parseTagName()
 	if tagName == "" {
-		return nil, fmt.Errorf("empty tag name at pos %d", p.pos)
+		p.report(startPos, "expected a tag name after '<'")
+		return nil
 	}
 
 	node := &ElementNode{
@@ -798,11 +776,7 @@ func (p *Parser) parseElement() (Node, error) {
 		p.skipWhitespace()
 		// Check for Twig directives within attributes
 		if p.peek(2) == "{%" {
-			ifNode, err := p.parseTwigIf()
-			if err != nil {
-				return nil, err
-			}
-			if ifNode != nil {
+			if ifNode := p.parseTwigIf(); ifNode != nil {
 				node.Attributes = append(node.Attributes, ifNode)
 				// After parsing a Twig directive, we need to skip whitespace again
 				p.skipWhitespace()
@@ -810,164 +784,165 @@ func (p *Parser) parseElement() (Node, error) {
 			}
 		}
 
-		if p.current() == '>' || (p.current() == '/' && p.peek(2) == "/>") {
+		if p.current() == '>' || p.peek(2) == "/>" {
 			break
 		}
+		attrStart := p.pos
 		attrName := p.parseAttrName()
 		if attrName == "" {
-			break
+			if p.pos < p.length {
+				p.report(p.pos, "unexpected %q in start tag of <%s>", p.current(), tagName)
+				p.pos++
+			}
+			continue
 		}
+		attrEnd := p.pos
 		p.skipWhitespace()
 		var attrVal string
 		if p.current() == '=' {
 			p.pos++ // skip '='
 			p.skipWhitespace()
 			attrVal = p.parseAttrValue()
+			attrEnd = p.pos
+		} else {
+			// The whitespace belongs to the next attribute
+			p.pos = attrEnd
 		}
 		// Append attribute preserving order.
-		node.Attributes = append(node.Attributes, Attribute{Key: attrName, Value: attrVal})
+		node.Attributes = append(node.Attributes, Attribute{Span: Span{Start: attrStart, End: attrEnd}, Key: attrName, Value: attrVal})
 	}
 
 	// Check for self-closing tag.
-	if p.current() == '/' {
-		p.pos++ // skip '/'
-		if p.current() != '>' {
-			return nil, fmt.Errorf("expected '>' after '/' at pos %d", p.pos)
-		}
-		p.pos++ // skip '>'
+	if p.peek(2) == "/>" {
+		p.pos += 2 // skip "/>"
 		node.SelfClosing = true
-		return node, nil
+		node.StartTag = Span{Start: startPos, End: p.pos}
+		node.Span = node.StartTag
+		return node
 	}
-	if p.current() == '>' {
-		p.pos++ // skip '>'
-		if isVoidElement(tagName) {
-			node.SelfClosing = true
-			return node, nil
-		}
-	} else {
-		// Add more context to the error message
-		surroundingText := ""
-		start := p.pos - 10
-		if start < 0 {
-			start = 0
-		}
-		end := p.pos + 10
-		if end > p.length {
-			end = p.length
-		}
-		surroundingText = p.input[start:end]
-		return nil, fmt.Errorf("expected '>' at pos %d, surrounding text: '%s', current byte: '%c'", p.pos, surroundingText, p.current())
+
+	if p.current() != '>' {
+		p.report(startPos, "start tag of <%s> is not closed", tagName)
+		node.StartTag = Span{Start: startPos, End: p.pos}
+		node.Span = node.StartTag
+		return node
 	}
 
-	// Parse children until the corresponding closing tag.
-	children, err := p.parseElementChildren(node.Tag)
-	if err != nil {
-		return nil, err
+	p.pos++ // skip '>'
+	node.StartTag = Span{Start: startPos, End: p.pos}
+	if isVoidElement(tagName) {
+		node.SelfClosing = true
+		node.Span = node.StartTag
+		return node
 	}
-	node.Children = children
 
-	return node, nil
+	// Parse children until the corresponding closing tag.
+	node.Children, node.EndTag = p.parseElementChildren(node.Tag, startPos)
+	node.Span = Span{Start: startPos, End: p.pos}
+
+	return node
 }
 
-// parseElementChildren parses the child nodes of an element until the closing tag is reached.
-func (p *Parser) parseElementChildren(tag string) (NodeList, error) {
+// parseElementChildren parses the child nodes of an element until the closing tag is reached
+// and returns them with the span of the closing tag. An element without closing tag ends
+// at the closing tag of a parent element or at the end of the input.
+func (p *Parser) parseElementChildren(tag string, startPos int) (NodeList, Span) {
 	var children NodeList
 	rawStart := p.pos
 
-	for p.pos < p.length {
-		if p.peek(4) == "<!--" {
-			if p.pos > rawStart {
-				text := p.input[rawStart:p.pos]
-				if text != "" {
-					children = append(children, &RawNode{
-						Text: text,
-						Line: p.getLineAt(rawStart),
-					})
-				}
-			}
-			comment, err := p.parseComment()
-			if err != nil {
-				return children, err
-			}
-			children = append(children, comment)
-			rawStart = p.pos
-			continue
+	p.open = append(p.open, tag)
+	defer func() {
+		p.open = p.open[:len(p.open)-1]
+	}()
+
+	addRaw := func(end int) {
+		if end > rawStart {
+			children = append(children, p.rawNode(rawStart, p.input[rawStart:end]))
 		}
+	}
+
+	for p.pos < p.length {
+		start := p.pos
+		var child Node
 
+		switch {
+		case p.peek(4) == "<!--":
+			child = p.parseComment()
 		// Parse template expressions {{ ... }}
-		if p.peek(2) == "{{" {
-			if p.pos > rawStart {
-				text := p.input[rawStart:p.pos]
-				if text != "" {
-					children = append(children, &RawNode{
-						Text: text,
-						Line: p.getLineAt(rawStart),
-					})
-				}
-			}
+		case p.peek(2) == "{{":
+			child = p.parseTemplateExpression()
+		case p.peek(2) == "{%":
+			name := p.twigTagName()
 
-			expression, err := p.parseTemplateExpression()
-			if err != nil {
-				return children, err
+			if !isTwigTerminator(name) {
+				child = p.parseTwigTag()
+				break
 			}
 
-			children = append(children, expression)
-			rawStart = p.pos
-			continue
-		}
+			if p.closesTwig(name) {
+				// The terminator belongs to a twig tag around the element
+				addRaw(start)
+				p.report(startPos, "element <%s> is not closed before %s tag", tag, name)
+				return children, Span{}
+			}
 
+			p.parseTwigTerminator()
 		// Check for a closing tag.
-		if p.current() == '<' && p.peek(2) == "</" {
-			savedPos := p.pos
+		case p.peek(2) == "</":
 			p.pos += 2 // skip "</"
 			p.skipWhitespace()
 			closingTag := p.parseTagName()
 			p.skipWhitespace()
-			if p.current() == '>' {
-				p.pos++ // skip '>'
-			} else {
-				return children,
-					fmt.Errorf("expected '>' for closing tag at pos %d", p.pos)
-			}
+
 			if closingTag == tag {
 				// Add any raw text before the closing tag.
-				if rawStart < savedPos {
-					text := p.input[rawStart:savedPos]
-					if text != "" {
-						children = append(children, &RawNode{
-							Text: text,
-							Line: p.getLineAt(rawStart),
-						})
-					}
+				addRaw(start)
+				if p.current() == '>' {
+					p.pos++ // skip '>'
+				} else {
+					p.report(start, "expected '>' to close </%s>", tag)
 				}
-				return children, nil
-			} else {
-				// Not the matching closing tag; reset and continue.
-				p.pos = savedPos
+				return children, Span{Start: start, End: p.pos}
 			}
-		}
 
-		if p.current() == '<' && p.peek(2) != "<!--" {
-			if p.pos > rawStart {
-				text := p.input[rawStart:p.pos]
-				if text != "" {
-					children = append(children, &RawNode{
-						Text: text,
-						Line: p.getLineAt(rawStart),
-					})
-				}
-			}
-			child, err := p.parseElement()
-			if err != nil {
-				return children, err
+			if p.isOpen(closingTag) {
+				// The closing tag belongs to a parent element
+				addRaw(start)
+				p.pos = start
+				p.report(startPos, "element <%s> is not closed before </%s>", tag, closingTag)
+				return children, Span{}
 			}
-			children = append(children, child)
-			rawStart = p.pos
-		} else {
-			p.pos++
+
+			p.report(start, "unexpected closing tag </%s>", closingTag)
+		case p.current() == '<':
+			child = p.parseElement()
 		}
+
+		// Anything else is kept as text
+		if child == nil {
+			p.pos = start + 1
+			continue
+		}
+
+		addRaw(start)
+		children = append(children, child)
+		rawStart = p.pos
 	}
-	return children, nil
+
+	addRaw(p.pos)
+	p.report(startPos, "element <%s> is not closed", tag)
+
+	return children, Span{}
+}
+
+// isOpen reports whether the children of an element with the tag are parsed.
+func (p *Parser) isOpen(tag string) bool {
+	for _, open := range p.open {
+		if open == tag {
+			return true
+		}
+	}
+	return false
 }
 
 // parseTagName parses a tag or attribute name (letters, digits, '-' and ':').
@@ -1002,17 +977,25 @@ func (p *Parser) parseAttrName() string {
 // parseAttrValue parses an attribute value (expects a quoted string).
 func (p *Parser) parseAttrValue() string {
 	if p.current() == '"' {
+		quote := p.pos
 		p.pos++ // skip opening "
 		start := p.pos
-		// Continue until we find a closing quote or reach the end
-		for p.pos < p.length && p.current() != '"' {
-			p.pos++
-		}
-		val := p.input[start:p.pos]
-		if p.pos < p.length && p.current() == '"' {
-			p.pos++ // skip closing "
+
+		end := strings.IndexByte(p.input[start:], '"')
+		// A value running into a closing tag takes its quote from a later attribute
+		if end == -1 || strings.Contains(p.input[start:start+end], "</") {
+			p.report(quote, "attribute value is missing its closing quote")
+			// Assume the value ends with the start tag
+			end = strings.IndexByte(p.input[start:], '>')
+			if end == -1 {
+				end = p.length - start
+			}
+			p.pos = start + end
+			return p.input[start:p.pos]
 		}
-		return val
+
+		p.pos = start + end + 1 // skip closing "
+		return p.input[start : start+end]
 	}
 	// Allow unquoted values.
 	start := p.pos
@@ -1023,56 +1006,33 @@ func (p *Parser) parseAttrValue() string {
 	return p.input[start:p.pos]
 }
 
-func (p *Parser) parseTwigDirective() (Node, error) {
-	if p.peek(2) != "{%" {
-		return nil, nil
+// parseTwigDirective parses {% parent %} and {% parent() %}.
+func (p *Parser) parseTwigDirective() Node {
+	if p.peek(2) != "{%" || p.twigTagName() != "parent" {
+		return nil
 	}
 
 	startPos := p.pos
 	p.pos += 2 // skip "{%"
 	p.skipWhitespace()
+	p.pos += 6 // skip "parent"
 
-	// Check if it's a parent() call
-	if strings.HasPrefix(p.input[p.pos:], "parent()") {
-		p.pos += 8 // skip "parent()"
-		p.skipWhitespace()
-		if p.peek(2) != "%}" {
-			return nil, fmt.Errorf("unclosed parent directive at pos %d", startPos)
-		}
-		p.pos += 2 // skip "%}"
-		return &ParentNode{Line: p.getLineAt(startPos)}, nil
-	}
-
-	// Handle {% parent %} directive (without parentheses)
-	if strings.HasPrefix(p.input[p.pos:], "parent") {
-		p.pos += 6 // skip "parent"
-		p.skipWhitespace()
-		if p.peek(2) != "%}" {
-			return nil, fmt.Errorf("unclosed parent directive at pos %d", startPos)
-		}
-		p.pos += 2 // skip "%}"
-		return &ParentNode{Line: p.getLineAt(startPos)}, nil
+	content := strings.TrimSpace(p.readTagEnd(startPos, "parent"))
+	if content != "" && content != "()" {
+		p.report(startPos, "unexpected %q in parent tag", content)
 	}
 
-	// Reset position if it's not a recognized directive
-	p.pos = startPos
-	return nil, nil
+	return &ParentNode{Span: Span{Start: startPos, End: p.pos}, Line: p.getLineAt(startPos)}
 }
 
-func (p *Parser) parseTwigBlock() (Node, error) {
-	if p.peek(2) != "{%" {
-		return nil, nil
+func (p *Parser) parseTwigBlock() Node {
+	if p.peek(2) != "{%" || p.twigTagName() != "block" {
+		return nil
 	}
 
 	startPos := p.pos
 	p.pos += 2 // skip "{%"
 	p.skipWhitespace()
-
-	// Check if it's a block
-	if !strings.HasPrefix(p.input[p.pos:], "block") {
-		p.pos = startPos
-		return nil, nil
-	}
 	p.pos += 5 // skip "block"
 	p.skipWhitespace()
 
@@ -1084,325 +1044,214 @@ func (p *Parser) parseTwigBlock() (Node, error) {
 	name := strings.TrimSpace(p.input[start:p.pos])
 
 	// Skip to end of opening tag
-	for p.pos < p.length && p.peek(2) != "%}" {
-		p.pos++
-	}
-	if p.peek(2) != "%}" {
-		return nil, fmt.Errorf("unclosed block tag at pos %d", startPos)
-	}
-	p.pos += 2 // skip "%}"
+	p.readTagEnd(startPos, "block")
+	startTag := Span{Start: startPos, End: p.pos}
 
 	// Parse children until endblock
-	children, err := p.parseNodes("")
-	if err != nil {
-		return nil, err
-	}
+	p.twig = append(p.twig, "block")
+	children := p.parseNodes()
+	p.twig = p.twig[:len(p.twig)-1]
 
 	// Look for endblock
 	p.skipWhitespace()
-	if !strings.HasPrefix(p.input[p.pos:], "{%") {
-		return nil, fmt.Errorf("missing endblock at pos %d", p.pos)
-	}
-	p.pos += 2 // skip "{%"
-	p.skipWhitespace()
-
-	if !strings.HasPrefix(p.input[p.pos:], "endblock") {
-		return nil, fmt.Errorf("missing endblock at pos %d", p.pos)
-	}
-	p.pos += 8 // skip "endblock"
-
-	// Skip to end of closing tag
-	for p.pos < p.length && p.peek(2) != "%}" {
-		p.pos++
-	}
-	if p.peek(2) != "%}" {
-		return nil, fmt.Errorf("unclosed endblock tag at pos %d", p.pos)
+	endStart := p.pos
+	if p.peek(2) == "{%" && p.twigTagName() == "endblock" {
+		p.pos += 2 // skip "{%"
+		p.readTagEnd(endStart, "endblock")
+	} else {
+		p.report(startPos, "block %s is not closed, expected endblock", name)
 	}
-	p.pos += 2 // skip "%}"
 
 	return &TwigBlockNode{
+		Span:     Span{Start: startPos, End: p.pos},
 		Name:     name,
 		Children: children,
 		Line:     p.getLineAt(startPos),
-	}, nil
+		StartTag: startTag,
+		EndTag:   Span{Start: endStart, End: p.pos},
+	}
 }
 
 // parseTwigIf parses a {% if ... %} ... {% endif %} block and returns a TwigIfNode
-func (p *Parser) parseTwigIf() (Node, error) {
-	if p.peek(2) != "{%" {
-		return nil, nil
+func (p *Parser) parseTwigIf() Node {
+	if p.peek(2) != "{%" || p.twigTagName() != "if" {
+		return nil
 	}
 
 	startPos := p.pos
 	p.pos += 2 // skip "{%"
 	p.skipWhitespace()
-
-	// Check if it's an if statement
-	if !strings.HasPrefix(p.input[p.pos:], "if") {
-		p.pos = startPos
-		return nil, nil
-	}
 	p.pos += 2 // skip "if"
 	p.skipWhitespace()
 
-	// Parse condition
-	start := p.pos
-	for p.pos < p.length && p.peek(2) != "%}" {
-		p.pos++
-	}
-	condition := strings.TrimSpace(p.input[start:p.pos])
-
-	if p.peek(2) != "%}" {
-		return nil, fmt.Errorf("unclosed if tag at pos %d", startPos)
+	node := &TwigIfNode{
+		Condition: strings.TrimSpace(p.readTagEnd(startPos, "if")),
+		Line:      p.getLineAt(startPos),
 	}
-	p.pos += 2 // skip "%}"
+	tags := []Span{{Start: startPos, End: p.pos}}
 
 	// Parse the if branch
-	ifChildren, err := p.parseIfBranch()
-	if err != nil {
-		return nil, err
-	}
-
-	// Initialize elseif condition and children slices
-	var elseIfConditions []string
-	var elseIfChildren []NodeList
+	node.Children = p.parseTwigBody("if")
 
 	// Parse any elseif branches
-	for {
-		// Check if we've reached an elseif
-		if p.peek(2) == "{%" && strings.HasPrefix(p.input[p.pos+2:], " elseif") {
-			p.pos += 2 // skip "{%"
-			p.skipWhitespace()
-			p.pos += 6 // skip "elseif"
-			p.skipWhitespace()
-
-			// Parse elseif condition
-			start := p.pos
-			for p.pos < p.length && p.peek(2) != "%}" {
-				p.pos++
-			}
-			elseifCondition := strings.TrimSpace(p.input[start:p.pos])
-
-			if p.peek(2) != "%}" {
-				return nil, fmt.Errorf("unclosed elseif tag at pos %d", p.pos)
-			}
-			p.pos += 2 // skip "%}"
-
-			// Parse elseif branch
-			elseifBranch, err := p.parseIfBranch()
-			if err != nil {
-				return nil, err
-			}
-
-			// Add to slices
-			elseIfConditions = append(elseIfConditions, elseifCondition)
-			elseIfChildren = append(elseIfChildren, elseifBranch)
-		} else {
-			break
-		}
-	}
-
-	// Parse the else branch if it exists
-	var elseChildren NodeList
-	if p.peek(2) == "{%" && strings.HasPrefix(p.input[p.pos+2:], " else") {
+	for p.peek(2) == "{%" && p.twigTagName() == "elseif" {
+		tagStart := p.pos
 		p.pos += 2 // skip "{%"
 		p.skipWhitespace()
-		p.pos += 4 // skip "else"
+		p.pos += 6 // skip "elseif"
 		p.skipWhitespace()
 
-		// Skip to the end of the else tag
-		for p.pos < p.length && p.peek(2) != "%}" {
-			p.pos++
-		}
-		if p.peek(2) != "%}" {
-			return nil, fmt.Errorf("unclosed else tag at pos %d", p.pos)
-		}
-		p.pos += 2 // skip "%}"
-
-		// Parse else branch
-		elseChildren, err = p.parseIfBranch()
-		if err != nil {
-			return nil, err
-		}
+		node.ElseIfConditions = append(node.ElseIfConditions, strings.TrimSpace(p.readTagEnd(tagStart, "elseif")))
+		tags = append(tags, Span{Start: tagStart, End: p.pos})
+		node.ElseIfChildren = append(node.ElseIfChildren, p.parseTwigBody("if"))
 	}
 
-	// Look for endif
-	if p.peek(2) != "{%" {
-		return nil, fmt.Errorf("missing endif at pos %d", p.pos)
+	// Parse the else branch if it exists
+	if p.peek(2) == "{%" && p.twigTagName() == "else" {
+		tagStart := p.pos
+		p.pos += 2 // skip "{%"
+		p.readTagEnd(tagStart, "else")
+		tags = append(tags, Span{Start: tagStart, End: p.pos})
+		node.ElseChildren = p.parseTwigBody("if")
 	}
-	p.pos += 2 // skip "{%"
-	p.skipWhitespace()
 
-	if !strings.HasPrefix(p.input[p.pos:], "endif") {
-		return nil, fmt.Errorf("missing endif at pos %d", p.pos)
-	}
-	p.pos += 5 // skip "endif"
+	// Look for endif, the branches end there or at the terminator of an outer tag
+	tags = append(tags, p.parseEndTag(startPos, "if"))
 
-	// Skip to end of closing tag
-	for p.pos < p.length && p.peek(2) != "%}" {
-		p.pos++
-	}
-	if p.peek(2) != "%}" {
-		return nil, fmt.Errorf("unclosed endif tag at pos %d", p.pos)
-	}
-	p.pos += 2 // skip "%}"
+	node.Span = Span{Start: startPos, End: p.pos}
+	node.Tags = tags
 
-	return &TwigIfNode{
-		Condition:        condition,
-		Children:         ifChildren,
-		ElseIfConditions: elseIfConditions,
-		ElseIfChildren:   elseIfChildren,
-		ElseChildren:     elseChildren,
-		Line:             p.getLineAt(startPos),
-	}, nil
+	return node
 }
 
-// parseIfBranch parses the contents of an if or else branch until it encounters
-// an {% else %}, {% elseif %} or {% endif %} tag
-func (p *Parser) parseIfBranch() (NodeList, error) {
+// parseTwigBody parses a branch of the twig tag until a terminator of an open twig tag,
+// like {% else %} or {% endif %} for an if.
+func (p *Parser) parseTwigBody(tag string) NodeList {
+	p.twig = append(p.twig, tag)
+	defer func() {
+		p.twig = p.twig[:len(p.twig)-1]
+	}()
+
 	var nodes NodeList
 	rawStart := p.pos
 
+loop:
 	for p.pos < p.length {
-		// Check for else, elseif or endif
-		if p.peek(2) == "{%" {
-			nextTag := p.input[p.pos+2:]
-			if strings.HasPrefix(strings.TrimSpace(nextTag), "else") ||
-				strings.HasPrefix(strings.TrimSpace(nextTag), "elseif") ||
-				strings.HasPrefix(strings.TrimSpace(nextTag), "endif") {
-				break
-			}
-		}
+		start := p.pos
+		var node Node
 
-		// Handle raw text
-		if p.pos > rawStart {
-			if p.peek(2) == "{%" || p.peek(2) == "{{" || p.peek(4) == "<!--" || p.current() == '<' {
-				text := p.input[rawStart:p.pos]
-				if text != "" {
-					nodes = append(nodes, &RawNode{
-						Text: text,
-						Line: p.getLineAt(rawStart),
-					})
+		switch {
+		case p.peek(2) == "{%":
+			if isTwigTerminator(p.twigTagName()) {
+				if p.parseTwigTerminator() {
+					break loop
 				}
-				rawStart = p.pos
+				break
 			}
+			node = p.parseTwigTag()
+		case p.peek(2) == "{{":
+			node = p.parseTemplateExpression()
+		case p.peek(4) == "<!--":
+			node = p.parseComment()
+		case p.current() == '<':
+			node = p.parseElement()
 		}
 
-		// Try parsing twig directives first
-		directive, err := p.parseTwigDirective()
-		if err != nil {
-			return nodes, err
-		}
-		if directive != nil {
-			nodes = append(nodes, directive)
-			rawStart = p.pos
-			continue
-		}
-
-		// If not a directive, try parsing as a block
-		block, err := p.parseTwigBlock()
-		if err != nil {
-			return nodes, err
-		}
-		if block != nil {
-			nodes = append(nodes, block)
-			rawStart = p.pos
-			continue
-		}
-
-		// Try parsing template expressions {{ ... }}
-		expr, err := p.parseTemplateExpression()
-		if err != nil {
-			return nodes, err
-		}
-		if expr != nil {
-			nodes = append(nodes, expr)
-			rawStart = p.pos
-			continue
-		}
-
-		// Try parsing HTML comments
-		comment, err := p.parseComment()
-		if err != nil {
-			return nodes, err
-		}
-		if comment != nil {
-			nodes = append(nodes, comment)
-			rawStart = p.pos
-			continue
-		}
-
-		// Try parsing HTML elements
-		element, err := p.parseElement()
-		if err != nil {
-			return nodes, err
-		}
-		if element != nil {
-			nodes = append(nodes, element)
-			rawStart = p.pos
+		// If nothing matched, advance one character
+		if node == nil {
+			p.pos = start + 1
 			continue
 		}
 
-		// If nothing matched, advance one character
-		if p.pos < p.length {
-			p.pos++
-		} else {
-			break
+		if start > rawStart {
+			nodes = append(nodes, p.rawNode(rawStart, p.input[rawStart:start]))
 		}
+		nodes = append(nodes, node)
+		rawStart = p.pos
 	}
 
 	// Add any remaining raw text
 	if p.pos > rawStart {
-		text := p.input[rawStart:p.pos]
-		if text != "" {
-			nodes = append(nodes, &RawNode{
-				Text: text,
-				Line: p.getLineAt(rawStart),
-			})
-		}
+		nodes = append(nodes, p.rawNode(rawStart, p.input[rawStart:p.pos]))
 	}
 
-	return nodes, nil
+	return nodes
 }
 
 // parseTemplateExpression parses a {{...}} template expression and returns a TemplateExpressionNode
-func (p *Parser) parseTemplateExpression() (*TemplateExpressionNode, error) {
+func (p *Parser) parseTemplateExpression() Node {
 	if p.peek(2) != "{{" {
-		return nil, nil
+		return nil
 	}
 
 	startPos := p.pos
-	p.pos += 2 // skip "{{"
+	start := p.pos + 2 // skip "{{"
 
 	// Find the closing "}}"
-	start := p.pos
-	idx := strings.Index(p.input[p.pos:], "}}")
+	idx := strings.Index(p.input[start:], "}}")
 	if idx == -1 {
-		return nil, fmt.Errorf("unterminated template expression starting at pos %d", startPos)
+		p.report(startPos, "unterminated template expression")
+		return nil
 	}
 
 	expression := p.input[start : start+idx]
-	p.pos += idx + 2 // skip past "}}"
+	p.pos = start + idx + 2 // skip past "}}"
 
 	return &TemplateExpressionNode{
+		Span:       Span{Start: startPos, End: p.pos},
 		Expression: expression,
 		Line:       p.getLineAt(startPos),
-	}, nil
+	}
 }
 
+// TraverseNode calls f for every element in the nodes, including the elements in all branches
+// of twig tags and in the twig tags between attributes.
 func TraverseNode(n NodeList, f func(*ElementNode)) {
 	for _, node := range n {
 		switch node := node.(type) {
 		case *ElementNode:
 			f(node)
-			for _, child := range node.Children {
-				TraverseNode(NodeList{child}, f)
-			}
+			TraverseNode(node.Attributes, f)
+			TraverseNode(node.Children, f)
 		case *TwigBlockNode:
 			TraverseNode(node.Children, f)
-		case *TemplateExpressionNode:
-			// Template expressions don't have children to traverse
-			continue
+		case *TwigIfNode:
+			TraverseNode(node.Children, f)
+			for _, branch := range node.ElseIfChildren {
+				TraverseNode(branch, f)
+			}
+			TraverseNode(node.ElseChildren, f)
+		case *TwigForNode:
+			TraverseNode(node.Children, f)
+			TraverseNode(node.ElseChildren, f)
+		case *TwigSetNode:
+			TraverseNode(node.Children, f)
+		}
+	}
+}
+
+// TraverseChildren calls f for the nodes and every list of child nodes in them, including all branches
+// of twig tags. Replacing a node in a list replaces it in the tree, the new node is traversed.
+func TraverseChildren(n NodeList, f func(NodeList)) {
+	f(n)
+
+	for _, node := range n {
+		switch node := node.(type) {
+		case *ElementNode:
+			TraverseChildren(node.Children, f)
+		case *TwigBlockNode:
+			TraverseChildren(node.Children, f)
+		case *TwigIfNode:
+			TraverseChildren(node.Children, f)
+			for _, branch := range node.ElseIfChildren {
+				TraverseChildren(branch, f)
+			}
+			TraverseChildren(node.ElseChildren, f)
+		case *TwigForNode:
+			TraverseChildren(node.Children, f)
+			TraverseChildren(node.ElseChildren, f)
+		case *TwigSetNode:
+			TraverseChildren(node.Children, f)
 		}
 	}
 }
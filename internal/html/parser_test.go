@@ -31,7 +31,7 @@ func TestFormattingOfHTML(t *testing.T) {
         label="Click me"
         variant="primary"
     ></sw-button>
-</template>`, node.Dump(0))
+</template>`, node.Dump(0, DefaultFormatOptions()))
 
 	simpleButton := &ElementNode{
 		Tag: "sw-button",
@@ -40,7 +40,7 @@ func TestFormattingOfHTML(t *testing.T) {
 		},
 	}
 
-	assert.Equal(t, `<sw-button>Click me</sw-button>`, simpleButton.Dump(0))
+	assert.Equal(t, `<sw-button>Click me</sw-button>`, simpleButton.Dump(0, DefaultFormatOptions()))
 }
 
 func TestFormatting(t *testing.T) {
@@ -76,15 +76,15 @@ func TestFormatting(t *testing.T) {
 				t.Fatal(err)
 			}
 
-			assert.Equal(t, stringParts[1], parsed.Dump(0))
+			assert.Equal(t, stringParts[1], parsed.Dump(0, DefaultFormatOptions()))
 
-			parsed, err = NewParser(parsed.Dump(0))
+			parsed, err = NewParser(parsed.Dump(0, DefaultFormatOptions()))
 
 			if err != nil {
 				t.Fatal(err)
 			}
 
-			assert.Equal(t, stringParts[1], parsed.Dump(0))
+			assert.Equal(t, stringParts[1], parsed.Dump(0, DefaultFormatOptions()))
 		})
 	}
 
@@ -108,7 +108,7 @@ func TestChangeElement(t *testing.T) {
 		}
 		n.Attributes = newAttributes
 	})
-	assert.Equal(t, `<mt-select @update:modelValue="onUpdateValue"/>`, node.Dump(0))
+	assert.Equal(t, `<mt-select @update:modelValue="onUpdateValue"/>`, node.Dump(0, DefaultFormatOptions()))
 }
 
 func TestBlockParsing(t *testing.T) {
@@ -117,9 +117,174 @@ func TestBlockParsing(t *testing.T) {
 	node, err := NewParser(input)
 	assert.NoError(t, err)
 
-	assert.Equal(t, input, node.Dump(0))
+	assert.Equal(t, input, node.Dump(0, DefaultFormatOptions()))
 
 	block, ok := node[0].(*TwigBlockNode)
 	assert.True(t, ok)
 	assert.Equal(t, "name", block.Name)
 }
+
+func TestParseDiagnostics(t *testing.T) {
+	cases := []struct {
+		name        string
+		input       string
+		diagnostics []Diagnostic
+	}{
+		{
+			name:  "unclosed element",
+			input: "<div>\n    <span>text\n</div>",
+			diagnostics: []Diagnostic{
+				{Message: "element <span> is not closed before </div>", Line: 2, Column: 5},
+			},
+		},
+		{
+			name:  "stray endblock",
+			input: "{% block a %}{% endblock %}\n{% endblock %}",
+			diagnostics: []Diagnostic{
+				{Message: "unexpected endblock without block", Line: 2, Column: 1},
+			},
+		},
+		{
+			name:  "missing quote",
+			input: "<sw-button label=\"Save>text</sw-button>",
+			diagnostics: []Diagnostic{
+				{Message: "attribute value is missing its closing quote", Line: 1, Column: 18},
+			},
+		},
+		{
+			name:  "unclosed block",
+			input: "{% block a %}\n<div></div>",
+			diagnostics: []Diagnostic{
+				{Message: "block a is not closed, expected endblock", Line: 1, Column: 1},
+			},
+		},
+		{
+			name:  "stray closing tag",
+			input: "<div></span></div>",
+			diagnostics: []Diagnostic{
+				{Message: "unexpected closing tag </span>", Line: 1, Column: 6},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, diagnostics := Parse(tc.input)
+			assert.Equal(t, tc.diagnostics, diagnostics)
+
+			_, err := NewParser(tc.input)
+			assert.EqualError(t, err, tc.diagnostics[0].Error())
+		})
+	}
+}
+
+func TestParsePartialTree(t *testing.T) {
+	input := `{% block a %}
+    <div>
+        <sw-button label="Save>Save</sw-button>
+    </div>
+{% endblock %}
+{% endblock %}
+<sw-icon name="default"></sw-icon>`
+
+	nodes, diagnostics := Parse(input)
+	assert.Len(t, diagnostics, 2)
+
+	block, ok := nodes[0].(*TwigBlockNode)
+	assert.True(t, ok)
+
+	div, ok := block.Children[0].(*ElementNode)
+	assert.True(t, ok)
+
+	// Children of elements keep the whitespace before them
+	button, ok := div.Children[1].(*ElementNode)
+	assert.True(t, ok)
+	assert.Equal(t, "sw-button", button.Tag)
+
+	// The nodes after the stray endblock are still parsed
+	icon, ok := nodes[len(nodes)-1].(*ElementNode)
+	assert.True(t, ok)
+	assert.Equal(t, "sw-icon", icon.Tag)
+}
+
+func TestTwigTagParsing(t *testing.T) {
+	nodes, err := NewParser(`{% for item in items %}{% set label %}{{ item.name }}{% endset %}{% else %}{% include 'empty.html.twig' %}{% endfor %}{% do foo %}`)
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 2)
+
+	loop, ok := nodes[0].(*TwigForNode)
+	assert.True(t, ok)
+	assert.Equal(t, "item in items", loop.Expression)
+	assert.Len(t, loop.Tags, 3)
+
+	set, ok := loop.Children[0].(*TwigSetNode)
+	assert.True(t, ok)
+	assert.Equal(t, "label", set.Name)
+	assert.Equal(t, "", set.Value)
+	assert.Len(t, set.Children, 1)
+
+	include, ok := loop.ElseChildren[0].(*TwigIncludeNode)
+	assert.True(t, ok)
+	assert.Equal(t, "include", include.Name)
+	assert.Equal(t, "'empty.html.twig'", include.Expression)
+
+	tag, ok := nodes[1].(*TwigTagNode)
+	assert.True(t, ok)
+	assert.Equal(t, "do", tag.Name)
+	assert.Equal(t, "foo", tag.Arguments)
+}
+
+func TestTwigTagDiagnostics(t *testing.T) {
+	_, diagnostics := Parse("{% for item in items %}\n<div>\n{% endfor %}\n{% else %}")
+
+	assert.Equal(t, []Diagnostic{
+		{Message: "element <div> is not closed before endfor tag", Line: 2, Column: 1},
+		{Message: "unexpected else without for or if", Line: 4, Column: 1},
+	}, diagnostics)
+
+	_, diagnostics = Parse("{% if a %}{% for item in items %}{% endif %}")
+
+	assert.Equal(t, []Diagnostic{
+		{Message: "for is not closed, expected endfor", Line: 1, Column: 11},
+	}, diagnostics)
+}
+
+func TestTraverseNodeVisitsAllBranches(t *testing.T) {
+	nodes, err := NewParser(`{% block a %}
+<div {% if a %}class="a"{% endif %}>
+    {% if b %}<sw-if/>{% elseif c %}<sw-elseif/>{% else %}<sw-else/>{% endif %}
+    {% for item in items %}<sw-for/>{% else %}<sw-for-else/>{% endfor %}
+    {% set content %}<sw-set/>{% endset %}
+</div>
+{% endblock %}`)
+	assert.NoError(t, err)
+
+	var tags []string
+	TraverseNode(nodes, func(node *ElementNode) {
+		tags = append(tags, node.Tag)
+	})
+
+	assert.Equal(t, []string{"div", "sw-if", "sw-elseif", "sw-else", "sw-for", "sw-for-else", "sw-set"}, tags)
+}
+
+func TestTraverseChildrenReplacesNodes(t *testing.T) {
+	nodes, err := NewParser(`<div>{% if a %}<span>{{ a }}</span>{% endif %}</div>`)
+	assert.NoError(t, err)
+
+	var expressions []string
+	wrapped := false
+	TraverseChildren(nodes, func(list NodeList) {
+		for i, node := range list {
+			if element, ok := node.(*ElementNode); ok && element.Tag == "span" && !wrapped {
+				list[i] = &ElementNode{Tag: "template", Children: NodeList{element}}
+				wrapped = true
+			}
+			if expression, ok := node.(*TemplateExpressionNode); ok {
+				expressions = append(expressions, expression.Expression)
+			}
+		}
+	})
+
+	assert.Equal(t, []string{" a "}, expressions)
+	assert.Equal(t, "<div>\n    {% if a %}\n        <template>\n            <span>{{ a }}</span>\n        </template>\n    {% endif %}\n</div>", nodes.Dump(0, DefaultFormatOptions()))
+}
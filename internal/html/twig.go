@@ -0,0 +1,290 @@
+package html
+
+import (
+	"slices"
+	"strings"
+)
+
+// twigTerminators are the tags which divide or end the body of a twig tag, keyed by the opening tag.
+var twigTerminators = map[string][]string{
+	"block": {"endblock"},
+	"if":    {"elseif", "else", "endif"},
+	"for":   {"else", "endfor"},
+	"set":   {"endset"},
+}
+
+// isTwigTerminator reports whether the tag divides or ends the body of a twig tag.
+func isTwigTerminator(name string) bool {
+	for _, terminators := range twigTerminators {
+		if slices.Contains(terminators, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// TwigForNode represents a {% for %} loop
+type TwigForNode struct {
+	Span
+	// The loop declaration, like item in items
+	Expression   string
+	Children     NodeList
+	ElseChildren NodeList
+	Line         int
+	// Spans of the for, else and endfor tags in the order of the template
+	Tags []Span
+}
+
+// Dump returns the twig for loop with proper formatting
+func (t *TwigForNode) Dump(indent int, options FormatOptions) string {
+	var builder strings.Builder
+	indentStr := strings.Repeat(options.indentUnit(), indent)
+
+	builder.WriteString(indentStr + "{% for " + t.Expression + " %}")
+	dumpBranch(&builder, t.Children, indent, options)
+
+	if len(t.ElseChildren) > 0 {
+		builder.WriteString(indentStr + "{% else %}")
+		dumpBranch(&builder, t.ElseChildren, indent, options)
+	}
+
+	builder.WriteString(indentStr + "{% endfor %}")
+
+	return builder.String()
+}
+
+// TwigSetNode represents {% set name = value %} or the capturing {% set name %}...{% endset %}
+type TwigSetNode struct {
+	Span
+	// The assigned variables, like a or a, b
+	Name string
+	// The assigned value, empty when the children are captured
+	Value    string
+	Children NodeList
+	Line     int
+	// Spans of the set and endset tags
+	Tags []Span
+}
+
+// Dump returns the twig set tag with proper formatting
+func (t *TwigSetNode) Dump(indent int, options FormatOptions) string {
+	indentStr := strings.Repeat(options.indentUnit(), indent)
+
+	if t.Value != "" {
+		return indentStr + "{% set " + t.Name + " = " + t.Value + " %}"
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString(indentStr + "{% set " + t.Name + " %}")
+	dumpBranch(&builder, t.Children, indent, options)
+	builder.WriteString(indentStr + "{% endset %}")
+
+	return builder.String()
+}
+
+// TwigIncludeNode represents {% include %} and {% sw_include %}
+type TwigIncludeNode struct {
+	Span
+	// include or sw_include
+	Name string
+	// The template and the options, like '@Foo/bar.html.twig' with { a: b } only
+	Expression string
+	Line       int
+}
+
+// Dump returns the include tag
+func (t *TwigIncludeNode) Dump(indent int, options FormatOptions) string {
+	return strings.Repeat(options.indentUnit(), indent) + "{% " + t.Name + " " + t.Expression + " %}"
+}
+
+// TwigTagNode represents any other twig tag, like {% do %}. Tags with a body are not nested,
+// the closing tag is a node on its own.
+type TwigTagNode struct {
+	Span
+	Name      string
+	Arguments string
+	Line      int
+}
+
+// Dump returns the tag
+func (t *TwigTagNode) Dump(indent int, options FormatOptions) string {
+	if t.Arguments == "" {
+		return strings.Repeat(options.indentUnit(), indent) + "{% " + t.Name + " %}"
+	}
+	return strings.Repeat(options.indentUnit(), indent) + "{% " + t.Name + " " + t.Arguments + " %}"
+}
+
+// dumpBranch writes the nodes of a branch of a twig tag, every node on its own line.
+func dumpBranch(builder *strings.Builder, nodes NodeList, indent int, options FormatOptions) {
+	// Filter out empty nodes and normalize newlines
+	var nonEmptyChildren NodeList
+	for _, child := range nodes {
+		if raw, ok := child.(*RawNode); ok {
+			if strings.TrimSpace(raw.Text) != "" {
+				nonEmptyChildren = append(nonEmptyChildren, raw)
+			}
+		} else {
+			nonEmptyChildren = append(nonEmptyChildren, child)
+		}
+	}
+
+	if len(nonEmptyChildren) == 0 {
+		return
+	}
+
+	builder.WriteString("\n")
+	for i, child := range nonEmptyChildren {
+		if elementChild, ok := child.(*ElementNode); ok {
+			builder.WriteString(elementChild.Dump(indent+1, options))
+		} else {
+			builder.WriteString(strings.Repeat(options.indentUnit(), indent+1))
+			builder.WriteString(strings.TrimSpace(child.Dump(indent+1, options)))
+		}
+		if i < len(nonEmptyChildren)-1 {
+			builder.WriteString("\n")
+		}
+	}
+	builder.WriteString("\n")
+}
+
+// parseTwigFor parses a {% for ... %} ... {% endfor %} loop and returns a TwigForNode
+func (p *Parser) parseTwigFor() Node {
+	if p.peek(2) != "{%" || p.twigTagName() != "for" {
+		return nil
+	}
+
+	startPos := p.pos
+	p.pos += 2 // skip "{%"
+	p.skipWhitespace()
+	p.pos += 3 // skip "for"
+	p.skipWhitespace()
+
+	node := &TwigForNode{
+		Expression: strings.TrimSpace(p.readTagEnd(startPos, "for")),
+		Line:       p.getLineAt(startPos),
+	}
+	tags := []Span{{Start: startPos, End: p.pos}}
+
+	node.Children = p.parseTwigBody("for")
+
+	if p.peek(2) == "{%" && p.twigTagName() == "else" {
+		tagStart := p.pos
+		p.pos += 2 // skip "{%"
+		p.readTagEnd(tagStart, "else")
+		tags = append(tags, Span{Start: tagStart, End: p.pos})
+		node.ElseChildren = p.parseTwigBody("for")
+	}
+
+	tags = append(tags, p.parseEndTag(startPos, "for"))
+
+	node.Span = Span{Start: startPos, End: p.pos}
+	node.Tags = tags
+
+	return node
+}
+
+// parseTwigSet parses {% set name = value %} and {% set name %} ... {% endset %} and returns a TwigSetNode
+func (p *Parser) parseTwigSet() Node {
+	if p.peek(2) != "{%" || p.twigTagName() != "set" {
+		return nil
+	}
+
+	startPos := p.pos
+	p.pos += 2 // skip "{%"
+	p.skipWhitespace()
+	p.pos += 3 // skip "set"
+	p.skipWhitespace()
+
+	content := strings.TrimSpace(p.readTagEnd(startPos, "set"))
+	node := &TwigSetNode{Name: content, Line: p.getLineAt(startPos)}
+	tags := []Span{{Start: startPos, End: p.pos}}
+
+	if name, value, ok := strings.Cut(content, "="); ok {
+		node.Name = strings.TrimSpace(name)
+		node.Value = strings.TrimSpace(value)
+	} else {
+		node.Children = p.parseTwigBody("set")
+		tags = append(tags, p.parseEndTag(startPos, "set"))
+	}
+
+	node.Span = Span{Start: startPos, End: p.pos}
+	node.Tags = tags
+
+	return node
+}
+
+// parseTwigInclude parses {% include %} and {% sw_include %} and returns a TwigIncludeNode
+func (p *Parser) parseTwigInclude() Node {
+	if p.peek(2) != "{%" {
+		return nil
+	}
+
+	name := p.twigTagName()
+	if name != "include" && name != "sw_include" {
+		return nil
+	}
+
+	startPos := p.pos
+	p.pos += 2 // skip "{%"
+	p.skipWhitespace()
+	p.pos += len(name)
+
+	return &TwigIncludeNode{
+		Name:       name,
+		Expression: strings.TrimSpace(p.readTagEnd(startPos, name)),
+		Line:       p.getLineAt(startPos),
+		Span:       Span{Start: startPos, End: p.pos},
+	}
+}
+
+// parseTwigGenericTag parses any twig tag which is not a terminator of an other tag
+func (p *Parser) parseTwigGenericTag() Node {
+	if p.peek(2) != "{%" {
+		return nil
+	}
+
+	name := p.twigTagName()
+	if name == "" || isTwigTerminator(name) {
+		return nil
+	}
+
+	startPos := p.pos
+	p.pos += 2 // skip "{%"
+	p.skipWhitespace()
+	p.pos += len(name)
+
+	return &TwigTagNode{
+		Name:      name,
+		Arguments: strings.TrimSpace(p.readTagEnd(startPos, name)),
+		Line:      p.getLineAt(startPos),
+		Span:      Span{Start: startPos, End: p.pos},
+	}
+}
+
+// parseEndTag parses the end tag of the twig tag started at startPos. A missing end tag has
+// an empty span at the current position.
+func (p *Parser) parseEndTag(startPos int, tag string) Span {
+	end := "end" + tag
+
+	if p.peek(2) != "{%" || p.twigTagName() != end {
+		p.report(startPos, "%s is not closed, expected %s", tag, end)
+		return Span{Start: p.pos, End: p.pos}
+	}
+
+	tagStart := p.pos
+	p.pos += 2 // skip "{%"
+	p.readTagEnd(tagStart, end)
+
+	return Span{Start: tagStart, End: p.pos}
+}
+
+// closesTwig reports whether the tag divides or ends the body of an open twig tag.
+func (p *Parser) closesTwig(name string) bool {
+	for _, open := range p.twig {
+		if slices.Contains(twigTerminators[open], name) {
+			return true
+		}
+	}
+	return false
+}
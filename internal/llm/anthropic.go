@@ -0,0 +1,140 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	anthropicVersion   = "2023-06-01"
+	anthropicMaxTokens = 4096
+)
+
+// AnthropicClient represents a client of the Anthropic Messages API
+type AnthropicClient struct {
+	usageCounter
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	headers map[string]string
+}
+
+// AnthropicRequest represents the request body of the messages endpoint
+type AnthropicRequest struct {
+	Model       string    `json:"model"`
+	System      string    `json:"system,omitempty"`
+	Messages    []Message `json:"messages"`
+	MaxTokens   int       `json:"max_tokens"`
+	Temperature *float64  `json:"temperature,omitempty"`
+}
+
+// AnthropicResponse represents the response of the messages endpoint
+type AnthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+	Usage      struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+// newAnthropicClient creates a new Anthropic client instance
+func newAnthropicClient(profile ProviderProfile) (*AnthropicClient, error) {
+	apiKey := profile.apiKey()
+	if apiKey == "" {
+		return nil, fmt.Errorf("%s is not set", profile.APIKeyEnv)
+	}
+
+	return &AnthropicClient{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(profile.BaseURL, "/"),
+		headers: profile.Headers,
+		client:  &http.Client{},
+	}, nil
+}
+
+// Generate sends a request to the Anthropic Messages API
+func (c *AnthropicClient) Generate(ctx context.Context, prompt string, options *LLMOptions) (string, error) {
+	reqBody := AnthropicRequest{
+		Model:  options.Model,
+		System: options.SystemPrompt,
+		Messages: []Message{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+		MaxTokens:   options.MaxTokens,
+		Temperature: options.Temperature,
+	}
+
+	if reqBody.MaxTokens == 0 {
+		reqBody.MaxTokens = anthropicMaxTokens
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v1/messages", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", anthropicVersion)
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close response body: %v\n", err)
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", newStatusError(resp, body)
+	}
+
+	var response AnthropicResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	c.record(ctx, Usage{PromptTokens: response.Usage.InputTokens, CompletionTokens: response.Usage.OutputTokens})
+
+	var text strings.Builder
+	for _, content := range response.Content {
+		if content.Type == "text" {
+			text.WriteString(content.Text)
+		}
+	}
+
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no text content returned, stop reason: %s", response.StopReason)
+	}
+
+	return text.String(), nil
+}
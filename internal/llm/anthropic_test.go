@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnthropicClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("x-api-key"))
+		assert.Equal(t, anthropicVersion, r.Header.Get("anthropic-version"))
+		assert.Equal(t, "team", r.Header.Get("X-Team"))
+
+		var request AnthropicRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "test-model", request.Model)
+		assert.Equal(t, "system", request.System)
+		assert.Equal(t, 1024, request.MaxTokens)
+		assert.Equal(t, 0.2, *request.Temperature)
+
+		_, _ = w.Write([]byte(`{"content":[{"type":"text","text":"done"}],"stop_reason":"end_turn","usage":{"input_tokens":12,"output_tokens":3}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_ANTHROPIC_KEY", "secret")
+
+	profile, err := ResolveProfile("team", map[string]ProviderProfile{
+		"team": {Type: "anthropic", BaseURL: server.URL, APIKeyEnv: "TEST_ANTHROPIC_KEY", Headers: map[string]string{"X-Team": "team"}},
+	})
+	assert.NoError(t, err)
+
+	client, err := NewClient(profile, testClientConfig())
+	assert.NoError(t, err)
+
+	temperature := 0.2
+	response, err := client.Generate(t.Context(), "prompt", &LLMOptions{Model: "test-model", SystemPrompt: "system", MaxTokens: 1024, Temperature: &temperature})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "done", response)
+	assert.Equal(t, Usage{PromptTokens: 12, CompletionTokens: 3}, client.Usage())
+}
+
+func TestResolveProfile(t *testing.T) {
+	temperature := 0.1
+	profiles := map[string]ProviderProfile{
+		"vllm": {BaseURL: "http://localhost:8000", Model: "qwen", Temperature: &temperature, MaxTokens: 2048},
+	}
+
+	profile, err := ResolveProfile("vllm", profiles)
+	assert.NoError(t, err)
+	assert.Equal(t, "openai", profile.Type)
+	assert.Equal(t, "OPENAI_API_KEY", profile.APIKeyEnv)
+
+	options := &LLMOptions{MaxTokens: 100}
+	profile.ApplyDefaults(options)
+	assert.Equal(t, "qwen", options.Model)
+	assert.Equal(t, &temperature, options.Temperature)
+	assert.Equal(t, 100, options.MaxTokens)
+
+	profile, err = ResolveProfile("anthropic", profiles)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.anthropic.com", profile.BaseURL)
+
+	_, err = ResolveProfile("unknown", profiles)
+	assert.ErrorContains(t, err, "vllm")
+}
@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// ErrNotRecorded is returned in replay mode when no response has been recorded for a prompt.
+var ErrNotRecorded = errors.New("no recorded LLM response")
+
+// CachedClient stores the responses of a client on disk and serves repeated prompts from there.
+// Without a client it runs in replay mode and only serves recorded responses.
+type CachedClient struct {
+	client   LLMClient
+	provider string
+	dir      string
+}
+
+type cachedResponse struct {
+	Provider   string `json:"provider"`
+	Model      string `json:"model"`
+	PromptHash string `json:"prompt_hash"`
+	Prompt     string `json:"prompt"`
+	Response   string `json:"response"`
+}
+
+// NewCachedClient wraps client and records its responses in dir.
+func NewCachedClient(client LLMClient, provider, dir string) *CachedClient {
+	return &CachedClient{client: client, provider: provider, dir: dir}
+}
+
+// NewReplayClient serves only responses recorded in dir and fails on prompts which have not been recorded.
+func NewReplayClient(provider, dir string) *CachedClient {
+	return &CachedClient{provider: provider, dir: dir}
+}
+
+func (c *CachedClient) Generate(ctx context.Context, prompt string, options *LLMOptions) (string, error) {
+	promptHash := hashString(prompt)
+	file := filepath.Join(c.dir, cacheKey(c.provider, options, promptHash)+".json")
+
+	content, err := os.ReadFile(file)
+
+	if err == nil {
+		var cached cachedResponse
+
+		if err := json.Unmarshal(content, &cached); err != nil {
+			return "", fmt.Errorf("failed to read recorded response %s: %w", file, err)
+		}
+
+		return cached.Response, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+
+	if c.client == nil {
+		return "", fmt.Errorf("%w for prompt %s of model %s in %s", ErrNotRecorded, promptHash, options.Model, c.dir)
+	}
+
+	response, err := c.client.Generate(ctx, prompt, options)
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := c.store(file, cachedResponse{
+		Provider:   c.provider,
+		Model:      options.Model,
+		PromptHash: promptHash,
+		Prompt:     prompt,
+		Response:   response,
+	}); err != nil {
+		return "", fmt.Errorf("failed to record response: %w", err)
+	}
+
+	return response, nil
+}
+
+func (c *CachedClient) Usage() Usage {
+	if c.client == nil {
+		return Usage{}
+	}
+
+	return c.client.Usage()
+}
+
+func (c *CachedClient) store(file string, response cachedResponse) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(response, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(file+".tmp", content, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(file+".tmp", file)
+}
+
+// cacheKey identifies a response by the provider, model, system prompt, sampling settings and the hash of the prompt.
+func cacheKey(provider string, options *LLMOptions, promptHash string) string {
+	// Settings which are not set use the provider default
+	temperature, seed := "default", "default"
+
+	if options.Temperature != nil {
+		temperature = strconv.FormatFloat(*options.Temperature, 'g', -1, 64)
+	}
+
+	if options.Seed != nil {
+		seed = strconv.Itoa(*options.Seed)
+	}
+
+	return hashString(provider + "\x00" + options.Model + "\x00" + options.SystemPrompt + "\x00" + temperature + "\x00" + strconv.Itoa(options.MaxTokens) + "\x00" + seed + "\x00" + promptHash)
+}
+
+func hashString(s string) string {
+	hash := sha256.Sum256([]byte(s))
+
+	return hex.EncodeToString(hash[:])
+}
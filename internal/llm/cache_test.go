@@ -0,0 +1,90 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClient struct {
+	usageCounter
+	calls int
+}
+
+func (f *fakeClient) Generate(ctx context.Context, prompt string, options *LLMOptions) (string, error) {
+	f.calls++
+	f.add(Usage{PromptTokens: len(prompt), CompletionTokens: 1})
+
+	return "answer to " + prompt, nil
+}
+
+func TestCachedClientRecordsResponses(t *testing.T) {
+	dir := t.TempDir()
+	inner := &fakeClient{}
+	client := NewCachedClient(inner, "ollama", dir)
+	options := &LLMOptions{Model: "gemma3:4b", SystemPrompt: "system"}
+
+	first, err := client.Generate(t.Context(), "prompt", options)
+	assert.NoError(t, err)
+	second, err := client.Generate(t.Context(), "prompt", options)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "answer to prompt", first)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, inner.calls)
+	assert.Equal(t, Usage{PromptTokens: 6, CompletionTokens: 1}, client.Usage())
+
+	// Another system prompt is another cache entry
+	_, err = client.Generate(t.Context(), "prompt", &LLMOptions{Model: "gemma3:4b", SystemPrompt: "other"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestCachedClientKeyContainsSamplingSettings(t *testing.T) {
+	dir := t.TempDir()
+	temperature, other := 0.2, 0.7
+	seed := 42
+	options := &LLMOptions{Model: "gemma3:4b", Temperature: &temperature, MaxTokens: 1024, Seed: &seed}
+
+	_, err := NewCachedClient(&fakeClient{}, "ollama", dir).Generate(t.Context(), "recorded", options)
+	assert.NoError(t, err)
+
+	replay := NewReplayClient("ollama", dir)
+
+	_, err = replay.Generate(t.Context(), "recorded", &LLMOptions{Model: "gemma3:4b", Temperature: &temperature, MaxTokens: 1024, Seed: &seed})
+	assert.NoError(t, err)
+
+	// Only the temperature changed
+	_, err = replay.Generate(t.Context(), "recorded", &LLMOptions{Model: "gemma3:4b", Temperature: &other, MaxTokens: 1024, Seed: &seed})
+	assert.ErrorIs(t, err, ErrNotRecorded)
+
+	_, err = replay.Generate(t.Context(), "recorded", &LLMOptions{Model: "gemma3:4b", MaxTokens: 1024, Seed: &seed})
+	assert.ErrorIs(t, err, ErrNotRecorded)
+
+	_, err = replay.Generate(t.Context(), "recorded", &LLMOptions{Model: "gemma3:4b", Temperature: &temperature, MaxTokens: 2048, Seed: &seed})
+	assert.ErrorIs(t, err, ErrNotRecorded)
+
+	_, err = replay.Generate(t.Context(), "recorded", &LLMOptions{Model: "gemma3:4b", Temperature: &temperature, MaxTokens: 1024})
+	assert.ErrorIs(t, err, ErrNotRecorded)
+}
+
+func TestReplayClient(t *testing.T) {
+	dir := t.TempDir()
+	options := &LLMOptions{Model: "gemma3:4b"}
+
+	_, err := NewCachedClient(&fakeClient{}, "ollama", dir).Generate(t.Context(), "recorded", options)
+	assert.NoError(t, err)
+
+	replay := NewReplayClient("ollama", dir)
+
+	response, err := replay.Generate(t.Context(), "recorded", options)
+	assert.NoError(t, err)
+	assert.Equal(t, "answer to recorded", response)
+
+	_, err = replay.Generate(t.Context(), "unknown", options)
+	assert.ErrorIs(t, err, ErrNotRecorded)
+
+	_, err = NewReplayClient("openai", dir).Generate(t.Context(), "recorded", options)
+	assert.ErrorIs(t, err, ErrNotRecorded)
+}
@@ -2,29 +2,33 @@ package llm
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
-	"strings"
-	"time"
-
-	"github.com/charmbracelet/log"
 
 	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 )
 
 type GeminiClient struct {
+	usageCounter
 	client *genai.Client
 }
 
-func newGeminiClient() (*GeminiClient, error) {
-	apiKey := os.Getenv("GEMINI_API_KEY")
+func newGeminiClient(profile ProviderProfile) (*GeminiClient, error) {
+	apiKey := profile.apiKey()
 
 	if apiKey == "" {
-		return nil, fmt.Errorf("GEMINI_API_KEY is not set")
+		return nil, fmt.Errorf("%s is not set", profile.APIKeyEnv)
 	}
 
-	client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+	clientOptions := []option.ClientOption{option.WithAPIKey(apiKey)}
+
+	if profile.BaseURL != "" {
+		clientOptions = append(clientOptions, option.WithEndpoint(profile.BaseURL))
+	}
+
+	client, err := genai.NewClient(context.Background(), clientOptions...)
 
 	if err != nil {
 		return nil, err
@@ -34,18 +38,40 @@ func newGeminiClient() (*GeminiClient, error) {
 }
 
 func (c *GeminiClient) Generate(ctx context.Context, prompt string, options *LLMOptions) (string, error) {
-	resp, err := c.client.GenerativeModel(options.Model).GenerateContent(ctx, genai.Text(options.SystemPrompt+"\n\n"+prompt))
+	model := c.client.GenerativeModel(options.Model)
 
-	if err != nil {
-		if strings.Contains(err.Error(), "Resource has been exhausted") {
-			log.Warn("Resource exhausted, waiting 15 seconds before retrying")
-			time.Sleep(15 * time.Second)
+	if options.Temperature != nil {
+		model.SetTemperature(float32(*options.Temperature))
+	}
+
+	if options.MaxTokens > 0 {
+		model.SetMaxOutputTokens(int32(options.MaxTokens))
+	}
+
+	resp, err := model.GenerateContent(ctx, genai.Text(options.SystemPrompt+"\n\n"+prompt))
 
-			return c.Generate(ctx, prompt, options)
+	if err != nil {
+		var apiErr *googleapi.Error
+		if errors.As(err, &apiErr) {
+			return "", &StatusError{StatusCode: apiErr.Code, Body: apiErr.Message}
 		}
 
 		return "", err
 	}
 
-	return string(resp.Candidates[0].Content.Parts[0].(genai.Text)), nil
+	if resp.UsageMetadata != nil {
+		c.record(ctx, Usage{PromptTokens: int(resp.UsageMetadata.PromptTokenCount), CompletionTokens: int(resp.UsageMetadata.CandidatesTokenCount)})
+	}
+
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil || len(resp.Candidates[0].Content.Parts) == 0 {
+		return "", fmt.Errorf("no response candidates returned")
+	}
+
+	text, ok := resp.Candidates[0].Content.Parts[0].(genai.Text)
+
+	if !ok {
+		return "", fmt.Errorf("unexpected response part %T", resp.Candidates[0].Content.Parts[0])
+	}
+
+	return string(text), nil
 }
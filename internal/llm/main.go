@@ -1,27 +1,59 @@
 package llm
 
-import "context"
+import (
+	"context"
+	"fmt"
+)
 
 type LLMOptions struct {
 	Model        string
 	SystemPrompt string
+	// Sampling temperature, the provider default is used when nil
+	Temperature *float64
+	// Maximum number of tokens to generate, the provider default is used when 0
+	MaxTokens int
+	// Seed for deterministic sampling, not supported by all providers
+	Seed *int
 }
 
 type LLMClient interface {
 	Generate(ctx context.Context, prompt string, options *LLMOptions) (string, error)
+	// Usage returns the tokens consumed by all requests of the client so far
+	Usage() Usage
 }
 
-func NewLLMClient(provider string) (LLMClient, error) {
-	switch provider {
-	case "ollama":
-		return newOpenAIClient()
+// NewLLMClient creates the client of a built-in provider, failed requests are retried as configured in config.
+func NewLLMClient(provider string, config ClientConfig) (LLMClient, error) {
+	profile, err := ResolveProfile(provider, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewClient(profile, config)
+}
+
+// NewClient creates the client for profile, failed requests are retried as configured in config.
+func NewClient(profile ProviderProfile, config ClientConfig) (LLMClient, error) {
+	var client LLMClient
+	var err error
+
+	switch profile.Type {
 	case "openai":
-		return newOpenAIClient()
+		client, err = newOpenAIClient(profile)
+	case "anthropic":
+		client, err = newAnthropicClient(profile)
 	case "gemini":
-		return newGeminiClient()
+		client, err = newGeminiClient(profile)
 	case "openrouter":
-		return newOpenRouterClient()
+		client, err = newOpenRouterClient(profile)
+	default:
+		return nil, fmt.Errorf("invalid provider type %q, must be one of openai, anthropic, gemini or openrouter", profile.Type)
+	}
+
+	if err != nil {
+		return nil, err
 	}
 
-	panic("Invalid provider: " + provider)
+	return newRetryClient(client, config), nil
 }
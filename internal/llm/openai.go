@@ -7,17 +7,23 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
-	"time"
+	"strings"
 
 	"github.com/shopware/shopware-cli/logging"
 )
 
 // Client represents an OpenAI API client
 type Client struct {
-	host   string
-	apiKey string
-	client *http.Client
+	usageCounter
+	host       string
+	path       string
+	query      map[string]string
+	apiKey     string
+	authHeader string
+	headers    map[string]string
+	client     *http.Client
 }
 
 // ChatMessage represents a message in the chat completion request
@@ -28,8 +34,11 @@ type ChatMessage struct {
 
 // ChatCompletionRequest represents the request body for chat completion
 type ChatCompletionRequest struct {
-	Model    string        `json:"model"`
-	Messages []ChatMessage `json:"messages"`
+	Model       string        `json:"model"`
+	Messages    []ChatMessage `json:"messages"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	MaxTokens   int           `json:"max_tokens,omitempty"`
+	Seed        *int          `json:"seed,omitempty"`
 }
 
 // ChatCompletionResponse represents the response from the chat completion endpoint
@@ -50,9 +59,13 @@ type ChatCompletionResponse struct {
 	} `json:"usage"`
 }
 
-// newOpenAIClient creates a new OpenAI client instance
-func newOpenAIClient() (*Client, error) {
-	host := os.Getenv("OPENAI_API_HOST")
+// newOpenAIClient creates a new client for an OpenAI compatible API. Without a base URL in the
+// profile the host is read from OPENAI_API_HOST or OLLAMA_HOST.
+func newOpenAIClient(profile ProviderProfile) (*Client, error) {
+	host := profile.BaseURL
+	if host == "" {
+		host = os.Getenv("OPENAI_API_HOST")
+	}
 	ollamaHost := os.Getenv("OLLAMA_HOST")
 	if host == "" {
 		if ollamaHost != "" {
@@ -62,17 +75,50 @@ func newOpenAIClient() (*Client, error) {
 		}
 	}
 
-	apiKey := os.Getenv("OPENAI_API_KEY")
+	host = strings.TrimSuffix(host, "/")
+
+	path := profile.Path
+	if path == "" {
+		path = "/v1/chat/completions"
+
+		// Base URLs are often configured including the version
+		if strings.HasSuffix(host, "/v1") {
+			path = "/chat/completions"
+		}
+	}
+
+	authHeader := profile.AuthHeader
+	if authHeader == "" {
+		authHeader = "Authorization"
+	}
 
 	return &Client{
-		host:   host,
-		apiKey: apiKey,
-		client: &http.Client{
-			Timeout: 120 * time.Second,
-		},
+		host:       host,
+		path:       "/" + strings.TrimPrefix(path, "/"),
+		query:      profile.Query,
+		apiKey:     profile.apiKey(),
+		authHeader: authHeader,
+		headers:    profile.Headers,
+		client:     &http.Client{},
 	}, nil
 }
 
+// endpoint returns the URL of the chat completions endpoint for the model.
+func (c *Client) endpoint(model string) string {
+	endpoint := c.host + strings.ReplaceAll(c.path, "{model}", url.PathEscape(model))
+
+	if len(c.query) == 0 {
+		return endpoint
+	}
+
+	query := url.Values{}
+	for key, value := range c.query {
+		query.Set(key, value)
+	}
+
+	return endpoint + "?" + query.Encode()
+}
+
 // Generate sends a chat completion request to the OpenAI API
 func (c *Client) Generate(ctx context.Context, prompt string, options *LLMOptions) (string, error) {
 	messages := []ChatMessage{
@@ -91,8 +137,11 @@ func (c *Client) Generate(ctx context.Context, prompt string, options *LLMOption
 	}
 
 	reqBody := ChatCompletionRequest{
-		Model:    options.Model,
-		Messages: messages,
+		Model:       options.Model,
+		Messages:    messages,
+		Temperature: options.Temperature,
+		MaxTokens:   options.MaxTokens,
+		Seed:        options.Seed,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -100,14 +149,21 @@ func (c *Client) Generate(ctx context.Context, prompt string, options *LLMOption
 		return "", fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/v1/chat/completions", c.host), bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint(options.Model), bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	if c.apiKey != "" {
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+		if strings.EqualFold(c.authHeader, "Authorization") {
+			req.Header.Set(c.authHeader, fmt.Sprintf("Bearer %s", c.apiKey))
+		} else {
+			req.Header.Set(c.authHeader, c.apiKey)
+		}
+	}
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
 	}
 
 	resp, err := c.client.Do(req)
@@ -125,7 +181,7 @@ func (c *Client) Generate(ctx context.Context, prompt string, options *LLMOption
 		if err != nil {
 			return "", fmt.Errorf("failed to read response body: %w", err)
 		}
-		return "", fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+		return "", newStatusError(resp, body)
 	}
 
 	var response ChatCompletionResponse
@@ -137,5 +193,7 @@ func (c *Client) Generate(ctx context.Context, prompt string, options *LLMOption
 		return "", fmt.Errorf("no completion choices returned")
 	}
 
+	c.record(ctx, Usage{PromptTokens: response.Usage.PromptTokens, CompletionTokens: response.Usage.CompletionTokens})
+
 	return response.Choices[0].Message.Content, nil
 }
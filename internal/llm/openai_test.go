@@ -0,0 +1,72 @@
+package llm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAIClientEndpoint(t *testing.T) {
+	cases := []struct {
+		description string
+		profile     ProviderProfile
+		path        string
+		query       string
+		header      string
+		value       string
+	}{
+		{
+			description: "default OpenAI endpoint",
+			profile:     ProviderProfile{Type: "openai"},
+			path:        "/v1/chat/completions",
+			header:      "Authorization",
+			value:       "Bearer secret",
+		},
+		{
+			description: "base URL including the version",
+			profile:     ProviderProfile{Type: "openai", BaseURL: "/v1/"},
+			path:        "/v1/chat/completions",
+			header:      "Authorization",
+			value:       "Bearer secret",
+		},
+		{
+			description: "Azure OpenAI deployment",
+			profile: ProviderProfile{
+				Type:       "openai",
+				Path:       "/openai/deployments/{model}/chat/completions",
+				Query:      map[string]string{"api-version": "2024-10-21"},
+				AuthHeader: "api-key",
+			},
+			path:   "/openai/deployments/test-model/chat/completions",
+			query:  "api-version=2024-10-21",
+			header: "api-key",
+			value:  "secret",
+		},
+	}
+
+	t.Setenv("TEST_OPENAI_KEY", "secret")
+
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, c.path, r.URL.Path, c.description)
+			assert.Equal(t, c.query, r.URL.RawQuery, c.description)
+			assert.Equal(t, c.value, r.Header.Get(c.header), c.description)
+
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"done"}}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`))
+		}))
+
+		c.profile.BaseURL = server.URL + c.profile.BaseURL
+		c.profile.APIKeyEnv = "TEST_OPENAI_KEY"
+
+		client, err := NewClient(c.profile, testClientConfig())
+		assert.NoError(t, err, c.description)
+
+		response, err := client.Generate(t.Context(), "prompt", &LLMOptions{Model: "test-model"})
+		assert.NoError(t, err, c.description)
+		assert.Equal(t, "done", response, c.description)
+
+		server.Close()
+	}
+}
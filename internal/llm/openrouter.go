@@ -8,19 +8,29 @@ import (
 	"io"
 	"net/http"
 	"os"
-	"time"
+	"strings"
 )
 
 // OpenRouterClient represents an OpenRouter API client
 type OpenRouterClient struct {
-	client *http.Client
-	apiKey string
+	usageCounter
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	headers map[string]string
 }
 
 // OpenRouterRequest represents the request body for text generation
 type OpenRouterRequest struct {
-	Model    string    `json:"model"`
-	Messages []Message `json:"messages"`
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Temperature *float64  `json:"temperature,omitempty"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+	Seed        *int      `json:"seed,omitempty"`
+	// Enables the usage accounting including the cost in the response
+	Usage struct {
+		Include bool `json:"include"`
+	} `json:"usage"`
 }
 
 type Message struct {
@@ -35,20 +45,25 @@ type OpenRouterResponse struct {
 			Content string `json:"content"`
 		} `json:"message"`
 	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int     `json:"prompt_tokens"`
+		CompletionTokens int     `json:"completion_tokens"`
+		Cost             float64 `json:"cost"`
+	} `json:"usage"`
 }
 
 // newOpenRouterClient creates a new OpenRouter client instance
-func newOpenRouterClient() (*OpenRouterClient, error) {
-	apiKey := os.Getenv("OPENROUTER_API_KEY")
+func newOpenRouterClient(profile ProviderProfile) (*OpenRouterClient, error) {
+	apiKey := profile.apiKey()
 	if apiKey == "" {
-		return nil, fmt.Errorf("OPENROUTER_API_KEY is not set")
+		return nil, fmt.Errorf("%s is not set", profile.APIKeyEnv)
 	}
 
 	return &OpenRouterClient{
-		apiKey: apiKey,
-		client: &http.Client{
-			Timeout: 120 * time.Second,
-		},
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(profile.BaseURL, "/"),
+		headers: profile.Headers,
+		client:  &http.Client{},
 	}, nil
 }
 
@@ -66,16 +81,20 @@ func (c *OpenRouterClient) Generate(ctx context.Context, prompt string, options
 	}
 
 	reqBody := OpenRouterRequest{
-		Model:    options.Model,
-		Messages: messages,
+		Model:       options.Model,
+		Messages:    messages,
+		Temperature: options.Temperature,
+		MaxTokens:   options.MaxTokens,
+		Seed:        options.Seed,
 	}
+	reqBody.Usage.Include = true
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
 		return "", fmt.Errorf("failed to marshal request body: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://openrouter.ai/api/v1/chat/completions", bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v1/chat/completions", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -84,6 +103,9 @@ func (c *OpenRouterClient) Generate(ctx context.Context, prompt string, options
 	req.Header.Set("Authorization", "Bearer "+c.apiKey)
 	req.Header.Set("HTTP-Referer", "https://github.com/shopwareLabs/extension-verifier")
 	req.Header.Set("X-Title", "Shopware Extension Verifier")
+	for key, value := range c.headers {
+		req.Header.Set(key, value)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
@@ -96,15 +118,15 @@ func (c *OpenRouterClient) Generate(ctx context.Context, prompt string, options
 		}
 	}()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	if resp.StatusCode != http.StatusOK {
+		return "", newStatusError(resp, body)
+	}
+
 	var response OpenRouterResponse
 	if err := json.Unmarshal(body, &response); err != nil {
 		return "", fmt.Errorf("failed to decode response: %w", err)
@@ -115,5 +137,7 @@ func (c *OpenRouterClient) Generate(ctx context.Context, prompt string, options
 		return "", fmt.Errorf("no response choices returned")
 	}
 
+	c.record(ctx, Usage{PromptTokens: response.Usage.PromptTokens, CompletionTokens: response.Usage.CompletionTokens, Cost: response.Usage.Cost})
+
 	return response.Choices[0].Message.Content, nil
 }
@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ProviderProfile describes how to connect to a provider. Additional profiles can be configured
+// in the llm.providers section of the config file, e.g. for self-hosted OpenAI compatible endpoints.
+type ProviderProfile struct {
+	// API of the provider: openai (default, any OpenAI compatible API), anthropic, gemini or openrouter
+	Type    string `yaml:"type"`
+	BaseURL string `yaml:"base_url"`
+	// Name of the environment variable containing the API key
+	APIKeyEnv string `yaml:"api_key_env"`
+	// Model used when no model has been passed
+	Model       string            `yaml:"model"`
+	Headers     map[string]string `yaml:"headers"`
+	Temperature *float64          `yaml:"temperature"`
+	MaxTokens   int               `yaml:"max_tokens"`
+	// Path of the chat completions endpoint of the openai type, defaults to /v1/chat/completions.
+	// {model} is replaced with the model, e.g. /openai/deployments/{model}/chat/completions for Azure OpenAI.
+	Path string `yaml:"path"`
+	// Query parameters added to every request, e.g. the api-version of Azure OpenAI
+	Query map[string]string `yaml:"query"`
+	// Header sending the API key of the openai type, defaults to Authorization with a Bearer token.
+	// Other headers like api-key of Azure OpenAI get the plain key.
+	AuthHeader string `yaml:"auth_header"`
+}
+
+var builtinProfiles = map[string]ProviderProfile{
+	"ollama":     {Type: "openai", APIKeyEnv: "OPENAI_API_KEY", Model: "gemma3:4b"},
+	"openai":     {Type: "openai", APIKeyEnv: "OPENAI_API_KEY", Model: "gpt-4o-mini"},
+	"anthropic":  {Type: "anthropic", BaseURL: "https://api.anthropic.com", APIKeyEnv: "ANTHROPIC_API_KEY", Model: "claude-sonnet-4-20250514", MaxTokens: 8192},
+	"gemini":     {Type: "gemini", APIKeyEnv: "GEMINI_API_KEY", Model: "gemini-2.0-flash"},
+	"openrouter": {Type: "openrouter", BaseURL: "https://openrouter.ai/api", APIKeyEnv: "OPENROUTER_API_KEY"},
+}
+
+// ResolveProfile returns the configured profile with the given name or the built-in provider.
+// Configured profiles take precedence over built-in providers with the same name.
+func ResolveProfile(name string, profiles map[string]ProviderProfile) (ProviderProfile, error) {
+	if profile, ok := profiles[name]; ok {
+		if profile.Type == "" {
+			profile.Type = "openai"
+		}
+
+		// Fill in what the profile doesn't configure from the built-in provider of the same type
+		if builtin, ok := builtinProfiles[profile.Type]; ok {
+			if profile.BaseURL == "" {
+				profile.BaseURL = builtin.BaseURL
+			}
+
+			if profile.APIKeyEnv == "" {
+				profile.APIKeyEnv = builtin.APIKeyEnv
+			}
+		}
+
+		return profile, nil
+	}
+
+	if profile, ok := builtinProfiles[name]; ok {
+		return profile, nil
+	}
+
+	names := make([]string, 0, len(builtinProfiles)+len(profiles))
+	for profileName := range builtinProfiles {
+		names = append(names, profileName)
+	}
+	for profileName := range profiles {
+		names = append(names, profileName)
+	}
+	sort.Strings(names)
+
+	return ProviderProfile{}, fmt.Errorf("invalid provider %q, must be one of %s", name, strings.Join(names, ", "))
+}
+
+// ApplyDefaults sets the model, temperature and max tokens of the profile when options doesn't set them.
+func (p ProviderProfile) ApplyDefaults(options *LLMOptions) {
+	if options.Model == "" {
+		options.Model = p.Model
+	}
+
+	if options.Temperature == nil {
+		options.Temperature = p.Temperature
+	}
+
+	if options.MaxTokens == 0 {
+		options.MaxTokens = p.MaxTokens
+	}
+}
+
+func (p ProviderProfile) apiKey() string {
+	if p.APIKeyEnv == "" {
+		return ""
+	}
+
+	return os.Getenv(p.APIKeyEnv)
+}
@@ -0,0 +1,160 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/log"
+)
+
+// ClientConfig controls how requests of a client are retried, limited and timed out.
+type ClientConfig struct {
+	// Retries of a failed request, 0 disables retrying
+	MaxRetries int
+	// Wait time before the first retry, doubled on every further retry
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout of a single request, 0 disables the timeout
+	Timeout time.Duration
+	// Maximum of requests running in parallel, 0 means unlimited
+	Concurrency int
+}
+
+func DefaultClientConfig() ClientConfig {
+	return ClientConfig{
+		MaxRetries:     3,
+		InitialBackoff: 2 * time.Second,
+		MaxBackoff:     time.Minute,
+		Timeout:        120 * time.Second,
+		Concurrency:    4,
+	}
+}
+
+// StatusError is returned by the clients when the provider answers with an unexpected status code.
+type StatusError struct {
+	StatusCode int
+	Body       string
+	// Wait time requested by the provider with the Retry-After header
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("unexpected status code: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// Retryable reports whether the request can succeed when sent again.
+func (e *StatusError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+func newStatusError(resp *http.Response, body []byte) *StatusError {
+	err := &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+
+	if seconds, parseErr := strconv.Atoi(resp.Header.Get("Retry-After")); parseErr == nil {
+		err.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return err
+}
+
+// retryClient retries failed requests of client with an exponential backoff and limits the number of parallel requests.
+type retryClient struct {
+	client    LLMClient
+	config    ClientConfig
+	semaphore chan struct{}
+}
+
+func newRetryClient(client LLMClient, config ClientConfig) *retryClient {
+	r := &retryClient{client: client, config: config}
+
+	if config.Concurrency > 0 {
+		r.semaphore = make(chan struct{}, config.Concurrency)
+	}
+
+	return r
+}
+
+func (r *retryClient) Generate(ctx context.Context, prompt string, options *LLMOptions) (string, error) {
+	if r.semaphore != nil {
+		select {
+		case r.semaphore <- struct{}{}:
+			defer func() { <-r.semaphore }()
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	backoff := r.config.InitialBackoff
+
+	for attempt := 0; ; attempt++ {
+		response, err := r.generate(ctx, prompt, options)
+
+		if err == nil {
+			return response, nil
+		}
+
+		if attempt >= r.config.MaxRetries || ctx.Err() != nil || !isRetryable(err) {
+			return "", err
+		}
+
+		wait := backoff
+
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > wait {
+			wait = statusErr.RetryAfter
+		}
+
+		log.Warn("LLM request failed, retrying", "error", err, "attempt", attempt+1, "wait", wait)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+
+		backoff *= 2
+
+		if r.config.MaxBackoff > 0 && backoff > r.config.MaxBackoff {
+			backoff = r.config.MaxBackoff
+		}
+	}
+}
+
+func (r *retryClient) generate(ctx context.Context, prompt string, options *LLMOptions) (string, error) {
+	if r.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.config.Timeout)
+		defer cancel()
+	}
+
+	return r.client.Generate(ctx, prompt, options)
+}
+
+func (r *retryClient) Usage() Usage {
+	return r.client.Usage()
+}
+
+func isRetryable(err error) bool {
+	var statusErr *StatusError
+
+	if errors.As(err, &statusErr) {
+		return statusErr.Retryable()
+	}
+
+	// The request exceeded the timeout of a single attempt
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+
+	return errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,176 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testClientConfig() ClientConfig {
+	return ClientConfig{
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		Timeout:        time.Second,
+		Concurrency:    2,
+	}
+}
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	t.Setenv("OPENAI_API_HOST", server.URL)
+	t.Setenv("OPENAI_API_KEY", "test")
+}
+
+const completionResponse = `{"choices":[{"index":0,"message":{"role":"assistant","content":"done"}}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`
+
+func TestRetryOnRateLimit(t *testing.T) {
+	var calls atomic.Int32
+
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer test", r.Header.Get("Authorization"))
+
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		_, _ = w.Write([]byte(completionResponse))
+	})
+
+	client, err := NewLLMClient("openai", testClientConfig())
+	assert.NoError(t, err)
+
+	ctx, tracker := TrackUsage(t.Context())
+	response, err := client.Generate(ctx, "prompt", &LLMOptions{Model: "test"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "done", response)
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, Usage{PromptTokens: 10, CompletionTokens: 5}, client.Usage())
+	assert.Equal(t, Usage{PromptTokens: 10, CompletionTokens: 5}, tracker.Usage())
+}
+
+func TestRetryGivesUpAfterMaxRetries(t *testing.T) {
+	var calls atomic.Int32
+
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+
+	client, err := NewLLMClient("openai", testClientConfig())
+	assert.NoError(t, err)
+
+	_, err = client.Generate(t.Context(), "prompt", &LLMOptions{Model: "test"})
+
+	var statusErr *StatusError
+	assert.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+	assert.Equal(t, int32(4), calls.Load())
+}
+
+func TestNoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":"invalid model"}`))
+	})
+
+	client, err := NewLLMClient("openai", testClientConfig())
+	assert.NoError(t, err)
+
+	_, err = client.Generate(t.Context(), "prompt", &LLMOptions{Model: "test"})
+
+	assert.ErrorContains(t, err, "invalid model")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestRetryOnTimeout(t *testing.T) {
+	var calls atomic.Int32
+
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(time.Second):
+			}
+
+			return
+		}
+
+		_, _ = w.Write([]byte(completionResponse))
+	})
+
+	config := testClientConfig()
+	config.Timeout = 50 * time.Millisecond
+
+	client, err := NewLLMClient("openai", config)
+	assert.NoError(t, err)
+
+	response, err := client.Generate(t.Context(), "prompt", &LLMOptions{Model: "test"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "done", response)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestUnknownProvider(t *testing.T) {
+	_, err := NewLLMClient("unknown", DefaultClientConfig())
+
+	assert.ErrorContains(t, err, "invalid provider")
+}
+
+type blockingClient struct {
+	usageCounter
+	mu      sync.Mutex
+	running int
+	max     int
+}
+
+func (b *blockingClient) Generate(ctx context.Context, prompt string, options *LLMOptions) (string, error) {
+	b.mu.Lock()
+	b.running++
+	b.max = max(b.max, b.running)
+	b.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	b.mu.Lock()
+	b.running--
+	b.mu.Unlock()
+
+	return prompt, nil
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	inner := &blockingClient{}
+	client := newRetryClient(inner, testClientConfig())
+
+	var wg sync.WaitGroup
+
+	for range 6 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := client.Generate(t.Context(), "prompt", &LLMOptions{})
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 2, inner.max)
+}
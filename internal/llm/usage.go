@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Usage contains the number of tokens consumed by LLM requests.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	// Cost in USD, only known for providers reporting it
+	Cost float64 `json:"cost,omitempty"`
+}
+
+// Total returns the number of prompt and completion tokens.
+func (u Usage) Total() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Sub returns the usage consumed since other has been taken.
+func (u Usage) Sub(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens - other.PromptTokens,
+		CompletionTokens: u.CompletionTokens - other.CompletionTokens,
+		Cost:             u.Cost - other.Cost,
+	}
+}
+
+// usageCounter sums up the usage of all requests of a client.
+type usageCounter struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+// record adds usage to the counter and to the tracker of ctx.
+func (c *usageCounter) record(ctx context.Context, usage Usage) {
+	c.add(usage)
+
+	if tracker, ok := ctx.Value(usageTrackerKey{}).(*UsageTracker); ok {
+		tracker.add(usage)
+	}
+}
+
+func (c *usageCounter) add(usage Usage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.usage.PromptTokens += usage.PromptTokens
+	c.usage.CompletionTokens += usage.CompletionTokens
+	c.usage.Cost += usage.Cost
+}
+
+// Usage returns the tokens consumed by all requests of the client so far.
+func (c *usageCounter) Usage() Usage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.usage
+}
+
+func (u Usage) String() string {
+	if u.Cost > 0 {
+		return fmt.Sprintf("%d prompt tokens, %d completion tokens, $%.4f", u.PromptTokens, u.CompletionTokens, u.Cost)
+	}
+
+	return fmt.Sprintf("%d prompt tokens, %d completion tokens", u.PromptTokens, u.CompletionTokens)
+}
+
+// UsageTracker counts the usage of all requests made with the context returned by TrackUsage.
+type UsageTracker struct {
+	usageCounter
+}
+
+type usageTrackerKey struct{}
+
+// TrackUsage returns a context which counts the usage of the requests made with it, independent of
+// other requests running in parallel on the same client.
+func TrackUsage(ctx context.Context) (context.Context, *UsageTracker) {
+	tracker := &UsageTracker{}
+
+	return context.WithValue(ctx, usageTrackerKey{}, tracker), tracker
+}
@@ -0,0 +1,138 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// versionRegex matches the versions which can be used as file names of the index.
+var versionRegex = regexp.MustCompile(`^[0-9A-Za-z][0-9A-Za-z._+-]*$`)
+
+// CacheProvider stores fetched sources content-addressed by their digest in Dir/objects
+// and keeps an index from version to digest in Dir/versions. Every version is only fetched once.
+type CacheProvider struct {
+	Dir     string
+	Fetcher Fetcher
+}
+
+func (c CacheProvider) Resolve(ctx context.Context, version string) (string, error) {
+	if err := validateVersion(version); err != nil {
+		return "", err
+	}
+
+	if dir, ok := c.lookup(version); ok {
+		return dir, nil
+	}
+
+	return c.Warm(ctx, version)
+}
+
+// Warm fetches the sources of version into the cache, even when they are already cached.
+func (c CacheProvider) Warm(ctx context.Context, version string) (string, error) {
+	if err := validateVersion(version); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Join(c.Dir, "versions"), 0755); err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Join(c.Dir, "objects"), 0755); err != nil {
+		return "", err
+	}
+
+	tmpDir, err := os.MkdirTemp(c.Dir, ".fetch-*")
+
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if err := os.RemoveAll(tmpDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to remove temporary directory: %v\n", err)
+		}
+	}()
+
+	digest, err := c.Fetcher.Fetch(ctx, version, tmpDir)
+
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch shopware %s: %w", version, err)
+	}
+
+	objectDir := filepath.Join(c.Dir, "objects", digest)
+
+	if _, err := os.Stat(objectDir); os.IsNotExist(err) {
+		if err := os.Rename(tmpDir, objectDir); err != nil {
+			return "", err
+		}
+	}
+
+	// Write the index entry atomically, parallel runs may warm the same version
+	if err := writeIndex(filepath.Join(c.Dir, "versions"), version, digest); err != nil {
+		return "", err
+	}
+
+	return objectDir, nil
+}
+
+func writeIndex(dir, version, digest string) error {
+	tmpFile, err := os.CreateTemp(dir, "."+version+"-*")
+
+	if err != nil {
+		return err
+	}
+
+	// CreateTemp creates the file only readable for the owner, the index was always world-readable
+	if err := tmpFile.Chmod(0644); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		return err
+	}
+
+	if _, err := tmpFile.WriteString(digest); err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		return err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return err
+	}
+
+	if err := os.Rename(tmpFile.Name(), filepath.Join(dir, version)); err != nil {
+		_ = os.Remove(tmpFile.Name())
+		return err
+	}
+
+	return nil
+}
+
+// validateVersion rejects versions which would escape the index directory when used as file name.
+func validateVersion(version string) error {
+	if !versionRegex.MatchString(version) {
+		return fmt.Errorf("invalid shopware version %q", version)
+	}
+
+	return nil
+}
+
+func (c CacheProvider) lookup(version string) (string, bool) {
+	digest, err := os.ReadFile(filepath.Join(c.Dir, "versions", version))
+
+	if err != nil {
+		return "", false
+	}
+
+	objectDir := filepath.Join(c.Dir, "objects", strings.TrimSpace(string(digest)))
+
+	if _, err := os.Stat(objectDir); err != nil {
+		return "", false
+	}
+
+	return objectDir, true
+}
@@ -0,0 +1,241 @@
+package source
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GitFetcher clones the tag of a version from github.com/shopware/storefront.
+type GitFetcher struct {
+	// Repository to clone, defaults to https://github.com/shopware/storefront
+	Repository string
+}
+
+func (g GitFetcher) Fetch(ctx context.Context, version string, target string) (string, error) {
+	repository := g.Repository
+
+	if repository == "" {
+		repository = "https://github.com/shopware/storefront"
+	}
+
+	git := exec.CommandContext(ctx, "git", "-c", "advice.detachedHead=false", "clone", "-q", "--branch", "v"+version, repository, target, "--depth", "1")
+
+	if output, err := git.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git clone failed: %w: %s", err, string(output))
+	}
+
+	revParse := exec.CommandContext(ctx, "git", "-C", target, "rev-parse", "HEAD")
+	commit, err := revParse.Output()
+
+	if err != nil {
+		return "", fmt.Errorf("failed to determine cloned commit: %w", err)
+	}
+
+	if err := os.RemoveAll(filepath.Join(target, ".git")); err != nil {
+		return "", err
+	}
+
+	return "git-" + strings.TrimSpace(string(commit)), nil
+}
+
+// ArchiveFetcher extracts a local ZIP or tarball. A single top-level folder is stripped.
+type ArchiveFetcher struct {
+	Path string
+}
+
+func (a ArchiveFetcher) Fetch(ctx context.Context, version string, target string) (string, error) {
+	file := expandVersion(a.Path, version)
+
+	digest, err := fileDigest(file)
+
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case strings.HasSuffix(file, ".zip"):
+		err = extractZip(file, target)
+	case strings.HasSuffix(file, ".tar.gz"), strings.HasSuffix(file, ".tgz"), strings.HasSuffix(file, ".tar"):
+		err = extractTar(file, target)
+	default:
+		err = fmt.Errorf("unsupported archive %s, must be .zip, .tar, .tar.gz or .tgz", file)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return "sha256-" + digest, stripSingleFolder(target)
+}
+
+func fileDigest(file string) (string, error) {
+	f, err := os.Open(file)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close archive: %v\n", err)
+		}
+	}()
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func extractZip(file, target string) error {
+	archive, err := zip.OpenReader(file)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := archive.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close archive: %v\n", err)
+		}
+	}()
+
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		reader, err := entry.Open()
+
+		if err != nil {
+			return err
+		}
+
+		err = writeArchiveFile(target, entry.Name, reader)
+
+		if closeErr := reader.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func extractTar(file, target string) error {
+	f, err := os.Open(file)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := f.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to close archive: %v\n", err)
+		}
+	}()
+
+	var reader io.Reader = f
+
+	if !strings.HasSuffix(file, ".tar") {
+		gz, err := gzip.NewReader(f)
+
+		if err != nil {
+			return err
+		}
+
+		reader = gz
+	}
+
+	tr := tar.NewReader(reader)
+
+	for {
+		header, err := tr.Next()
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if err := writeArchiveFile(target, header.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func writeArchiveFile(target, name string, content io.Reader) error {
+	destination := filepath.Join(target, filepath.FromSlash(name))
+
+	if !strings.HasPrefix(destination, filepath.Clean(target)+string(os.PathSeparator)) {
+		return fmt.Errorf("archive entry %s points outside of the target directory", name)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.Create(destination)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, content); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// stripSingleFolder moves the content of a single top-level folder like storefront-6.6.0.0/ into dir.
+func stripSingleFolder(dir string) error {
+	entries, err := os.ReadDir(dir)
+
+	if err != nil {
+		return err
+	}
+
+	// The storefront bundle itself starts with Resources or src
+	if len(entries) != 1 || !entries[0].IsDir() || entries[0].Name() == "Resources" || entries[0].Name() == "src" {
+		return nil
+	}
+
+	folder := filepath.Join(dir, entries[0].Name())
+	children, err := os.ReadDir(folder)
+
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children {
+		if err := os.Rename(filepath.Join(folder, child.Name()), filepath.Join(dir, child.Name())); err != nil {
+			return err
+		}
+	}
+
+	return os.Remove(folder)
+}
@@ -0,0 +1,154 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Provider resolves the Shopware storefront sources of a version to a local directory.
+type Provider interface {
+	Resolve(ctx context.Context, version string) (string, error)
+}
+
+// Fetcher downloads the storefront sources of a version into target and
+// returns a digest identifying the fetched content.
+type Fetcher interface {
+	Fetch(ctx context.Context, version string, target string) (string, error)
+}
+
+// NewProvider creates a provider from a comma separated list of sources, which are tried in order:
+//   - "path:<dir>" uses a local checkout
+//   - "archive:<file>" extracts a local tarball or ZIP into the cache
+//   - "git" clones github.com/shopware/storefront into the cache
+//
+// Git is always used as the last fallback. Paths can contain {version} which gets replaced with the requested version.
+func NewProvider(spec string, cacheDir string) (ChainProvider, error) {
+	var chain ChainProvider
+	hasGit := false
+
+	for _, part := range strings.Split(spec, ",") {
+		kind, value, _ := strings.Cut(strings.TrimSpace(part), ":")
+
+		switch kind {
+		case "path":
+			chain = append(chain, LocalProvider{Path: value})
+		case "archive":
+			chain = append(chain, CacheProvider{Dir: cacheDir, Fetcher: ArchiveFetcher{Path: value}})
+		case "git":
+			chain = append(chain, CacheProvider{Dir: cacheDir, Fetcher: GitFetcher{}})
+			hasGit = true
+		case "":
+		default:
+			return nil, fmt.Errorf("invalid shopware source %q, must be a comma separated list of path:<dir>, archive:<file> or git", part)
+		}
+	}
+
+	if !hasGit {
+		chain = append(chain, CacheProvider{Dir: cacheDir, Fetcher: GitFetcher{}})
+	}
+
+	return chain, nil
+}
+
+// ChainProvider resolves the sources with the first provider which succeeds.
+type ChainProvider []Provider
+
+func (c ChainProvider) Resolve(ctx context.Context, version string) (string, error) {
+	var errs []error
+
+	for _, provider := range c {
+		dir, err := provider.Resolve(ctx, version)
+
+		if err == nil {
+			return dir, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	return "", errors.Join(errs...)
+}
+
+// Warm fetches the sources of version into the cache with the first cache provider which succeeds.
+func (c ChainProvider) Warm(ctx context.Context, version string) (string, error) {
+	var errs []error
+
+	for _, provider := range c {
+		cache, ok := provider.(CacheProvider)
+
+		if !ok {
+			continue
+		}
+
+		dir, err := cache.Warm(ctx, version)
+
+		if err == nil {
+			return dir, nil
+		}
+
+		errs = append(errs, err)
+	}
+
+	return "", errors.Join(errs...)
+}
+
+// DefaultCacheDir returns the directory used to cache Shopware sources.
+func DefaultCacheDir() string {
+	if dir := os.Getenv("EXTENSION_VERIFIER_CACHE_DIR"); dir != "" {
+		return dir
+	}
+
+	dir, err := os.UserCacheDir()
+
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return path.Join(dir, "extension-verifier", "shopware")
+}
+
+// LocalProvider uses an existing checkout of shopware/storefront or shopware/shopware.
+type LocalProvider struct {
+	Path string
+}
+
+func (l LocalProvider) Resolve(ctx context.Context, version string) (string, error) {
+	dir := expandVersion(l.Path, version)
+
+	if _, err := os.Stat(dir); err != nil {
+		return "", fmt.Errorf("shopware source for version %s not found: %w", version, err)
+	}
+
+	return dir, nil
+}
+
+// TemplatePath returns the file of a storefront template like @Storefront/storefront/base.html.twig
+// inside of the resolved sources.
+func TemplatePath(root, template string) string {
+	if strings.HasPrefix(template, "@") {
+		_, template, _ = strings.Cut(template, "/")
+	}
+
+	return filepath.Join(bundleRoot(root), "Resources", "views", template)
+}
+
+// bundleRoot returns the storefront bundle inside of a shopware/shopware monorepo checkout
+// or root itself for a shopware/storefront checkout.
+func bundleRoot(root string) string {
+	monorepo := filepath.Join(root, "src", "Storefront")
+
+	if _, err := os.Stat(filepath.Join(monorepo, "Resources")); err == nil {
+		return monorepo
+	}
+
+	return root
+}
+
+func expandVersion(pattern, version string) string {
+	return strings.ReplaceAll(pattern, "{version}", version)
+}
@@ -0,0 +1,153 @@
+package source
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingFetcher struct {
+	calls *int
+}
+
+func (f countingFetcher) Fetch(ctx context.Context, version string, target string) (string, error) {
+	*f.calls++
+
+	if err := os.MkdirAll(filepath.Join(target, "Resources", "views"), 0755); err != nil {
+		return "", err
+	}
+
+	return "test-" + version, os.WriteFile(filepath.Join(target, "Resources", "views", "version.txt"), []byte(version), 0644)
+}
+
+func TestLocalProviderExpandsVersion(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "6.6.0.0"), 0755))
+
+	provider := LocalProvider{Path: filepath.Join(dir, "{version}")}
+
+	resolved, err := provider.Resolve(t.Context(), "6.6.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "6.6.0.0"), resolved)
+
+	_, err = provider.Resolve(t.Context(), "6.7.0.0")
+	assert.Error(t, err)
+}
+
+func TestCacheProviderFetchesOnce(t *testing.T) {
+	calls := 0
+	provider := CacheProvider{Dir: t.TempDir(), Fetcher: countingFetcher{calls: &calls}}
+
+	first, err := provider.Resolve(t.Context(), "6.6.0.0")
+	assert.NoError(t, err)
+
+	second, err := provider.Resolve(t.Context(), "6.6.0.0")
+	assert.NoError(t, err)
+
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, calls)
+	assert.FileExists(t, filepath.Join(first, "Resources", "views", "version.txt"))
+
+	_, err = provider.Warm(t.Context(), "6.6.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestArchiveFetcherStripsTopLevelFolder(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "storefront-6.6.0.0.zip")
+
+	file, err := os.Create(archivePath)
+	assert.NoError(t, err)
+
+	writer := zip.NewWriter(file)
+	entry, err := writer.Create("storefront-6.6.0.0/Resources/views/storefront/base.html.twig")
+	assert.NoError(t, err)
+	_, err = entry.Write([]byte("{% block base %}{% endblock %}"))
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, file.Close())
+
+	provider, err := NewProvider("archive:"+filepath.Join(dir, "storefront-{version}.zip"), filepath.Join(dir, "cache"))
+	assert.NoError(t, err)
+
+	resolved, err := provider.Resolve(t.Context(), "6.6.0.0")
+	assert.NoError(t, err)
+	assert.FileExists(t, TemplatePath(resolved, "@Storefront/storefront/base.html.twig"))
+}
+
+func TestArchiveFetcherRejectsPathTraversal(t *testing.T) {
+	dir := t.TempDir()
+	archivePath := filepath.Join(dir, "evil.zip")
+
+	file, err := os.Create(archivePath)
+	assert.NoError(t, err)
+
+	writer := zip.NewWriter(file)
+	_, err = writer.Create("../evil.txt")
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	assert.NoError(t, file.Close())
+
+	_, err = ArchiveFetcher{Path: archivePath}.Fetch(t.Context(), "6.6.0.0", filepath.Join(dir, "target"))
+	assert.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "evil.txt"))
+}
+
+func TestTemplatePathMonorepo(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "src", "Storefront", "Resources"), 0755))
+
+	assert.Equal(t, filepath.Join(dir, "src", "Storefront", "Resources", "views", "storefront", "base.html.twig"), TemplatePath(dir, "@Storefront/storefront/base.html.twig"))
+}
+
+func TestNewProviderInvalid(t *testing.T) {
+	_, err := NewProvider("ftp:foo", t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestNewProviderFallsBackToGit(t *testing.T) {
+	provider, err := NewProvider("path:/foo,archive:/bar.zip", t.TempDir())
+	assert.NoError(t, err)
+
+	assert.Len(t, provider, 3)
+	assert.Equal(t, LocalProvider{Path: "/foo"}, provider[0])
+	assert.IsType(t, ArchiveFetcher{}, provider[1].(CacheProvider).Fetcher)
+	assert.IsType(t, GitFetcher{}, provider[2].(CacheProvider).Fetcher)
+}
+
+func TestChainProviderUsesFirstWorkingProvider(t *testing.T) {
+	calls := 0
+	cacheDir := t.TempDir()
+	provider := ChainProvider{
+		LocalProvider{Path: filepath.Join(t.TempDir(), "{version}")},
+		CacheProvider{Dir: cacheDir, Fetcher: countingFetcher{calls: &calls}},
+	}
+
+	resolved, err := provider.Resolve(t.Context(), "6.6.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(cacheDir, "objects", "test-6.6.0.0"), resolved)
+
+	warmed, err := provider.Warm(t.Context(), "6.6.0.0")
+	assert.NoError(t, err)
+	assert.Equal(t, resolved, warmed)
+	assert.Equal(t, 2, calls)
+}
+
+func TestCacheProviderRejectsInvalidVersions(t *testing.T) {
+	calls := 0
+	dir := t.TempDir()
+	provider := CacheProvider{Dir: filepath.Join(dir, "cache"), Fetcher: countingFetcher{calls: &calls}}
+
+	for _, version := range []string{"", "..", "../6.6.0.0", "6.6/0.0"} {
+		_, err := provider.Resolve(t.Context(), version)
+		assert.Error(t, err, version)
+	}
+
+	assert.Equal(t, 0, calls)
+	assert.NoDirExists(t, filepath.Join(dir, "cache"))
+}
@@ -0,0 +1,344 @@
+package tool
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	vueTemplateStartRegex = regexp.MustCompile(`(?m)^<template(\s[^>]*)?>`)
+	vueTemplateEndRegex   = regexp.MustCompile(`(?m)^</template>`)
+	componentCallRegex    = regexp.MustCompile(`\b(?:Shopware\.)?Component\.(?:register|extend|override)\s*\(`)
+	identifierRegex       = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*`)
+)
+
+// adminTemplate is the markup of an admin component inside a file.
+type adminTemplate struct {
+	// Byte range of the markup in the file
+	start int
+	end   int
+	// Quote of the string literal around inline templates, zero for markup
+	quote byte
+}
+
+// line returns the line of the file the template starts in.
+func (t adminTemplate) line(content string) int {
+	return strings.Count(content[:t.start], "\n") + 1
+}
+
+// column returns the column of the file the first line of the template starts in.
+func (t adminTemplate) column(content string) int {
+	return t.start - strings.LastIndex(content[:t.start], "\n")
+}
+
+// replace returns the content with the markup of the template replaced. It fails when the markup
+// cannot be written into the string literal without escaping.
+func (t adminTemplate) replace(content, markup string) (string, bool) {
+	switch t.quote {
+	case '`':
+		if strings.ContainsAny(markup, "`\\") || strings.Contains(markup, "${") {
+			return content, false
+		}
+	case '\'', '"':
+		if strings.ContainsAny(markup, string(t.quote)+"\\\n") {
+			return content, false
+		}
+	}
+
+	return content[:t.start] + markup + content[t.end:], true
+}
+
+// isAdminTemplateFile reports whether the file can contain admin templates.
+func isAdminTemplateFile(file string) bool {
+	switch filepath.Ext(file) {
+	case ".twig", ".html", ".vue", ".js", ".ts":
+		return true
+	}
+	return false
+}
+
+// isAdminMarkupFile reports whether the whole file is a template.
+func isAdminMarkupFile(file string) bool {
+	switch filepath.Ext(file) {
+	case ".twig", ".html":
+		return true
+	}
+	return false
+}
+
+// findAdminTemplates returns the templates of the file: the whole file for .twig and .html files, the <template>
+// section of .vue files and the string templates of Component.register, extend and override calls in scripts.
+func findAdminTemplates(file, content string) []adminTemplate {
+	switch filepath.Ext(file) {
+	case ".twig", ".html":
+		return []adminTemplate{{start: 0, end: len(content)}}
+	case ".vue":
+		return findVueTemplates(content)
+	case ".js", ".ts":
+		return findScriptTemplates(content)
+	}
+	return nil
+}
+
+// findVueTemplates returns the top-level <template> section of a single file component.
+// The section starts and ends at the beginning of a line, nested templates are indented.
+func findVueTemplates(content string) []adminTemplate {
+	start := vueTemplateStartRegex.FindStringIndex(content)
+
+	if start == nil {
+		return nil
+	}
+
+	end := vueTemplateEndRegex.FindStringIndex(content[start[1]:])
+
+	if end == nil {
+		return nil
+	}
+
+	return []adminTemplate{{start: start[1], end: start[1] + end[0]}}
+}
+
+// findScriptTemplates returns the templates of component registrations in a script. The template can be
+// a string in the options or a variable declared with a string in the same file.
+func findScriptTemplates(content string) []adminTemplate {
+	var templates []adminTemplate
+
+	for _, match := range componentCallRegex.FindAllStringIndex(content, -1) {
+		end := skipJSBrackets(content, match[1]-1)
+
+		if end == -1 {
+			continue
+		}
+
+		value := findTemplateProperty(content, match[1], end-1)
+
+		if value == -1 {
+			continue
+		}
+
+		if template, ok := jsStringTemplate(content, value); ok {
+			templates = append(templates, template)
+			continue
+		}
+
+		name := identifierRegex.FindString(content[value:])
+
+		if name == "" {
+			continue
+		}
+
+		declaration := regexp.MustCompile(`\b(?:const|let|var)\s+` + regexp.QuoteMeta(name) + `\s*=\s*`).FindStringIndex(content)
+
+		if declaration == nil {
+			continue
+		}
+
+		if template, ok := jsStringTemplate(content, declaration[1]); ok && !slices.Contains(templates, template) {
+			templates = append(templates, template)
+		}
+	}
+
+	return templates
+}
+
+// findTemplateProperty returns the position of the value of the template property in the options object
+// between start and end, which are the arguments of a call. It returns -1 without template property.
+func findTemplateProperty(content string, start, end int) int {
+	var stack []byte
+	// Whether the next token in the options object is a property name
+	expectKey := false
+
+	for i := start; i < end; {
+		c := content[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`' || strings.HasPrefix(content[i:], "//") || strings.HasPrefix(content[i:], "/*"):
+			i = skipJSToken(content, i)
+			expectKey = false
+			continue
+		case c == '(' || c == '[' || c == '{':
+			stack = append(stack, c)
+			expectKey = len(stack) == 1 && c == '{'
+		case c == ')' || c == ']' || c == '}':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			expectKey = false
+		case c == ',':
+			expectKey = len(stack) == 1 && stack[0] == '{'
+		case expectKey && identifierRegex.MatchString(content[i:i+1]):
+			name := identifierRegex.FindString(content[i:])
+			next := skipJSSpace(content, i+len(name))
+
+			if name != "template" {
+				i = next
+				expectKey = false
+				continue
+			}
+
+			switch {
+			case next < end && content[next] == ':':
+				return skipJSSpace(content, next+1)
+			case next < end && (content[next] == ',' || content[next] == '}'):
+				// Shorthand property referencing a variable named template
+				return i
+			}
+
+			i = next
+			expectKey = false
+			continue
+		}
+
+		i++
+	}
+
+	return -1
+}
+
+// jsStringTemplate returns the content of the string literal at pos as template.
+// Strings with escapes or interpolations are skipped, their content is not the markup.
+func jsStringTemplate(content string, pos int) (adminTemplate, bool) {
+	if pos >= len(content) {
+		return adminTemplate{}, false
+	}
+
+	quote := content[pos]
+
+	if quote != '\'' && quote != '"' && quote != '`' {
+		return adminTemplate{}, false
+	}
+
+	end := skipJSToken(content, pos)
+
+	// An unterminated string runs to the end of the content
+	if end-1 <= pos || content[end-1] != quote {
+		return adminTemplate{}, false
+	}
+
+	literal := content[pos+1 : end-1]
+
+	if strings.Contains(literal, "\\") || (quote == '`' && strings.Contains(literal, "${")) {
+		return adminTemplate{}, false
+	}
+
+	return adminTemplate{start: pos + 1, end: end - 1, quote: quote}, true
+}
+
+// skipJSToken returns the position behind the string, template literal or comment at pos.
+func skipJSToken(content string, pos int) int {
+	switch {
+	case strings.HasPrefix(content[pos:], "//"):
+		if end := strings.IndexByte(content[pos:], '\n'); end != -1 {
+			return pos + end + 1
+		}
+		return len(content)
+	case strings.HasPrefix(content[pos:], "/*"):
+		if end := strings.Index(content[pos+2:], "*/"); end != -1 {
+			return pos + 2 + end + 2
+		}
+		return len(content)
+	}
+
+	quote := content[pos]
+
+	for i := pos + 1; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '$':
+			if quote == '`' && i+1 < len(content) && content[i+1] == '{' {
+				// Skip the interpolation with its nested brackets
+				end := skipJSBrackets(content, i+1)
+				if end == -1 {
+					return len(content)
+				}
+				i = end - 1
+			}
+		}
+	}
+
+	return len(content)
+}
+
+// skipJSBrackets returns the position behind the bracket matching the one at pos or -1 when it is not closed.
+func skipJSBrackets(content string, pos int) int {
+	depth := 0
+
+	for i := pos; i < len(content); {
+		c := content[i]
+
+		switch {
+		case c == '\'' || c == '"' || c == '`' || strings.HasPrefix(content[i:], "//") || strings.HasPrefix(content[i:], "/*"):
+			i = skipJSToken(content, i)
+			continue
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+
+		i++
+	}
+
+	return -1
+}
+
+// skipJSSpace returns the position of the next character which is no whitespace.
+func skipJSSpace(content string, pos int) int {
+	for pos < len(content) && strings.ContainsRune(" \t\r\n", rune(content[pos])) {
+		pos++
+	}
+	return pos
+}
+
+// walkAdminTemplates calls fn for every file with templates in the admin directories.
+// Dependencies and builds are skipped.
+func walkAdminTemplates(config ToolConfig, fn func(path, content string, templates []adminTemplate) error) error {
+	for _, p := range config.AdminDirectories {
+		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if d.IsDir() {
+				switch d.Name() {
+				case "node_modules", "dist", "vendor":
+					return filepath.SkipDir
+				}
+				return nil
+			}
+
+			if !isAdminTemplateFile(path) {
+				return nil
+			}
+
+			file, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+
+			templates := findAdminTemplates(path, string(file))
+
+			if len(templates) == 0 {
+				return nil
+			}
+
+			return fn(path, string(file), templates)
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindAdminTemplates(t *testing.T) {
+	cases := []struct {
+		description string
+		file        string
+		content     string
+		templates   []string
+	}{
+		{
+			description: "whole html file",
+			file:        "index.html",
+			content:     "<sw-button>Save</sw-button>",
+			templates:   []string{"<sw-button>Save</sw-button>"},
+		},
+		{
+			description: "template section of a vue file",
+			file:        "component.vue",
+			content:     "<template>\n    <template v-if=\"a\"><sw-button /></template>\n</template>\n\n<script>\nexport default {};\n</script>\n",
+			templates:   []string{"\n    <template v-if=\"a\"><sw-button /></template>\n"},
+		},
+		{
+			description: "vue file without template",
+			file:        "component.vue",
+			content:     "<script>\nexport default {};\n</script>\n",
+		},
+		{
+			description: "inline template property",
+			file:        "index.js",
+			content:     "Shopware.Component.register('sw-foo', {\n    template: `<sw-button>Save</sw-button>`,\n});",
+			templates:   []string{"<sw-button>Save</sw-button>"},
+		},
+		{
+			description: "template referenced by a constant",
+			file:        "index.ts",
+			content:     "const template = '<sw-button>Save</sw-button>';\n\nComponent.extend('sw-foo', 'sw-bar', {\n    template,\n});\nComponent.override('sw-baz', { template });",
+			templates:   []string{"<sw-button>Save</sw-button>"},
+		},
+		{
+			description: "template property of a nested object is ignored",
+			file:        "index.js",
+			content:     "Component.register('sw-foo', {\n    data() { return { template: '<div></div>' }; },\n});",
+		},
+		{
+			description: "strings with escapes and interpolations are skipped",
+			file:        "index.js",
+			content:     "Component.register('sw-foo', { template: '<div class=\\'a\\'></div>' });\nComponent.register('sw-bar', { template: `<div>${content}</div>` });",
+		},
+		{
+			description: "imported templates are skipped",
+			file:        "index.js",
+			content:     "import template from './sw-foo.html.twig';\n\nComponent.register('sw-foo', { template });",
+		},
+	}
+
+	for _, c := range cases {
+		var found []string
+		for _, template := range findAdminTemplates(c.file, c.content) {
+			found = append(found, c.content[template.start:template.end])
+		}
+
+		assert.Equal(t, c.templates, found, c.description)
+	}
+}
+
+func TestAdminTemplateReplace(t *testing.T) {
+	content := "Component.register('sw-foo', { template: '<sw-button />' });"
+	templates := findAdminTemplates("index.js", content)
+
+	assert.Len(t, templates, 1)
+
+	replaced, ok := templates[0].replace(content, "<mt-button />")
+	assert.True(t, ok)
+	assert.Equal(t, "Component.register('sw-foo', { template: '<mt-button />' });", replaced)
+
+	// Markup which needs escaping is not written into the literal
+	_, ok = templates[0].replace(content, "<mt-button\n    label='Save'\n/>")
+	assert.False(t, ok)
+}
+
+func TestAdminTwigLinterChecksEmbeddedTemplates(t *testing.T) {
+	root := t.TempDir()
+	adminDir := path.Join(root, "src", "Resources", "app", "administration")
+
+	assert.NoError(t, os.MkdirAll(path.Join(adminDir, "node_modules", "foo"), 0755))
+	assert.NoError(t, os.WriteFile(path.Join(adminDir, "component.vue"), []byte("<template>\n    <div>\n        <sw-button>Save</sw-button>\n    </div>\n</template>\n"), 0644))
+	assert.NoError(t, os.WriteFile(path.Join(adminDir, "index.js"), []byte("import './component.vue';\n\nShopware.Component.register('sw-foo', {\n    template: `\n        <sw-button>Save</sw-button>\n    `,\n});\n"), 0644))
+	assert.NoError(t, os.WriteFile(path.Join(adminDir, "index.html"), []byte("<div>\n    <sw-button>Save</sw-button>\n</div>\n"), 0644))
+	assert.NoError(t, os.WriteFile(path.Join(adminDir, "node_modules", "foo", "index.html"), []byte("<sw-button>Save</sw-button>"), 0644))
+
+	check := NewCheck()
+	config := ToolConfig{RootDir: root, AdminDirectories: []string{adminDir}, MinShopwareVersion: "6.7.0.0"}
+	assert.NoError(t, AdminTwigLinter{}.Check(context.Background(), check, config))
+
+	found := map[string]int{}
+	for _, r := range check.Results {
+		assert.Equal(t, "admintwiglinter/sw-button", r.Identifier)
+		found[path.Base(r.Path)] = r.Line
+	}
+
+	// Lines point into the file, not into the template
+	assert.Equal(t, map[string]int{"component.vue": 3, "index.js": 5, "index.html": 2}, found)
+
+	assert.NoError(t, AdminTwigLinter{}.Fix(context.Background(), config))
+
+	vue, err := os.ReadFile(path.Join(adminDir, "component.vue"))
+	assert.NoError(t, err)
+	assert.Equal(t, "<template>\n    <div>\n        <mt-button>Save</mt-button>\n    </div>\n</template>\n", string(vue))
+
+	script, err := os.ReadFile(path.Join(adminDir, "index.js"))
+	assert.NoError(t, err)
+	assert.Equal(t, "import './component.vue';\n\nShopware.Component.register('sw-foo', {\n    template: `\n        <mt-button>Save</mt-button>\n    `,\n});\n", string(script))
+
+	dependency, err := os.ReadFile(path.Join(adminDir, "node_modules", "foo", "index.html"))
+	assert.NoError(t, err)
+	assert.Equal(t, "<sw-button>Save</sw-button>", string(dependency))
+}
+
+func TestAdminTwigLinterReportsParseErrorsInScripts(t *testing.T) {
+	root := t.TempDir()
+	adminDir := path.Join(root, "src", "Resources", "app", "administration")
+
+	assert.NoError(t, os.MkdirAll(adminDir, 0755))
+	assert.NoError(t, os.WriteFile(path.Join(adminDir, "index.js"), []byte("\nComponent.register('sw-foo', { template: '{% endblock %}' });"), 0644))
+
+	check := NewCheck()
+	config := ToolConfig{RootDir: root, AdminDirectories: []string{adminDir}, MinShopwareVersion: "6.7.0.0"}
+	assert.NoError(t, AdminTwigLinter{}.Check(context.Background(), check, config))
+
+	assert.Len(t, check.Results, 1)
+	assert.Equal(t, "admintwiglinter/parse-error", check.Results[0].Identifier)
+	assert.Equal(t, 2, check.Results[0].Line)
+	assert.Contains(t, check.Results[0].Message, "at column 43:")
+}
+
+func TestAdminTwigLinterFixFailsForTemplatesNeedingEscaping(t *testing.T) {
+	root := t.TempDir()
+	adminDir := path.Join(root, "src", "Resources", "app", "administration")
+	script := "Component.register('sw-foo', { template: '<sw-button router-link=\"sw.foo.index\">Go</sw-button>' });\n"
+
+	assert.NoError(t, os.MkdirAll(adminDir, 0755))
+	assert.NoError(t, os.WriteFile(path.Join(adminDir, "index.js"), []byte(script), 0644))
+	assert.NoError(t, os.WriteFile(path.Join(adminDir, "index.html"), []byte("<sw-button>Save</sw-button>"), 0644))
+
+	config := ToolConfig{RootDir: root, AdminDirectories: []string{adminDir}, MinShopwareVersion: "6.7.0.0"}
+
+	// The fixed button navigates with this.$router.push('sw.foo.index'), the quote would end the literal
+	err := AdminTwigLinter{}.Fix(context.Background(), config)
+	assert.ErrorContains(t, err, path.Join(adminDir, "index.js"))
+
+	content, err := os.ReadFile(path.Join(adminDir, "index.js"))
+	assert.NoError(t, err)
+	assert.Equal(t, script, string(content))
+
+	// Other templates are still fixed
+	markup, err := os.ReadFile(path.Join(adminDir, "index.html"))
+	assert.NoError(t, err)
+	assert.Equal(t, "<mt-button>Save</mt-button>", string(markup))
+}
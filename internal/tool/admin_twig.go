@@ -3,13 +3,10 @@ package tool
 import (
 	"context"
 	"fmt"
-	"io/fs"
 	"os"
-	"path/filepath"
+	"slices"
 	"strings"
 
-	"github.com/sergi/go-diff/diffmatchpatch"
-
 	"github.com/shopware/extension-verifier/internal/admintwiglinter"
 	"github.com/shopware/extension-verifier/internal/html"
 	"github.com/shyim/go-version"
@@ -21,148 +18,200 @@ func (a AdminTwigLinter) Name() string {
 	return "admin-twig"
 }
 
+// Rules returns the rules of the fixers of all Shopware versions.
+func (a AdminTwigLinter) Rules() []RuleDescriptor {
+	var rules []RuleDescriptor
+
+	for _, fixer := range admintwiglinter.AllFixers() {
+		metadata := fixer.Metadata()
+
+		rule := RuleDescriptor{
+			Identifier:  "admintwiglinter/" + metadata.Identifier,
+			Title:       metadata.Title,
+			Description: metadata.Description,
+			Versions:    metadata.Versions,
+			FixSafety:   string(metadata.Safety),
+		}
+
+		for _, example := range metadata.Examples {
+			rule.Examples = append(rule.Examples, RuleExample{Before: example.Before, After: example.After})
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
 func (a AdminTwigLinter) Check(ctx context.Context, check *Check, config ToolConfig) error {
 	fixers := admintwiglinter.GetFixers(version.Must(version.NewVersion(config.MinShopwareVersion)))
 
-	for _, p := range config.AdminDirectories {
-		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
+	return walkAdminTemplates(config, func(path, content string, templates []adminTemplate) error {
+		relativePath := strings.TrimPrefix(strings.TrimPrefix(path, "/private"), config.RootDir+"/")
 
-			if d.IsDir() {
-				return nil
-			}
-
-			if filepath.Ext(path) != ".twig" {
-				return nil
-			}
+		for _, template := range templates {
+			// Lines of the template are relative to its start in the file
+			lineOffset := template.line(content) - 1
 
-			file, err := os.ReadFile(path)
-			if err != nil {
-				return err
-			}
+			// The fixers still check the parts of a malformed template which could be parsed
+			parsed, diagnostics := html.Parse(content[template.start:template.end])
 
-			parsed, err := html.NewParser(string(file))
+			for _, diagnostic := range diagnostics {
+				column := diagnostic.Column
+				if diagnostic.Line == 1 {
+					column += template.column(content) - 1
+				}
 
-			if err != nil {
-				return fmt.Errorf("failed to parse %s: %w", path, err)
+				check.AddResult(CheckResult{
+					Message:    fmt.Sprintf("Cannot parse template at column %d: %s", column, diagnostic.Message),
+					Path:       relativePath,
+					Line:       diagnostic.Line + lineOffset,
+					Severity:   "error",
+					Identifier: "admintwiglinter/parse-error",
+				})
 			}
 
 			for _, fixer := range fixers {
 				for _, message := range fixer.Check(parsed) {
+					line := message.Line
+					if line > 0 {
+						line += lineOffset
+					}
+
 					check.AddResult(CheckResult{
 						Message:    message.Message,
-						Path:       strings.TrimPrefix(strings.TrimPrefix(path, "/private"), config.RootDir+"/"),
-						Line:       0,
+						Path:       relativePath,
+						Line:       line,
 						Severity:   message.Severity,
 						Identifier: fmt.Sprintf("admintwiglinter/%s", message.Identifier),
 					})
 				}
 			}
-
-			return nil
-		})
-
-		if err != nil {
-			return err
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
 func (a AdminTwigLinter) Fix(ctx context.Context, config ToolConfig) error {
 	fixers := admintwiglinter.GetFixers(version.Must(version.NewVersion(config.MinShopwareVersion)))
 
-	for _, p := range config.AdminDirectories {
-		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
+	// Files with templates which cannot be written back, the other templates are still fixed
+	var unfixable []string
 
-			if d.IsDir() {
-				return nil
-			}
+	err := walkAdminTemplates(config, func(path, content string, templates []adminTemplate) error {
+		options, err := adminFormatOptions(config, path)
 
-			if filepath.Ext(path) != ".twig" {
-				return nil
-			}
+		if err != nil {
+			return err
+		}
 
-			file, err := os.ReadFile(path)
-			if err != nil {
-				return err
-			}
+		fixed := content
+
+		// Later templates are replaced first, so the positions of the earlier ones stay valid
+		for i := len(templates) - 1; i >= 0; i-- {
+			template := templates[i]
 
-			parsed, err := html.NewParser(string(file))
+			// Fixes are written as minimal edits, formatting is left to Format
+			doc, err := html.ParseDocument(content[template.start:template.end])
 
+			// Malformed templates are reported by Check, they are not touched
 			if err != nil {
-				return err
+				continue
 			}
 
+			doc.Options = options
+
 			for _, fixer := range fixers {
-				if err := fixer.Fix(parsed); err != nil {
+				if err := fixer.Fix(doc.Nodes); err != nil {
 					return err
 				}
 			}
 
-			return os.WriteFile(path, []byte(parsed.Dump(0)), os.ModePerm)
-		})
+			markup, err := doc.Dump()
 
-		if err != nil {
-			return err
+			if err != nil {
+				return fmt.Errorf("failed to fix %s: %w", path, err)
+			}
+
+			if replaced, ok := template.replace(fixed, markup); ok {
+				fixed = replaced
+			} else if !slices.Contains(unfixable, path) {
+				unfixable = append(unfixable, path)
+			}
+		}
+
+		if fixed == content {
+			return nil
 		}
+
+		return os.WriteFile(path, []byte(fixed), os.ModePerm)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if len(unfixable) > 0 {
+		return fmt.Errorf("cannot write the fixed templates back into the string literals of %s, they need escaping and have to be fixed manually", strings.Join(unfixable, ", "))
 	}
 
 	return nil
 }
 
-func (a AdminTwigLinter) Format(ctx context.Context, config ToolConfig, dryRun bool) error {
-	dmp := diffmatchpatch.New()
+func (a AdminTwigLinter) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
+	return walkAdminTemplates(config, func(path, content string, templates []adminTemplate) error {
+		// Templates embedded in components keep the formatting of their file
+		if !isAdminMarkupFile(path) {
+			return nil
+		}
 
-	for _, p := range config.AdminDirectories {
-		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
-			if err != nil {
-				return err
-			}
+		parsed, err := html.NewParser(content)
 
-			if d.IsDir() {
-				return nil
-			}
+		// Malformed templates are reported by Check, they are not touched
+		if err != nil {
+			return nil
+		}
 
-			if filepath.Ext(path) != ".twig" {
-				return nil
-			}
+		options, err := adminFormatOptions(config, path)
 
-			file, err := os.ReadFile(path)
-			if err != nil {
-				return err
-			}
+		if err != nil {
+			return err
+		}
 
-			parsed, err := html.NewParser(string(file))
+		formatted := parsed.Dump(0, options)
 
-			if err != nil {
-				return fmt.Errorf("failed to parse %s: %w", path, err)
-			}
+		if dryRun {
+			addFormatDiff(check, config, a.Name(), path, content, formatted)
+			return nil
+		}
+
+		if formatted == content {
+			return nil
+		}
 
-			if dryRun {
-				diffs := dmp.DiffMain(string(file), parsed.Dump(0), false)
+		return os.WriteFile(path, []byte(formatted), os.ModePerm)
+	})
+}
 
-				fmt.Println(dmp.DiffPrettyText(diffs))
+// adminFormatOptions returns the options to format the template file. The .editorconfig
+// of the extension is applied to the defaults and the format config overrides both.
+func adminFormatOptions(config ToolConfig, file string) (html.FormatOptions, error) {
+	options := html.DefaultFormatOptions()
 
-				return nil
-			} else {
-				return os.WriteFile(path, []byte(parsed.Dump(0)), os.ModePerm)
-			}
-		})
+	properties, err := editorConfigProperties(file)
 
-		if err != nil {
-			return err
-		}
+	if err != nil {
+		return options, err
 	}
 
-	return nil
+	applyEditorConfig(&options, properties)
+
+	if err := config.Verifier.Format.apply(&options); err != nil {
+		return options, err
+	}
 
+	return options, nil
 }
 
 func init() {
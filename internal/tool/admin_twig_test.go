@@ -0,0 +1,67 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminTwigLinterReportsParseErrors(t *testing.T) {
+	root := t.TempDir()
+	adminDir := path.Join(root, "src", "Resources", "app", "administration")
+
+	assert.NoError(t, os.MkdirAll(adminDir, 0755))
+	assert.NoError(t, os.WriteFile(path.Join(adminDir, "broken.html.twig"), []byte("{% block a %}\n<sw-button>Save</sw-button>\n{% endblock %}\n{% endblock %}"), 0644))
+	assert.NoError(t, os.WriteFile(path.Join(adminDir, "valid.html.twig"), []byte("<sw-button>Save</sw-button>"), 0644))
+
+	check := NewCheck()
+	config := ToolConfig{RootDir: root, AdminDirectories: []string{adminDir}, MinShopwareVersion: "6.7.0.0"}
+	assert.NoError(t, AdminTwigLinter{}.Check(context.Background(), check, config))
+
+	var found []string
+	for _, r := range check.Results {
+		found = append(found, path.Base(r.Path)+" "+r.Identifier)
+	}
+
+	assert.Equal(t, []string{
+		"broken.html.twig admintwiglinter/parse-error",
+		"broken.html.twig admintwiglinter/sw-button",
+		"valid.html.twig admintwiglinter/sw-button",
+	}, found)
+	assert.Equal(t, 4, check.Results[0].Line)
+	assert.Equal(t, "error", check.Results[0].Severity)
+
+	// Malformed templates are left untouched by the fixers
+	assert.NoError(t, AdminTwigLinter{}.Fix(context.Background(), config))
+
+	content, err := os.ReadFile(path.Join(adminDir, "broken.html.twig"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(content), "<sw-button>")
+}
+
+func TestAdminTwigLinterFormatDryRun(t *testing.T) {
+	root := t.TempDir()
+	adminDir := path.Join(root, "src", "Resources", "app", "administration")
+	file := path.Join(adminDir, "index.html.twig")
+	content := "{% block a %}\n<div><span>Text</span></div>\n{% endblock %}"
+
+	assert.NoError(t, os.MkdirAll(adminDir, 0755))
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0644))
+
+	check := NewCheck()
+	config := ToolConfig{RootDir: root, AdminDirectories: []string{adminDir}}
+	assert.NoError(t, AdminTwigLinter{}.Format(context.Background(), check, config, true))
+
+	assert.Len(t, check.Results, 1)
+	assert.Equal(t, "format/admin-twig", check.Results[0].Identifier)
+	assert.Equal(t, "src/Resources/app/administration/index.html.twig", check.Results[0].Path)
+	assert.Contains(t, check.Results[0].Diff, "+    <div>\n")
+
+	// Nothing is written in dry run
+	written, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(written))
+}
@@ -0,0 +1,318 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+var assetSourceExtensions = []string{".js", ".ts", ".mjs", ".vue"}
+
+type Assets struct{}
+
+func (a Assets) Name() string {
+	return "assets"
+}
+
+func (a Assets) Check(ctx context.Context, check *Check, config ToolConfig) error {
+	for _, adminDir := range config.AdminDirectories {
+		compiledDir := path.Join(path.Dir(path.Dir(adminDir)), "public", "administration")
+
+		if err := a.checkBuild(check, config, path.Join(adminDir, "src"), compiledDir); err != nil {
+			return err
+		}
+	}
+
+	for _, storefrontDir := range config.StorefrontDirectories {
+		if err := a.checkBuild(check, config, path.Join(storefrontDir, "src"), path.Join(storefrontDir, "dist")); err != nil {
+			return err
+		}
+	}
+
+	if config.ArchivePath != "" {
+		return a.checkReleaseLeftovers(check, config)
+	}
+
+	return nil
+}
+
+// checkBuild verifies that the compiled output of sourceDir exists, is not older than the sources
+// and that every entry bundle stays inside the configured size budget.
+func (a Assets) checkBuild(check *Check, config ToolConfig, sourceDir, compiledDir string) error {
+	sourcesModified, hasSources, err := newestFile(sourceDir, func(p string) bool {
+		for _, ext := range assetSourceExtensions {
+			if strings.HasSuffix(p, ext) {
+				return true
+			}
+		}
+
+		return false
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if !hasSources {
+		return nil
+	}
+
+	relativeCompiledDir := strings.TrimPrefix(strings.TrimPrefix(compiledDir, "/private"), config.RootDir+"/")
+
+	compiledModified, hasCompiled, err := newestFile(compiledDir, func(p string) bool {
+		return strings.HasSuffix(p, ".js")
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if !hasCompiled {
+		check.AddResult(CheckResult{
+			Path:       relativeCompiledDir,
+			Message:    fmt.Sprintf("JavaScript sources found in %s, but the compiled files are missing. Build the assets before releasing", strings.TrimPrefix(sourceDir, config.RootDir+"/")),
+			Severity:   "error",
+			Identifier: "assets/missing-build",
+		})
+
+		return nil
+	}
+
+	if sourcesModified.After(compiledModified) {
+		check.AddResult(CheckResult{
+			Path:       relativeCompiledDir,
+			Message:    "The compiled files are older than their sources. Rebuild the assets before releasing",
+			Severity:   "warning",
+			Identifier: "assets/stale-build",
+		})
+	}
+
+	entries, err := entryFiles(compiledDir)
+
+	if err != nil {
+		return err
+	}
+
+	for _, p := range entries {
+		info, err := os.Stat(p)
+
+		if err != nil {
+			return err
+		}
+
+		relativePath := strings.TrimPrefix(strings.TrimPrefix(p, "/private"), config.RootDir+"/")
+		budget := config.Verifier.Assets.budgetFor(relativePath)
+
+		if budget > 0 && ByteSize(info.Size()) > budget {
+			check.AddResult(CheckResult{
+				Path:       relativePath,
+				Message:    fmt.Sprintf("The compiled file has %s and exceeds the size budget of %s", ByteSize(info.Size()), budget),
+				Severity:   "warning",
+				Identifier: "assets/size-budget",
+			})
+		}
+	}
+
+	return nil
+}
+
+// entryFiles returns the compiled entry bundles in compiledDir, lazy loaded chunks are not included.
+// Vite builds list their entries in .vite/manifest.json. In webpack builds the entries are the files
+// directly in the js and css folders of the administration and the files named like their folder
+// in the storefront, like js/my-extension/my-extension.js.
+func entryFiles(compiledDir string) ([]string, error) {
+	manifest, err := os.ReadFile(filepath.Join(compiledDir, ".vite", "manifest.json"))
+
+	if err == nil {
+		var chunks map[string]struct {
+			File    string   `json:"file"`
+			CSS     []string `json:"css"`
+			IsEntry bool     `json:"isEntry"`
+		}
+
+		if err := json.Unmarshal(manifest, &chunks); err != nil {
+			return nil, fmt.Errorf("cannot parse the vite manifest of %s: %w", compiledDir, err)
+		}
+
+		var entries []string
+
+		for _, chunk := range chunks {
+			if !chunk.IsEntry {
+				continue
+			}
+
+			entries = append(entries, filepath.Join(compiledDir, chunk.File))
+
+			for _, css := range chunk.CSS {
+				entries = append(entries, filepath.Join(compiledDir, css))
+			}
+		}
+
+		sort.Strings(entries)
+
+		return entries, nil
+	}
+
+	if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var entries []string
+
+	err = filepath.WalkDir(compiledDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		ext := filepath.Ext(p)
+
+		if d.IsDir() || (ext != ".js" && ext != ".css") {
+			return nil
+		}
+
+		dir := filepath.Dir(p)
+		inAssetFolder := (filepath.Base(dir) == "js" || filepath.Base(dir) == "css") && filepath.Dir(dir) == filepath.Clean(compiledDir)
+
+		if inAssetFolder || strings.TrimSuffix(d.Name(), ext) == filepath.Base(dir) {
+			entries = append(entries, p)
+		}
+
+		return nil
+	})
+
+	return entries, err
+}
+
+// checkReleaseLeftovers flags development files which should never be part of a release ZIP.
+func (a Assets) checkReleaseLeftovers(check *Check, config ToolConfig) error {
+	return filepath.WalkDir(config.RootDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		relativePath := strings.TrimPrefix(strings.TrimPrefix(p, "/private"), config.RootDir+"/")
+
+		if d.IsDir() {
+			if d.Name() == "vendor" {
+				return filepath.SkipDir
+			}
+
+			if d.Name() == "node_modules" {
+				check.AddResult(CheckResult{
+					Path:       relativePath,
+					Message:    "The release contains a node_modules folder. Exclude it from the ZIP",
+					Severity:   "error",
+					Identifier: "assets/node-modules",
+				})
+
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if strings.HasSuffix(p, ".js.map") || strings.HasSuffix(p, ".css.map") {
+			check.AddResult(CheckResult{
+				Path:       relativePath,
+				Message:    "The release contains a source map. Exclude it from the ZIP",
+				Severity:   "warning",
+				Identifier: "assets/source-map",
+			})
+		}
+
+		return nil
+	})
+}
+
+func (a Assets) Fix(ctx context.Context, config ToolConfig) error {
+	return nil
+}
+
+func (a Assets) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
+	return nil
+}
+
+// budgetFor returns the budget of the most specific glob matching the file, which is the one with the
+// most characters besides wildcards. Globs as specific as each other are ordered alphabetically.
+func (c AssetsConfig) budgetFor(relativePath string) ByteSize {
+	var matching []string
+
+	for pattern := range c.Budgets {
+		if matched, _ := path.Match(pattern, relativePath); matched {
+			matching = append(matching, pattern)
+		}
+	}
+
+	if len(matching) == 0 {
+		return c.MaxEntrySize
+	}
+
+	specificity := func(pattern string) int {
+		return len(pattern) - strings.Count(pattern, "*") - strings.Count(pattern, "?")
+	}
+
+	sort.Slice(matching, func(i, j int) bool {
+		if specificity(matching[i]) != specificity(matching[j]) {
+			return specificity(matching[i]) > specificity(matching[j])
+		}
+		return matching[i] < matching[j]
+	})
+
+	return c.Budgets[matching[0]]
+}
+
+// newestFile returns the modification time of the newest file in dir accepted by filter.
+// node_modules and dist folders are not considered.
+func newestFile(dir string, filter func(string) bool) (time.Time, bool, error) {
+	var newest time.Time
+	found := false
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return newest, false, nil
+	}
+
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if p != dir && (d.Name() == "node_modules" || d.Name() == "dist") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !filter(p) {
+			return nil
+		}
+
+		info, err := d.Info()
+
+		if err != nil {
+			return err
+		}
+
+		found = true
+
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+
+		return nil
+	})
+
+	return newest, found, err
+}
+
+func init() {
+	AddTool(Assets{})
+}
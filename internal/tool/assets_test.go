@@ -0,0 +1,162 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeAssetFile(t *testing.T, file string, size int, modified time.Time) {
+	t.Helper()
+
+	assert.NoError(t, os.MkdirAll(path.Dir(file), 0755))
+	assert.NoError(t, os.WriteFile(file, make([]byte, size), 0644))
+	assert.NoError(t, os.Chtimes(file, modified, modified))
+}
+
+func assetsCheck(t *testing.T, config ToolConfig) []string {
+	t.Helper()
+
+	check := NewCheck()
+	assert.NoError(t, Assets{}.Check(context.Background(), check, config))
+
+	var identifiers []string
+	for _, r := range check.Results {
+		identifiers = append(identifiers, r.Identifier)
+	}
+
+	return identifiers
+}
+
+func TestAssetsMissingBuild(t *testing.T) {
+	root := t.TempDir()
+	adminDir := path.Join(root, "src", "Resources", "app", "administration")
+	writeAssetFile(t, path.Join(adminDir, "src", "main.js"), 10, time.Now())
+
+	assert.Equal(t, []string{"assets/missing-build"}, assetsCheck(t, ToolConfig{RootDir: root, AdminDirectories: []string{adminDir}}))
+}
+
+func TestAssetsStaleBuild(t *testing.T) {
+	root := t.TempDir()
+	storefrontDir := path.Join(root, "src", "Resources", "app", "storefront")
+	now := time.Now()
+
+	writeAssetFile(t, path.Join(storefrontDir, "dist", "storefront", "js", "test", "test.js"), 10, now.Add(-time.Hour))
+	writeAssetFile(t, path.Join(storefrontDir, "src", "main.js"), 10, now)
+
+	assert.Equal(t, []string{"assets/stale-build"}, assetsCheck(t, ToolConfig{RootDir: root, StorefrontDirectories: []string{storefrontDir}}))
+}
+
+func TestAssetsSizeBudget(t *testing.T) {
+	root := t.TempDir()
+	adminDir := path.Join(root, "src", "Resources", "app", "administration")
+	now := time.Now()
+
+	writeAssetFile(t, path.Join(adminDir, "src", "main.js"), 10, now.Add(-time.Hour))
+	writeAssetFile(t, path.Join(root, "src", "Resources", "public", "administration", "js", "test.js"), 2048, now)
+
+	config := ToolConfig{
+		RootDir:          root,
+		AdminDirectories: []string{adminDir},
+		Verifier: VerifierConfig{Assets: AssetsConfig{
+			MaxEntrySize: 4 * KiloByte,
+			Budgets:      map[string]ByteSize{"src/Resources/public/administration/js/*.js": KiloByte},
+		}},
+	}
+
+	assert.Equal(t, []string{"assets/size-budget"}, assetsCheck(t, config))
+
+	config.Verifier.Assets.Budgets = nil
+	assert.Empty(t, assetsCheck(t, config))
+}
+
+func TestAssetsSizeBudgetOnlyForEntries(t *testing.T) {
+	root := t.TempDir()
+	storefrontDir := path.Join(root, "src", "Resources", "app", "storefront")
+	adminDir := path.Join(root, "src", "Resources", "app", "administration")
+	adminCompiled := path.Join(root, "src", "Resources", "public", "administration")
+	now := time.Now()
+
+	writeAssetFile(t, path.Join(storefrontDir, "src", "main.js"), 10, now.Add(-time.Hour))
+	writeAssetFile(t, path.Join(storefrontDir, "dist", "storefront", "js", "test", "test.js"), 2048, now)
+	writeAssetFile(t, path.Join(storefrontDir, "dist", "storefront", "js", "test", "chunk-1.js"), 2048, now)
+
+	writeAssetFile(t, path.Join(adminDir, "src", "main.js"), 10, now.Add(-time.Hour))
+	writeAssetFile(t, path.Join(adminCompiled, "assets", "test-abc.js"), 2048, now)
+	writeAssetFile(t, path.Join(adminCompiled, "assets", "lazy-def.js"), 2048, now)
+	assert.NoError(t, os.MkdirAll(path.Join(adminCompiled, ".vite"), 0755))
+	assert.NoError(t, os.WriteFile(path.Join(adminCompiled, ".vite", "manifest.json"), []byte(`{
+		"main.js": {"file": "assets/test-abc.js", "isEntry": true},
+		"lazy.js": {"file": "assets/lazy-def.js"}
+	}`), 0644))
+
+	config := ToolConfig{
+		RootDir:               root,
+		AdminDirectories:      []string{adminDir},
+		StorefrontDirectories: []string{storefrontDir},
+		Verifier:              VerifierConfig{Assets: AssetsConfig{MaxEntrySize: KiloByte}},
+	}
+
+	check := NewCheck()
+	assert.NoError(t, Assets{}.Check(context.Background(), check, config))
+
+	var paths []string
+	for _, r := range check.Results {
+		paths = append(paths, r.Path)
+	}
+
+	assert.ElementsMatch(t, []string{
+		"src/Resources/public/administration/assets/test-abc.js",
+		"src/Resources/app/storefront/dist/storefront/js/test/test.js",
+	}, paths)
+}
+
+func TestAssetsBudgetForMostSpecificGlob(t *testing.T) {
+	config := AssetsConfig{
+		MaxEntrySize: 4 * KiloByte,
+		Budgets: map[string]ByteSize{
+			"src/Resources/public/*/js/*.js":              KiloByte,
+			"src/Resources/public/administration/js/*.js": 2 * KiloByte,
+			"src/Resources/public/administration/js/a*":   3 * KiloByte,
+		},
+	}
+
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, 2*KiloByte, config.budgetFor("src/Resources/public/administration/js/app.js"))
+	}
+
+	assert.Equal(t, KiloByte, config.budgetFor("src/Resources/public/storefront/js/app.js"))
+	assert.Equal(t, 4*KiloByte, config.budgetFor("other.js"))
+}
+
+func TestAssetsReleaseLeftovers(t *testing.T) {
+	root := t.TempDir()
+	storefrontDir := path.Join(root, "src", "Resources", "app", "storefront")
+
+	writeAssetFile(t, path.Join(storefrontDir, "node_modules", "foo", "index.js"), 10, time.Now())
+	writeAssetFile(t, path.Join(storefrontDir, "dist", "storefront", "js", "test", "test.js.map"), 10, time.Now())
+
+	assert.ElementsMatch(t, []string{"assets/node-modules", "assets/source-map"}, assetsCheck(t, ToolConfig{RootDir: root, ArchivePath: "test.zip"}))
+}
+
+func TestParseByteSize(t *testing.T) {
+	cases := map[string]ByteSize{
+		"512":   512,
+		"500KB": 500 * KiloByte,
+		"2mb":   2 * MegaByte,
+		"1.5GB": GigaByte + GigaByte/2,
+	}
+
+	for input, expected := range cases {
+		size, err := ParseByteSize(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, size, input)
+	}
+
+	_, err := ParseByteSize("big")
+	assert.Error(t, err)
+}
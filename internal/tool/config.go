@@ -0,0 +1,216 @@
+package tool
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/shopware/extension-verifier/internal/llm"
+	"gopkg.in/yaml.v3"
+)
+
+var possibleVerifierConfigs = []string{
+	".shopware-extension.yml",
+	".shopware-extension.yaml",
+	".shopware-project.yml",
+	".shopware-project.yaml",
+}
+
+// VerifierConfig contains the settings of the verifier itself. They are read
+// from additional sections of the extension or project config file.
+type VerifierConfig struct {
+	Assets  AssetsConfig  `yaml:"assets"`
+	Release ReleaseConfig `yaml:"release"`
+	LLM     LLMConfig     `yaml:"llm"`
+	Format  FormatConfig  `yaml:"format"`
+}
+
+type AssetsConfig struct {
+	// Maximum size of a compiled entry bundle, used when no budget matches
+	MaxEntrySize ByteSize `yaml:"max_entry_size"`
+	// Size budgets of entry bundles keyed by a glob relative to the extension root, the most specific glob wins
+	Budgets map[string]ByteSize `yaml:"budgets"`
+}
+
+type ReleaseConfig struct {
+	// Maximum size of a single file inside the release ZIP
+	MaxFileSize ByteSize `yaml:"max_file_size"`
+	// Maximum uncompressed size of all files inside the release ZIP
+	MaxTotalSize ByteSize `yaml:"max_total_size"`
+}
+
+type LLMConfig struct {
+	// Provider profiles keyed by the name passed to --provider
+	Providers map[string]llm.ProviderProfile `yaml:"providers"`
+}
+
+// FormatConfig overrides the formatting of admin templates, unset values are taken
+// from .editorconfig or the defaults.
+type FormatConfig struct {
+	IndentStyle                   html.IndentStyle      `yaml:"indent_style"`
+	IndentSize                    int                   `yaml:"indent_size"`
+	PrintWidth                    int                   `yaml:"print_width"`
+	ExpressionWidth               int                   `yaml:"expression_width"`
+	InlineExpressionsWidth        int                   `yaml:"inline_expressions_width"`
+	AttributeWrap                 html.AttributeWrap    `yaml:"attribute_wrap"`
+	SelfClosing                   html.SelfClosingStyle `yaml:"self_closing"`
+	BlankLineBetweenBlockChildren *bool                 `yaml:"blank_line_between_block_children"`
+	BlankLineBetweenTemplates     *bool                 `yaml:"blank_line_between_templates"`
+}
+
+// apply sets the configured values on options.
+func (c FormatConfig) apply(options *html.FormatOptions) error {
+	switch c.IndentStyle {
+	case "":
+	case html.IndentSpace, html.IndentTab:
+		options.IndentStyle = c.IndentStyle
+	default:
+		return fmt.Errorf("invalid format.indent_style %q, expected space or tab", c.IndentStyle)
+	}
+
+	switch c.AttributeWrap {
+	case "":
+	case html.AttributeWrapAuto, html.AttributeWrapAlways, html.AttributeWrapFit:
+		options.AttributeWrap = c.AttributeWrap
+	default:
+		return fmt.Errorf("invalid format.attribute_wrap %q, expected auto, always or fit", c.AttributeWrap)
+	}
+
+	switch c.SelfClosing {
+	case "":
+	case html.SelfClosingCompact, html.SelfClosingSpaced, html.SelfClosingVoid:
+		options.SelfClosing = c.SelfClosing
+	default:
+		return fmt.Errorf("invalid format.self_closing %q, expected compact, spaced or void", c.SelfClosing)
+	}
+
+	if c.IndentSize > 0 {
+		options.IndentSize = c.IndentSize
+	}
+
+	if c.PrintWidth > 0 {
+		options.PrintWidth = c.PrintWidth
+	}
+
+	if c.ExpressionWidth > 0 {
+		options.ExpressionWidth = c.ExpressionWidth
+	}
+
+	if c.InlineExpressionsWidth > 0 {
+		options.InlineExpressionsWidth = c.InlineExpressionsWidth
+	}
+
+	if c.BlankLineBetweenBlockChildren != nil {
+		options.BlankLineBetweenBlockChildren = *c.BlankLineBetweenBlockChildren
+	}
+
+	if c.BlankLineBetweenTemplates != nil {
+		options.BlankLineBetweenTemplates = *c.BlankLineBetweenTemplates
+	}
+
+	return nil
+}
+
+func defaultVerifierConfig() VerifierConfig {
+	return VerifierConfig{
+		Assets: AssetsConfig{
+			MaxEntrySize: 2 * MegaByte,
+		},
+		Release: ReleaseConfig{
+			MaxFileSize:  10 * MegaByte,
+			MaxTotalSize: 50 * MegaByte,
+		},
+	}
+}
+
+// readVerifierConfig reads the verifier sections of the config file in root.
+// Missing files or sections fall back to the defaults.
+func readVerifierConfig(root string) (VerifierConfig, error) {
+	cfg := defaultVerifierConfig()
+
+	for _, name := range possibleVerifierConfigs {
+		content, err := os.ReadFile(path.Join(root, name))
+
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return cfg, err
+		}
+
+		if err := yaml.Unmarshal(content, &cfg); err != nil {
+			return cfg, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+
+		break
+	}
+
+	return cfg, nil
+}
+
+const (
+	KiloByte ByteSize = 1024
+	MegaByte          = 1024 * KiloByte
+	GigaByte          = 1024 * MegaByte
+)
+
+// ByteSize is a size in bytes which can be written as 512, "500KB" or "2MB" in the config.
+type ByteSize int64
+
+func (b *ByteSize) UnmarshalYAML(value *yaml.Node) error {
+	size, err := ParseByteSize(value.Value)
+
+	if err != nil {
+		return err
+	}
+
+	*b = size
+
+	return nil
+}
+
+func (b ByteSize) String() string {
+	switch {
+	case b >= GigaByte && b%GigaByte == 0:
+		return fmt.Sprintf("%dGB", b/GigaByte)
+	case b >= MegaByte:
+		return fmt.Sprintf("%.1fMB", float64(b)/float64(MegaByte))
+	case b >= KiloByte:
+		return fmt.Sprintf("%.1fKB", float64(b)/float64(KiloByte))
+	}
+
+	return fmt.Sprintf("%dB", int64(b))
+}
+
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	unit := ByteSize(1)
+
+	for _, suffix := range []struct {
+		name string
+		size ByteSize
+	}{
+		{"GB", GigaByte},
+		{"MB", MegaByte},
+		{"KB", KiloByte},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(s, suffix.name) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, suffix.name))
+			unit = suffix.size
+			break
+		}
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	return ByteSize(value * float64(unit)), nil
+}
@@ -0,0 +1,225 @@
+package tool
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shopware/extension-verifier/internal/html"
+)
+
+// editorConfigSection holds the properties of a section for the files matching its glob.
+type editorConfigSection struct {
+	pattern    *regexp.Regexp
+	properties map[string]string
+}
+
+// editorConfigProperties returns the .editorconfig properties for file. The files are searched from the
+// directory of file upwards until one declares root = true, closer files take precedence.
+func editorConfigProperties(file string) (map[string]string, error) {
+	file, err := filepath.Abs(file)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var found [][]editorConfigSection
+	dir := filepath.Dir(file)
+
+	for {
+		sections, root, err := readEditorConfig(dir)
+
+		if err != nil {
+			return nil, err
+		}
+
+		found = append(found, sections)
+
+		parent := filepath.Dir(dir)
+
+		if root || parent == dir {
+			break
+		}
+
+		dir = parent
+	}
+
+	properties := map[string]string{}
+
+	for i := len(found) - 1; i >= 0; i-- {
+		for _, section := range found[i] {
+			if !section.pattern.MatchString(filepath.ToSlash(file)) {
+				continue
+			}
+
+			for key, value := range section.properties {
+				properties[key] = value
+			}
+		}
+	}
+
+	return properties, nil
+}
+
+// readEditorConfig reads the .editorconfig in dir, a missing file has no sections.
+func readEditorConfig(dir string) (sections []editorConfigSection, root bool, err error) {
+	f, err := os.Open(filepath.Join(dir, ".editorconfig"))
+
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	defer func() {
+		if closeErr := f.Close(); closeErr != nil && err == nil {
+			err = fmt.Errorf("failed to close .editorconfig: %w", closeErr)
+		}
+	}()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' && line[len(line)-1] == ']' {
+			pattern, err := editorConfigGlob(filepath.ToSlash(dir), line[1:len(line)-1])
+
+			if err != nil {
+				return nil, false, fmt.Errorf("invalid section %s in %s: %w", line, filepath.Join(dir, ".editorconfig"), err)
+			}
+
+			sections = append(sections, editorConfigSection{pattern: pattern, properties: map[string]string{}})
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+
+		if !ok {
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.ToLower(strings.TrimSpace(value))
+
+		if len(sections) == 0 {
+			// Only root is allowed before the first section
+			root = key == "root" && value == "true"
+			continue
+		}
+
+		sections[len(sections)-1].properties[key] = value
+	}
+
+	return sections, root, scanner.Err()
+}
+
+// editorConfigGlob converts the glob of a section to a regular expression for absolute paths.
+// Globs without a slash match files in any directory below dir.
+func editorConfigGlob(dir, glob string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+
+	expr.WriteString("^" + regexp.QuoteMeta(strings.TrimSuffix(dir, "/")) + "/")
+
+	if !strings.Contains(glob, "/") {
+		expr.WriteString("(?:.*/)?")
+	}
+
+	glob = strings.TrimPrefix(glob, "/")
+	braces := 0
+
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; c {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				expr.WriteString(".*")
+				i++
+			} else {
+				expr.WriteString("[^/]*")
+			}
+		case '?':
+			expr.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i:], ']')
+
+			if end == -1 {
+				expr.WriteString(`\[`)
+				continue
+			}
+
+			class := glob[i+1 : i+end]
+
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			expr.WriteString("[" + class + "]")
+			i += end
+		case '{':
+			braces++
+			expr.WriteString("(?:")
+		case '}':
+			if braces == 0 {
+				expr.WriteString(`\}`)
+				continue
+			}
+			braces--
+			expr.WriteString(")")
+		case ',':
+			if braces == 0 {
+				expr.WriteString(",")
+				continue
+			}
+			expr.WriteString("|")
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				expr.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			}
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if braces > 0 {
+		return nil, fmt.Errorf("unclosed brace in %q", glob)
+	}
+
+	expr.WriteString("$")
+
+	return regexp.Compile(expr.String())
+}
+
+// applyEditorConfig sets the indentation and print width of options from .editorconfig properties.
+func applyEditorConfig(options *html.FormatOptions, properties map[string]string) {
+	switch properties["indent_style"] {
+	case "space":
+		options.IndentStyle = html.IndentSpace
+	case "tab":
+		options.IndentStyle = html.IndentTab
+	}
+
+	size := properties["indent_size"]
+
+	if size == "tab" {
+		size = properties["tab_width"]
+	}
+
+	if value, err := strconv.Atoi(size); err == nil && value > 0 {
+		options.IndentSize = value
+	}
+
+	if value, err := strconv.Atoi(properties["max_line_length"]); err == nil && value > 0 {
+		options.PrintWidth = value
+	}
+}
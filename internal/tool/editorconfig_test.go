@@ -0,0 +1,87 @@
+package tool
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/shopware/extension-verifier/internal/html"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditorConfigGlob(t *testing.T) {
+	cases := []struct {
+		glob    string
+		file    string
+		matches bool
+	}{
+		{"*", "/ext/src/a.twig", true},
+		{"*.twig", "/ext/src/a.html.twig", true},
+		{"*.twig", "/ext/src/a.js", false},
+		{"*.{js,twig}", "/ext/a.js", true},
+		{"src/*.twig", "/ext/src/a.twig", true},
+		{"src/*.twig", "/ext/src/sub/a.twig", false},
+		{"/src/**.twig", "/ext/src/sub/a.twig", true},
+		{"[ab].twig", "/ext/a.twig", true},
+		{"[!ab].twig", "/ext/a.twig", false},
+	}
+
+	for _, tc := range cases {
+		pattern, err := editorConfigGlob("/ext", tc.glob)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.matches, pattern.MatchString(tc.file), "%s on %s", tc.glob, tc.file)
+	}
+
+	_, err := editorConfigGlob("/ext", "*.{js")
+	assert.Error(t, err)
+}
+
+func TestAdminFormatOptions(t *testing.T) {
+	root := t.TempDir()
+	adminDir := path.Join(root, "src", "Resources", "app", "administration")
+	file := path.Join(adminDir, "index.html.twig")
+
+	assert.NoError(t, os.MkdirAll(adminDir, 0755))
+	assert.NoError(t, os.WriteFile(path.Join(root, ".editorconfig"), []byte(`root = true
+
+[*]
+indent_style = space
+indent_size = 4
+
+[*.twig]
+indent_size = 2
+max_line_length = 120
+`), 0644))
+	assert.NoError(t, os.WriteFile(path.Join(adminDir, ".editorconfig"), []byte(`[*.html.twig]
+indent_style = tab
+`), 0644))
+
+	options, err := adminFormatOptions(ToolConfig{RootDir: root}, file)
+	assert.NoError(t, err)
+
+	expected := html.DefaultFormatOptions()
+	expected.IndentStyle = html.IndentTab
+	expected.IndentSize = 2
+	expected.PrintWidth = 120
+	assert.Equal(t, expected, options)
+
+	// The format config overrides the .editorconfig
+	blankLines := false
+	config := ToolConfig{RootDir: root, Verifier: VerifierConfig{Format: FormatConfig{
+		IndentStyle:                   html.IndentSpace,
+		AttributeWrap:                 html.AttributeWrapFit,
+		BlankLineBetweenBlockChildren: &blankLines,
+	}}}
+
+	options, err = adminFormatOptions(config, file)
+	assert.NoError(t, err)
+
+	expected.IndentStyle = html.IndentSpace
+	expected.AttributeWrap = html.AttributeWrapFit
+	expected.BlankLineBetweenBlockChildren = false
+	assert.Equal(t, expected, options)
+
+	config.Verifier.Format.SelfClosing = "xhtml"
+	_, err = adminFormatOptions(config, file)
+	assert.ErrorContains(t, err, "invalid format.self_closing")
+}
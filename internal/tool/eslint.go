@@ -160,7 +160,7 @@ func (e Eslint) Fix(ctx context.Context, config ToolConfig) error {
 	return gr.Wait()
 }
 
-func (e Eslint) Format(ctx context.Context, config ToolConfig, dryRun bool) error {
+func (e Eslint) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
 	return nil
 }
 
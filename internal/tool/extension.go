@@ -21,8 +21,15 @@ func ConvertExtensionToToolConfig(ext extension.Extension) (*ToolConfig, error)
 		})
 	}
 
+	verifierCfg, err := readVerifierConfig(ext.GetRootDir())
+
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := &ToolConfig{
 		Extension:             ext,
+		Verifier:              verifierCfg,
 		ValidationIgnores:     ignores,
 		RootDir:               ext.GetPath(),
 		SourceDirectories:     ext.GetSourceDirs(),
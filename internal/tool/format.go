@@ -0,0 +1,125 @@
+package tool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shopware/extension-verifier/internal/diff"
+)
+
+var hunkHeaderRegex = regexp.MustCompile(`(?m)^@@ -(\d+)`)
+
+// addFormatDiff adds a format/<tool> result when formatting changes the content of file.
+func addFormatDiff(check *Check, config ToolConfig, toolName, file, original, formatted string) {
+	if original == formatted {
+		return
+	}
+
+	relativePath := strings.TrimPrefix(strings.TrimPrefix(file, "/private"), config.RootDir+"/")
+
+	addFormatResult(check, toolName, relativePath, diff.Unified("a/"+relativePath, "b/"+relativePath, original, formatted))
+}
+
+// addFormatResult adds a format/<tool> result for a unified diff of the file, an empty diff has no changes.
+func addFormatResult(check *Check, toolName, relativePath, unified string) {
+	if unified == "" {
+		return
+	}
+
+	added, removed := 0, 0
+
+	for _, line := range strings.Split(unified, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+
+	// The result points to the first hunk
+	line := 0
+
+	if match := hunkHeaderRegex.FindStringSubmatch(unified); match != nil {
+		line, _ = strconv.Atoi(match[1])
+	}
+
+	check.AddResult(CheckResult{
+		Path:       relativePath,
+		Line:       line,
+		Message:    fmt.Sprintf("The file is not formatted by %s, %d lines would be added and %d removed", toolName, added, removed),
+		Severity:   "error",
+		Identifier: "format/" + toolName,
+		Diff:       unified,
+	})
+}
+
+// formatCopy copies the files below dir into a temporary directory and runs format on it. It returns the
+// formatted content of the files keyed by their path below dir, the files in dir are not touched.
+func formatCopy(dir string, files []string, format func(tmpDir string) error) (_ map[string]string, err error) {
+	tmpDir, err := os.MkdirTemp(os.TempDir(), "format-check-*")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if removeErr := os.RemoveAll(tmpDir); removeErr != nil && err == nil {
+			err = fmt.Errorf("failed to remove temporary directory: %w", removeErr)
+		}
+	}()
+
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(dir, file))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(tmpDir, file)), os.ModePerm); err != nil {
+			return nil, err
+		}
+
+		if err := os.WriteFile(filepath.Join(tmpDir, file), content, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := format(tmpDir); err != nil {
+		return nil, err
+	}
+
+	formatted := make(map[string]string, len(files))
+
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(tmpDir, file))
+
+		if err != nil {
+			return nil, err
+		}
+
+		formatted[file] = string(content)
+	}
+
+	return formatted, nil
+}
+
+// addFormatCopyDiffs compares the formatted copies of formatCopy with the files in dir.
+func addFormatCopyDiffs(check *Check, config ToolConfig, toolName, dir string, formatted map[string]string) error {
+	for file, content := range formatted {
+		original, err := os.ReadFile(filepath.Join(dir, file))
+
+		if err != nil {
+			return err
+		}
+
+		addFormatDiff(check, config, toolName, filepath.Join(dir, file), string(original), content)
+	}
+
+	return nil
+}
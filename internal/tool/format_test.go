@@ -0,0 +1,80 @@
+package tool
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddFormatDiff(t *testing.T) {
+	check := NewCheck()
+	config := ToolConfig{RootDir: "/ext"}
+
+	addFormatDiff(check, config, "prettier", "/ext/src/main.js", "a\nb\nc\n", "a\nb\nc\n")
+	assert.Empty(t, check.Results)
+
+	addFormatDiff(check, config, "prettier", "/ext/src/main.js", "a\nb\nc\n", "a\nB\nc\nd\n")
+	assert.Len(t, check.Results, 1)
+
+	result := check.Results[0]
+	assert.Equal(t, "src/main.js", result.Path)
+	assert.Equal(t, 1, result.Line)
+	assert.Equal(t, "format/prettier", result.Identifier)
+	assert.Equal(t, "error", result.Severity)
+	assert.Equal(t, "The file is not formatted by prettier, 2 lines would be added and 1 removed", result.Message)
+	assert.Equal(t, `--- a/src/main.js
++++ b/src/main.js
+@@ -1,3 +1,4 @@
+ a
+-b
++B
+ c
++d
+`, result.Diff)
+
+	// A changed line ending at the end of the file is a change as well
+	check = NewCheck()
+	addFormatDiff(check, config, "admintwiglinter", "/ext/src/index.html.twig", "<div></div>", "<div></div>\n")
+	assert.Len(t, check.Results, 1)
+	assert.Equal(t, "The file is not formatted by admintwiglinter, 1 lines would be added and 1 removed", check.Results[0].Message)
+}
+
+func TestFormatCopy(t *testing.T) {
+	dir := t.TempDir()
+
+	assert.NoError(t, os.MkdirAll(path.Join(dir, "scss"), 0755))
+	assert.NoError(t, os.WriteFile(path.Join(dir, "scss", "base.scss"), []byte("a{color:red}\n"), 0644))
+
+	formatted, err := formatCopy(dir, []string{"scss/base.scss"}, func(tmpDir string) error {
+		assert.NotEqual(t, dir, tmpDir)
+		return os.WriteFile(path.Join(tmpDir, "scss", "base.scss"), []byte("a {\n    color: red;\n}\n"), 0644)
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"scss/base.scss": "a {\n    color: red;\n}\n"}, formatted)
+
+	// The original file is not touched
+	content, err := os.ReadFile(path.Join(dir, "scss", "base.scss"))
+	assert.NoError(t, err)
+	assert.Equal(t, "a{color:red}\n", string(content))
+
+	check := NewCheck()
+	assert.NoError(t, addFormatCopyDiffs(check, ToolConfig{RootDir: dir}, "stylelint", dir, formatted))
+	assert.Len(t, check.Results, 1)
+	assert.Equal(t, "scss/base.scss", check.Results[0].Path)
+	assert.True(t, strings.HasPrefix(check.Results[0].Diff, "--- a/scss/base.scss\n+++ b/scss/base.scss\n"))
+}
+
+func TestParsePHPCSFixerDiffs(t *testing.T) {
+	output := `{"files":[{"name":"src/Service.php","appliedFixers":["braces"],"diff":"--- Original\n+++ New\n@@ -3,1 +3,1 @@\n-class Service{\n+class Service\n"},{"name":"src/Clean.php","diff":""}],"time":{"total":0.1}}`
+
+	diffs, err := parsePHPCSFixerDiffs([]byte(output))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"src/Service.php": "@@ -3,1 +3,1 @@\n-class Service{\n+class Service\n"}, diffs)
+
+	_, err = parsePHPCSFixerDiffs([]byte("PHP Fatal error"))
+	assert.ErrorContains(t, err, "failed to parse php-cs-fixer output")
+}
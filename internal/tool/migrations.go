@@ -0,0 +1,332 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	migrationClassRegex     = regexp.MustCompile(`class\s+(Migration(\d+)\w*)\s+extends`)
+	migrationTimestampRegex = regexp.MustCompile(`function\s+getCreationTimestamp\s*\(\s*\)\s*:\s*int\s*\{\s*return\s+(\d+)\s*;`)
+	migrationDestructiveSQL = regexp.MustCompile(`\b(DROP|RENAME)\b`)
+	migrationCreateTable    = regexp.MustCompile(`\bCREATE\s+TABLE\b`)
+	migrationIfNotExists    = regexp.MustCompile(`\bCREATE\s+TABLE\s+IF\s+NOT\s+EXISTS\b`)
+)
+
+// migrationFile holds the information extracted from one Migration*.php class.
+type migrationFile struct {
+	Path      string
+	ClassName string
+	// Timestamp contained in the class name
+	NameTimestamp int64
+	// Timestamp returned by getCreationTimestamp
+	CreationTimestamp int64
+	// Line of the getCreationTimestamp return statement
+	TimestampLine int
+	Content       string
+}
+
+// Migrations checks Shopware database migrations for destructive or non-idempotent changes.
+type Migrations struct{}
+
+func (m Migrations) Name() string {
+	return "migrations"
+}
+
+func (m Migrations) Check(ctx context.Context, check *Check, config ToolConfig) error {
+	for _, sourceDirectory := range config.SourceDirectories {
+		files, err := filepath.Glob(path.Join(sourceDirectory, "Migration", "Migration*.php"))
+
+		if err != nil {
+			return err
+		}
+
+		var migrations []migrationFile
+
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+
+			if err != nil {
+				return err
+			}
+
+			migration, ok := parseMigration(string(content))
+
+			if !ok {
+				continue
+			}
+
+			migration.Path = strings.TrimPrefix(strings.TrimPrefix(file, "/private"), config.RootDir+"/")
+			migrations = append(migrations, migration)
+
+			for _, result := range checkMigrationSQL(migration) {
+				check.AddResult(result)
+			}
+		}
+
+		for _, result := range checkMigrationTimestamps(migrations, time.Now()) {
+			check.AddResult(result)
+		}
+	}
+
+	return nil
+}
+
+func (m Migrations) Fix(ctx context.Context, config ToolConfig) error {
+	return nil
+}
+
+func (m Migrations) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
+	return nil
+}
+
+func parseMigration(content string) (migrationFile, bool) {
+	classMatch := migrationClassRegex.FindStringSubmatch(content)
+
+	if classMatch == nil {
+		return migrationFile{}, false
+	}
+
+	migration := migrationFile{ClassName: classMatch[1], Content: content}
+	migration.NameTimestamp, _ = strconv.ParseInt(classMatch[2], 10, 64)
+
+	if match := migrationTimestampRegex.FindStringSubmatchIndex(content); match != nil {
+		migration.CreationTimestamp, _ = strconv.ParseInt(content[match[2]:match[3]], 10, 64)
+		migration.TimestampLine = strings.Count(content[:match[2]], "\n") + 1
+	}
+
+	return migration, true
+}
+
+func checkMigrationTimestamps(migrations []migrationFile, now time.Time) []CheckResult {
+	var results []CheckResult
+	seen := map[int64]string{}
+	// The migration with the newest timestamp of the classes sorted before the current one
+	var newest *migrationFile
+
+	// Migrations are listed by their class name, but executed in the order of their timestamps
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].ClassName < migrations[j].ClassName
+	})
+
+	for i, migration := range migrations {
+		if migration.CreationTimestamp == 0 {
+			results = append(results, CheckResult{
+				Path:       migration.Path,
+				Message:    fmt.Sprintf("%s does not return a literal timestamp in getCreationTimestamp", migration.ClassName),
+				Severity:   "warning",
+				Identifier: "migrations/timestamp-mismatch",
+			})
+
+			continue
+		}
+
+		if migration.CreationTimestamp != migration.NameTimestamp {
+			results = append(results, CheckResult{
+				Path:       migration.Path,
+				Line:       migration.TimestampLine,
+				Message:    fmt.Sprintf("getCreationTimestamp returns %d, but the class name %s contains %d", migration.CreationTimestamp, migration.ClassName, migration.NameTimestamp),
+				Severity:   "error",
+				Identifier: "migrations/timestamp-mismatch",
+			})
+		}
+
+		if other, ok := seen[migration.CreationTimestamp]; ok {
+			results = append(results, CheckResult{
+				Path:       migration.Path,
+				Line:       migration.TimestampLine,
+				Message:    fmt.Sprintf("The timestamp %d is already used by %s", migration.CreationTimestamp, other),
+				Severity:   "error",
+				Identifier: "migrations/duplicate-timestamp",
+			})
+		}
+
+		seen[migration.CreationTimestamp] = migration.ClassName
+
+		if newest != nil && migration.CreationTimestamp < newest.CreationTimestamp {
+			results = append(results, CheckResult{
+				Path:       migration.Path,
+				Line:       migration.TimestampLine,
+				Message:    fmt.Sprintf("%s is sorted after %s, but its timestamp %d is older than %d, so it is executed before", migration.ClassName, newest.ClassName, migration.CreationTimestamp, newest.CreationTimestamp),
+				Severity:   "error",
+				Identifier: "migrations/out-of-order",
+			})
+		}
+
+		if newest == nil || migration.CreationTimestamp > newest.CreationTimestamp {
+			newest = &migrations[i]
+		}
+
+		if migration.CreationTimestamp > now.Unix() {
+			results = append(results, CheckResult{
+				Path:       migration.Path,
+				Line:       migration.TimestampLine,
+				Message:    fmt.Sprintf("The timestamp %d lies in the future, migrations created later will be executed before this one", migration.CreationTimestamp),
+				Severity:   "error",
+				Identifier: "migrations/future-timestamp",
+			})
+		}
+	}
+
+	return results
+}
+
+func checkMigrationSQL(migration migrationFile) []CheckResult {
+	var results []CheckResult
+
+	for _, method := range []string{"update", "updateDestructive"} {
+		start, end, ok := phpMethodBody(migration.Content, method)
+
+		if !ok {
+			continue
+		}
+
+		for _, literal := range phpStringLiterals(migration.Content[start:end]) {
+			sql := strings.ToUpper(literal.Value)
+			line := strings.Count(migration.Content[:start+literal.Offset], "\n") + 1
+
+			if method == "update" {
+				if match := migrationDestructiveSQL.FindString(sql); match != "" {
+					results = append(results, CheckResult{
+						Path:       migration.Path,
+						Line:       line,
+						Message:    fmt.Sprintf("%s statement found in update() of %s. Move destructive changes to updateDestructive()", match, migration.ClassName),
+						Severity:   "error",
+						Identifier: "migrations/destructive-update",
+					})
+				}
+			}
+
+			if migrationCreateTable.MatchString(sql) && !migrationIfNotExists.MatchString(sql) {
+				results = append(results, CheckResult{
+					Path:       migration.Path,
+					Line:       line,
+					Message:    fmt.Sprintf("CREATE TABLE without IF NOT EXISTS in %s() of %s. Migrations must be idempotent", method, migration.ClassName),
+					Severity:   "warning",
+					Identifier: "migrations/create-table-not-idempotent",
+				})
+			}
+		}
+	}
+
+	return results
+}
+
+type phpStringLiteral struct {
+	Value string
+	// Offset of the literal relative to the scanned source
+	Offset int
+}
+
+// phpMethodBody returns the offsets of the body of the method with the given name.
+func phpMethodBody(src, name string) (int, int, bool) {
+	declaration := regexp.MustCompile(`function\s+` + regexp.QuoteMeta(name) + `\s*\(`).FindStringIndex(src)
+
+	if declaration == nil {
+		return 0, 0, false
+	}
+
+	bodyOffset := declaration[1]
+	depth := 0
+	start, end := -1, -1
+
+	scanPHP(src[bodyOffset:], func(offset int, c byte) bool {
+		switch c {
+		case '{':
+			if depth == 0 {
+				start = bodyOffset + offset + 1
+			}
+			depth++
+		case '}':
+			depth--
+			if depth == 0 && start != -1 {
+				end = bodyOffset + offset
+				return false
+			}
+		}
+
+		return true
+	}, nil)
+
+	return start, end, end != -1
+}
+
+// phpStringLiterals returns all single, double quoted and heredoc strings of src.
+func phpStringLiterals(src string) []phpStringLiteral {
+	var literals []phpStringLiteral
+
+	scanPHP(src, nil, func(literal phpStringLiteral) {
+		literals = append(literals, literal)
+	})
+
+	return literals
+}
+
+// scanPHP walks src while skipping comments. Code bytes are passed to onCode,
+// which can stop the scan by returning false. String literals are passed to onString.
+func scanPHP(src string, onCode func(int, byte) bool, onString func(phpStringLiteral)) {
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+
+		switch {
+		case c == '/' && strings.HasPrefix(src[i:], "//"), c == '#' && !strings.HasPrefix(src[i:], "#["):
+			end := strings.IndexByte(src[i:], '\n')
+			if end == -1 {
+				return
+			}
+			i += end
+		case c == '/' && strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end == -1 {
+				return
+			}
+			i += end + 3
+		case c == '\'' || c == '"':
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				j = len(src) - 1
+			}
+			if onString != nil {
+				onString(phpStringLiteral{Value: src[i+1 : j], Offset: i})
+			}
+			i = j
+		case c == '<' && strings.HasPrefix(src[i:], "<<<"):
+			lineEnd := strings.IndexByte(src[i:], '\n')
+			if lineEnd == -1 {
+				return
+			}
+			identifier := strings.Trim(strings.TrimSpace(src[i+3:i+lineEnd]), `'"`)
+			bodyStart := i + lineEnd + 1
+			closing := regexp.MustCompile(`(?m)^[ \t]*` + regexp.QuoteMeta(identifier) + `\b`).FindStringIndex(src[bodyStart:])
+			if closing == nil {
+				return
+			}
+			if onString != nil {
+				onString(phpStringLiteral{Value: src[bodyStart : bodyStart+closing[0]], Offset: i})
+			}
+			i = bodyStart + closing[1] - 1
+		default:
+			if onCode != nil && !onCode(i, c) {
+				return
+			}
+		}
+	}
+}
+
+func init() {
+	AddTool(Migrations{})
+}
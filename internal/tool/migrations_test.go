@@ -0,0 +1,90 @@
+package tool
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testMigration = `<?php declare(strict_types=1);
+
+namespace Frosh\Tools\Migration;
+
+use Doctrine\DBAL\Connection;
+use Shopware\Core\Framework\Migration\MigrationStep;
+
+class Migration1700000000Example extends MigrationStep
+{
+    public function getCreationTimestamp(): int
+    {
+        return 1700000001;
+    }
+
+    public function update(Connection $connection): void
+    {
+        // DROP TABLE in a comment is fine
+        $connection->executeStatement(<<<'SQL'
+CREATE TABLE IF NOT EXISTS frosh_example (
+    id BINARY(16) NOT NULL
+);
+SQL);
+
+        $connection->executeStatement('CREATE TABLE frosh_other (id BINARY(16) NOT NULL)');
+
+        if (true) {
+            $connection->executeStatement("ALTER TABLE frosh_example DROP COLUMN foo");
+        }
+    }
+
+    public function updateDestructive(Connection $connection): void
+    {
+        $connection->executeStatement('DROP TABLE IF EXISTS frosh_legacy');
+    }
+}
+`
+
+func TestMigrationSQLChecks(t *testing.T) {
+	migration, ok := parseMigration(testMigration)
+	assert.True(t, ok)
+	assert.Equal(t, "Migration1700000000Example", migration.ClassName)
+	assert.Equal(t, int64(1700000000), migration.NameTimestamp)
+	assert.Equal(t, int64(1700000001), migration.CreationTimestamp)
+	assert.Equal(t, 12, migration.TimestampLine)
+
+	var found []string
+	for _, r := range checkMigrationSQL(migration) {
+		found = append(found, r.Identifier)
+	}
+
+	assert.Equal(t, []string{"migrations/create-table-not-idempotent", "migrations/destructive-update"}, found)
+
+	results := checkMigrationSQL(migration)
+	assert.Equal(t, 24, results[0].Line)
+	assert.Equal(t, 27, results[1].Line)
+}
+
+func TestMigrationTimestampChecks(t *testing.T) {
+	now := time.Unix(1800000000, 0)
+
+	results := checkMigrationTimestamps([]migrationFile{
+		{Path: "a.php", ClassName: "Migration1700000000A", NameTimestamp: 1700000000, CreationTimestamp: 1700000000},
+		{Path: "b.php", ClassName: "Migration1700000000B", NameTimestamp: 1700000000, CreationTimestamp: 1700000000},
+		{Path: "c.php", ClassName: "Migration1700000001C", NameTimestamp: 1700000001, CreationTimestamp: 1700000002},
+		{Path: "d.php", ClassName: "Migration1900000000D", NameTimestamp: 1900000000, CreationTimestamp: 1900000000},
+		{Path: "e.php", ClassName: "Migration1700000002E", NameTimestamp: 1700000002, CreationTimestamp: 1700000001},
+	}, now)
+
+	var found []string
+	for _, r := range results {
+		found = append(found, r.Identifier+" "+r.Path)
+	}
+
+	assert.Equal(t, []string{
+		"migrations/duplicate-timestamp b.php",
+		"migrations/timestamp-mismatch c.php",
+		"migrations/timestamp-mismatch e.php",
+		"migrations/out-of-order e.php",
+		"migrations/future-timestamp d.php",
+	}, found)
+}
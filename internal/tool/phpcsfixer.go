@@ -1,10 +1,15 @@
 package tool
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"os/exec"
 	"path"
+	"strings"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -31,7 +36,7 @@ func (p PHPCSFixer) getConfigPath(cwd, rootDir string) string {
 	return path.Join(cwd, "tools", "php", ".php-cs-fixer.dist.php")
 }
 
-func (p PHPCSFixer) Format(ctx context.Context, config ToolConfig, dryRun bool) error {
+func (p PHPCSFixer) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
 	// Apps don't have an composer.json file, skip them
 	if _, err := os.Stat(path.Join(config.RootDir, "composer.json")); err != nil {
 		return nil
@@ -54,7 +59,7 @@ func (p PHPCSFixer) Format(ctx context.Context, config ToolConfig, dryRun bool)
 
 		args := []string{"fix", "--config", p.getConfigPath(cwd, config.RootDir), fixDir}
 		if dryRun {
-			args = append(args, "--dry-run")
+			args = append(args, "--dry-run", "--diff", "--format=json")
 		}
 
 		cmd := exec.CommandContext(ctx, path.Join(cwd, "tools", "php", "vendor", "bin", "php-cs-fixer"), args...)
@@ -62,14 +67,85 @@ func (p PHPCSFixer) Format(ctx context.Context, config ToolConfig, dryRun bool)
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 
+		if !dryRun {
+			gr.Go(func() error {
+				return cmd.Run()
+			})
+			continue
+		}
+
 		gr.Go(func() error {
-			return cmd.Run()
+			var stdout bytes.Buffer
+			cmd.Stdout = &stdout
+
+			// The exit code 8 means that files need fixing
+			if err := cmd.Run(); err != nil {
+				var exitErr *exec.ExitError
+				if !errors.As(err, &exitErr) || exitErr.ExitCode() != 8 {
+					return err
+				}
+			}
+
+			diffs, err := parsePHPCSFixerDiffs(stdout.Bytes())
+
+			if err != nil {
+				return err
+			}
+
+			for file, hunks := range diffs {
+				if !path.IsAbs(file) {
+					file = path.Join(config.RootDir, file)
+				}
+
+				relativePath := strings.TrimPrefix(strings.TrimPrefix(file, "/private"), config.RootDir+"/")
+
+				addFormatResult(check, p.Name(), relativePath, fmt.Sprintf("--- a/%s\n+++ b/%s\n%s", relativePath, relativePath, hunks))
+			}
+
+			return nil
 		})
 	}
 
 	return gr.Wait()
 }
 
+// parsePHPCSFixerDiffs returns the hunks of the diffs in the JSON report of php-cs-fixer keyed by file.
+// The headers are dropped, as php-cs-fixer does not name the files in them.
+func parsePHPCSFixerDiffs(output []byte) (map[string]string, error) {
+	var report struct {
+		Files []struct {
+			Name string `json:"name"`
+			Diff string `json:"diff"`
+		} `json:"files"`
+	}
+
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse php-cs-fixer output: %w", err)
+	}
+
+	diffs := make(map[string]string, len(report.Files))
+
+	for _, file := range report.Files {
+		hunks := file.Diff
+
+		if hunks == "" {
+			continue
+		}
+
+		if index := strings.Index(hunks, "@@"); index != -1 {
+			hunks = hunks[index:]
+		}
+
+		if !strings.HasSuffix(hunks, "\n") {
+			hunks += "\n"
+		}
+
+		diffs[file.Name] = hunks
+	}
+
+	return diffs, nil
+}
+
 func init() {
 	AddTool(PHPCSFixer{})
 }
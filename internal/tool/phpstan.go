@@ -125,7 +125,7 @@ func (p PhpStan) Fix(ctx context.Context, config ToolConfig) error {
 	return nil
 }
 
-func (p PhpStan) Format(ctx context.Context, config ToolConfig, dryRun bool) error {
+func (p PhpStan) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
 	return nil
 }
 
@@ -1,10 +1,13 @@
 package tool
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path"
+	"strings"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -30,7 +33,7 @@ func (b Prettier) Fix(ctx context.Context, config ToolConfig) error {
 	return nil
 }
 
-func (b Prettier) Format(ctx context.Context, config ToolConfig, dryRun bool) error {
+func (b Prettier) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
 	cwd, err := os.Getwd()
 
 	if err != nil {
@@ -50,17 +53,27 @@ func (b Prettier) Format(ctx context.Context, config ToolConfig, dryRun bool) er
 			return err
 		}
 
-		args := []string{
+		prettier := []string{
 			path.Join(cwd, "tools", "js", "node_modules", ".bin", "prettier"),
 			"--config",
 			path.Join(cwd, "tools", "js", ".prettierrc.js"),
-			".",
 		}
 
-		if !dryRun {
-			args = append(args, "--write")
+		if dryRun {
+			gr.Go(func() (err error) {
+				defer func() {
+					if removeErr := os.Remove(path.Join(sourceDirectory, ".prettierignore")); removeErr != nil && err == nil {
+						err = removeErr
+					}
+				}()
+
+				return b.addDiffs(ctx, check, config, sourceDirectory, prettier)
+			})
+			continue
 		}
 
+		args := append(prettier, ".", "--write")
+
 		gr.Go(func() error {
 			cmd := exec.CommandContext(ctx, "node", args...)
 			cmd.Dir = sourceDirectory
@@ -78,6 +91,50 @@ func (b Prettier) Format(ctx context.Context, config ToolConfig, dryRun bool) er
 	return gr.Wait()
 }
 
+// addDiffs adds the changes of the files prettier would format in sourceDirectory to check.
+func (b Prettier) addDiffs(ctx context.Context, check *Check, config ToolConfig, sourceDirectory string, prettier []string) error {
+	var stdout bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, "node", append(prettier, "--list-different", ".")...)
+	cmd.Dir = sourceDirectory
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+
+	// The exit code 1 means that files are not formatted
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return err
+		}
+	}
+
+	var files []string
+
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	formatted, err := formatCopy(sourceDirectory, files, func(tmpDir string) error {
+		cmd := exec.CommandContext(ctx, "node", append(append(prettier, "--write"), files...)...)
+		cmd.Dir = tmpDir
+		cmd.Stderr = os.Stderr
+
+		return cmd.Run()
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return addFormatCopyDiffs(check, config, b.Name(), sourceDirectory, formatted)
+}
+
 func init() {
 	AddTool(Prettier{})
 }
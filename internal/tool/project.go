@@ -184,8 +184,15 @@ func GetConfigFromProject(root string) (*ToolConfig, error) {
 		}
 	}
 
+	verifierCfg, err := readVerifierConfig(root)
+
+	if err != nil {
+		return nil, err
+	}
+
 	toolCfg := &ToolConfig{
 		RootDir:               root,
+		Verifier:              verifierCfg,
 		SourceDirectories:     sourceDirectories,
 		AdminDirectories:      adminDirectories,
 		StorefrontDirectories: storefrontDirectories,
@@ -96,7 +96,7 @@ func (r Rector) Fix(ctx context.Context, config ToolConfig) error {
 	return nil
 }
 
-func (r Rector) Format(ctx context.Context, config ToolConfig, dryRun bool) error {
+func (r Rector) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
 	return nil
 }
 
@@ -0,0 +1,227 @@
+package tool
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/shopware/shopware-cli/logging"
+)
+
+// forbiddenReleaseFiles are base names which are rejected anywhere in a release ZIP.
+var forbiddenReleaseFiles = []string{
+	".git",
+	".DS_Store",
+	"auth.json",
+	".env",
+}
+
+// Release validates the structure and content of a packaged release ZIP.
+type Release struct{}
+
+func (r Release) Name() string {
+	return "release"
+}
+
+func (r Release) Check(ctx context.Context, check *Check, config ToolConfig) error {
+	if config.ArchivePath == "" {
+		return nil
+	}
+
+	archive, err := zip.OpenReader(config.ArchivePath)
+
+	if err != nil {
+		return fmt.Errorf("failed to open release zip: %w", err)
+	}
+
+	defer func() {
+		if err := archive.Close(); err != nil {
+			logging.FromContext(ctx).Warnf("Failed to close release zip: %v", err)
+		}
+	}()
+
+	r.checkTopLevelFolder(check, config, archive.File)
+
+	var totalSize uint64
+	reportedForbidden := map[string]bool{}
+
+	for _, file := range archive.File {
+		name := strings.TrimSuffix(file.Name, "/")
+		totalSize += file.UncompressedSize64
+
+		if forbiddenPath, reason := forbiddenReleaseEntry(name, file.FileInfo().IsDir()); forbiddenPath != "" && !reportedForbidden[forbiddenPath] {
+			reportedForbidden[forbiddenPath] = true
+
+			check.AddResult(CheckResult{
+				Path:       forbiddenPath,
+				Message:    reason,
+				Severity:   "error",
+				Identifier: "release/forbidden-file",
+			})
+		}
+
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		if file.Mode()&fs.ModeSymlink != 0 {
+			if err := r.checkSymlink(check, file); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if file.Mode()&0111 != 0 {
+			check.AddResult(CheckResult{
+				Path:       name,
+				Message:    fmt.Sprintf("The file is executable (%s). Remove the executable bit before packaging", file.Mode().Perm()),
+				Severity:   "error",
+				Identifier: "release/executable",
+			})
+		}
+
+		if config.Verifier.Release.MaxFileSize > 0 && ByteSize(file.UncompressedSize64) > config.Verifier.Release.MaxFileSize {
+			check.AddResult(CheckResult{
+				Path:       name,
+				Message:    fmt.Sprintf("The file has %s and exceeds the limit of %s", ByteSize(file.UncompressedSize64), config.Verifier.Release.MaxFileSize),
+				Severity:   "error",
+				Identifier: "release/file-size",
+			})
+		}
+	}
+
+	if config.Verifier.Release.MaxTotalSize > 0 && ByteSize(totalSize) > config.Verifier.Release.MaxTotalSize {
+		check.AddResult(CheckResult{
+			Path:       "",
+			Message:    fmt.Sprintf("The release has %s uncompressed and exceeds the limit of %s", ByteSize(totalSize), config.Verifier.Release.MaxTotalSize),
+			Severity:   "error",
+			Identifier: "release/total-size",
+		})
+	}
+
+	return nil
+}
+
+// checkTopLevelFolder ensures that all files are inside one folder named like the extension.
+func (r Release) checkTopLevelFolder(check *Check, config ToolConfig, files []*zip.File) {
+	topLevel := map[string]bool{}
+
+	for _, file := range files {
+		parts := strings.SplitN(file.Name, "/", 2)
+
+		// A file directly in the root of the archive
+		if len(parts) == 1 {
+			topLevel[""] = true
+			continue
+		}
+
+		topLevel[parts[0]] = true
+	}
+
+	if len(topLevel) != 1 || topLevel[""] {
+		check.AddResult(CheckResult{
+			Path:       "",
+			Message:    "The release must contain exactly one top-level folder with the extension",
+			Severity:   "error",
+			Identifier: "release/top-level-folder",
+		})
+
+		return
+	}
+
+	if config.Extension == nil {
+		return
+	}
+
+	name, err := config.Extension.GetName()
+
+	if err != nil {
+		return
+	}
+
+	for folder := range topLevel {
+		if folder != name {
+			check.AddResult(CheckResult{
+				Path:       folder,
+				Message:    fmt.Sprintf("The top-level folder %q does not match the technical name %q", folder, name),
+				Severity:   "error",
+				Identifier: "release/top-level-folder",
+			})
+		}
+	}
+}
+
+// checkSymlink flags symlinks pointing outside of the top-level extension folder of their entry.
+func (r Release) checkSymlink(check *Check, file *zip.File) error {
+	reader, err := file.Open()
+
+	if err != nil {
+		return err
+	}
+
+	target, err := io.ReadAll(reader)
+
+	if closeErr := reader.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read symlink %s: %w", file.Name, err)
+	}
+
+	resolved := path.Clean(path.Join(path.Dir(file.Name), string(target)))
+	extensionDir, _, _ := strings.Cut(file.Name, "/")
+
+	if path.IsAbs(string(target)) || (resolved != extensionDir && !strings.HasPrefix(resolved, extensionDir+"/")) {
+		check.AddResult(CheckResult{
+			Path:       file.Name,
+			Message:    fmt.Sprintf("The symlink points to %q outside of the extension", string(target)),
+			Severity:   "error",
+			Identifier: "release/symlink",
+		})
+	}
+
+	return nil
+}
+
+func (r Release) Fix(ctx context.Context, config ToolConfig) error {
+	return nil
+}
+
+func (r Release) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
+	return nil
+}
+
+// forbiddenReleaseEntry returns the forbidden file or folder containing name and why it must not be
+// part of a release. The path is empty when the entry is allowed.
+func forbiddenReleaseEntry(name string, isDir bool) (string, string) {
+	parts := strings.Split(name, "/")
+
+	for i, part := range parts {
+		for _, forbidden := range forbiddenReleaseFiles {
+			if part == forbidden {
+				return strings.Join(parts[:i+1], "/"), fmt.Sprintf("%s must not be part of the release", forbidden)
+			}
+		}
+	}
+
+	// tests folder of the extension itself, dependencies in vendor may ship their own
+	if len(parts) > 1 && parts[1] == "tests" && (isDir || len(parts) > 2) {
+		return strings.Join(parts[:2], "/"), "The tests folder must not be part of the release"
+	}
+
+	if !isDir && strings.HasSuffix(name, ".zip") {
+		return name, "The release must not contain other ZIP files"
+	}
+
+	return "", ""
+}
+
+func init() {
+	AddTool(Release{})
+}
@@ -0,0 +1,102 @@
+package tool
+
+import (
+	"archive/zip"
+	"context"
+	"io/fs"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type releaseTestFile struct {
+	name    string
+	content string
+	mode    fs.FileMode
+}
+
+func createReleaseZip(t *testing.T, files []releaseTestFile) string {
+	t.Helper()
+
+	zipPath := path.Join(t.TempDir(), "release.zip")
+	f, err := os.Create(zipPath)
+	assert.NoError(t, err)
+
+	w := zip.NewWriter(f)
+
+	for _, file := range files {
+		header := &zip.FileHeader{Name: file.name, Method: zip.Deflate}
+		header.SetMode(file.mode)
+
+		writer, err := w.CreateHeader(header)
+		assert.NoError(t, err)
+
+		_, err = writer.Write([]byte(file.content))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, w.Close())
+	assert.NoError(t, f.Close())
+
+	return zipPath
+}
+
+func releaseCheck(t *testing.T, config ToolConfig) []string {
+	t.Helper()
+
+	check := NewCheck()
+	assert.NoError(t, Release{}.Check(context.Background(), check, config))
+
+	var results []string
+	for _, r := range check.Results {
+		results = append(results, r.Identifier+" "+r.Path)
+	}
+
+	return results
+}
+
+func TestReleaseValidZip(t *testing.T) {
+	zipPath := createReleaseZip(t, []releaseTestFile{
+		{name: "FroshTools/composer.json", content: "{}", mode: 0644},
+		{name: "FroshTools/src/FroshTools.php", content: "<?php", mode: 0644},
+		{name: "FroshTools/vendor/foo/bar/tests/BarTest.php", content: "<?php", mode: 0644},
+	})
+
+	assert.Empty(t, releaseCheck(t, ToolConfig{ArchivePath: zipPath, Verifier: defaultVerifierConfig()}))
+}
+
+func TestReleaseInvalidZip(t *testing.T) {
+	zipPath := createReleaseZip(t, []releaseTestFile{
+		{name: "FroshTools/composer.json", content: "{}", mode: 0644},
+		{name: "FroshTools/.git/config", content: "", mode: 0644},
+		{name: "FroshTools/.git/HEAD", content: "", mode: 0644},
+		{name: "FroshTools/src/.DS_Store", content: "", mode: 0644},
+		{name: "FroshTools/tests/FooTest.php", content: "", mode: 0644},
+		{name: "FroshTools/auth.json", content: "{}", mode: 0644},
+		{name: "FroshTools/old.zip", content: "", mode: 0644},
+		{name: "FroshTools/bin/console", content: "#!/bin/php", mode: 0755},
+		{name: "FroshTools/link", content: "../../etc/passwd", mode: 0777 | fs.ModeSymlink},
+		{name: "FroshTools/sibling", content: "../OtherDir/config.php", mode: 0777 | fs.ModeSymlink},
+		{name: "FroshTools/src/inside", content: "../composer.json", mode: 0777 | fs.ModeSymlink},
+		{name: "FroshTools/big.bin", content: "0123456789ABCDEF", mode: 0644},
+		{name: "README.md", content: "", mode: 0644},
+	})
+
+	config := ToolConfig{ArchivePath: zipPath, Verifier: VerifierConfig{Release: ReleaseConfig{MaxFileSize: 12, MaxTotalSize: 40}}}
+
+	assert.ElementsMatch(t, []string{
+		"release/top-level-folder ",
+		"release/total-size ",
+		"release/forbidden-file FroshTools/.git",
+		"release/forbidden-file FroshTools/src/.DS_Store",
+		"release/forbidden-file FroshTools/tests",
+		"release/forbidden-file FroshTools/auth.json",
+		"release/forbidden-file FroshTools/old.zip",
+		"release/executable FroshTools/bin/console",
+		"release/symlink FroshTools/link",
+		"release/symlink FroshTools/sibling",
+		"release/file-size FroshTools/big.bin",
+	}, releaseCheck(t, config))
+}
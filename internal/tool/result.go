@@ -81,4 +81,6 @@ type CheckResult struct {
 	Severity string `json:"severity"`
 
 	Identifier string `json:"identifier"`
+	// The changes in the unified diff format, for results of the format command
+	Diff string `json:"diff,omitempty"`
 }
@@ -0,0 +1,88 @@
+package tool
+
+import (
+	"sort"
+	"strings"
+)
+
+// RuleDescriptor describes the rule behind the results with its identifier.
+type RuleDescriptor struct {
+	Identifier  string `json:"identifier"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	// The Shopware versions the rule applies to, like >=6.7.0
+	Versions string `json:"versions,omitempty"`
+	// How the results are fixed: safe, needs-review or manual
+	FixSafety string        `json:"fixSafety,omitempty"`
+	Examples  []RuleExample `json:"examples,omitempty"`
+}
+
+// RuleExample shows code before and after the fix of the rule.
+type RuleExample struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// RuleProvider is implemented by tools which describe the rules of their results.
+type RuleProvider interface {
+	Rules() []RuleDescriptor
+}
+
+// GetRules returns the rules of all tools sorted by their identifier.
+func GetRules() []RuleDescriptor {
+	var rules []RuleDescriptor
+
+	for _, tool := range availableTools {
+		if provider, ok := tool.(RuleProvider); ok {
+			rules = append(rules, provider.Rules()...)
+		}
+	}
+
+	sort.Slice(rules, func(i, j int) bool {
+		return rules[i].Identifier < rules[j].Identifier
+	})
+
+	return rules
+}
+
+// FindRule returns the rule with the identifier. The identifier can be given without
+// the prefix of the tool, like sw-button for admintwiglinter/sw-button.
+func FindRule(identifier string) (RuleDescriptor, bool) {
+	var found []RuleDescriptor
+
+	for _, rule := range GetRules() {
+		if rule.Identifier == identifier {
+			return rule, true
+		}
+
+		if strings.HasSuffix(rule.Identifier, "/"+identifier) {
+			found = append(found, rule)
+		}
+	}
+
+	// A short identifier has to be unique
+	if len(found) == 1 {
+		return found[0], true
+	}
+
+	return RuleDescriptor{}, false
+}
+
+// RulesOf returns the rules of the results sorted by their identifier, results without known rule are skipped.
+func RulesOf(results []CheckResult) []RuleDescriptor {
+	identifiers := map[string]bool{}
+
+	for _, result := range results {
+		identifiers[result.Identifier] = true
+	}
+
+	var rules []RuleDescriptor
+
+	for _, rule := range GetRules() {
+		if identifiers[rule.Identifier] {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules
+}
@@ -0,0 +1,39 @@
+package tool
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindRule(t *testing.T) {
+	rule, ok := FindRule("admintwiglinter/sw-button")
+	assert.True(t, ok)
+	assert.Equal(t, "Migrate sw-button to mt-button", rule.Title)
+	assert.Equal(t, ">=6.7.0", rule.Versions)
+	assert.NotEmpty(t, rule.Examples)
+
+	// The prefix of the tool can be left out
+	short, ok := FindRule("sw-button")
+	assert.True(t, ok)
+	assert.Equal(t, rule, short)
+
+	_, ok = FindRule("unknown")
+	assert.False(t, ok)
+}
+
+func TestRulesOf(t *testing.T) {
+	rules := RulesOf([]CheckResult{
+		{Identifier: "admintwiglinter/vue-slot"},
+		{Identifier: "admintwiglinter/sw-button"},
+		{Identifier: "admintwiglinter/sw-button"},
+		{Identifier: "phpstan/unknown"},
+	})
+
+	var identifiers []string
+	for _, rule := range rules {
+		identifiers = append(identifiers, rule.Identifier)
+	}
+
+	assert.Equal(t, []string{"admintwiglinter/sw-button", "admintwiglinter/vue-slot"}, identifiers)
+}
@@ -0,0 +1,260 @@
+package tool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type secretPattern struct {
+	identifier string
+	name       string
+	regex      *regexp.Regexp
+}
+
+var secretPatterns = []secretPattern{
+	{identifier: "aws-access-key", name: "AWS access key", regex: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{identifier: "aws-secret-key", name: "AWS secret key", regex: regexp.MustCompile(`(?i)aws.{0,20}secret.{0,20}['"=:\s]([A-Za-z0-9/+]{40})\b`)},
+	{identifier: "stripe-key", name: "Stripe secret key", regex: regexp.MustCompile(`\b(?:sk|rk)_live_[0-9a-zA-Z]{24,}\b`)},
+	{identifier: "shopware-integration-key", name: "Shopware integration access key", regex: regexp.MustCompile(`\bSWIA[A-Z0-9]{22}\b`)},
+	{identifier: "private-key", name: "private key", regex: regexp.MustCompile(`-----BEGIN (?:RSA |EC |DSA |OPENSSH |ENCRYPTED |PGP )?PRIVATE KEY(?: BLOCK)?-----`)},
+}
+
+var (
+	appSecretRegex       = regexp.MustCompile(`^\s*(?:export\s+)?APP_SECRET\s*=\s*['"]?([^'"\s#]+)`)
+	secretCandidateRegex = regexp.MustCompile(`['"=:]\s*['"]?([A-Za-z0-9+/_\-]{32,}={0,2})`)
+	secretSkipDirs       = []string{"vendor", "node_modules", "dist", ".git"}
+	secretSkipFiles      = []string{"composer.lock", "package-lock.json", "yarn.lock", "pnpm-lock.yaml"}
+	secretSkipExts       = []string{".map", ".png", ".jpg", ".jpeg", ".gif", ".webp", ".ico", ".svg", ".woff", ".woff2", ".ttf", ".eot", ".pdf", ".zip"}
+)
+
+const (
+	secretMaxFileSize     = 2 * MegaByte
+	secretEntropyMinimum  = 4.5
+	secretMinifiedLineLen = 1000
+)
+
+// Secrets scans the extension for credentials which should never be shipped.
+type Secrets struct{}
+
+func (s Secrets) Name() string {
+	return "secrets"
+}
+
+func (s Secrets) Check(ctx context.Context, check *Check, config ToolConfig) error {
+	return filepath.WalkDir(config.RootDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			for _, skip := range secretSkipDirs {
+				if d.Name() == skip && p != config.RootDir {
+					return filepath.SkipDir
+				}
+			}
+
+			return nil
+		}
+
+		if !d.Type().IsRegular() || shouldSkipSecretFile(d.Name()) {
+			return nil
+		}
+
+		info, err := d.Info()
+
+		if err != nil {
+			return err
+		}
+
+		if ByteSize(info.Size()) > secretMaxFileSize {
+			return nil
+		}
+
+		content, err := os.ReadFile(p)
+
+		if err != nil {
+			return err
+		}
+
+		// Binary file
+		if bytes.IndexByte(content, 0) != -1 {
+			return nil
+		}
+
+		relativePath := strings.TrimPrefix(strings.TrimPrefix(p, "/private"), config.RootDir+"/")
+
+		findings, err := scanSecrets(d.Name(), content)
+
+		if err != nil {
+			return fmt.Errorf("failed to scan %s for secrets: %w", relativePath, err)
+		}
+
+		for _, finding := range findings {
+			finding.Path = relativePath
+			check.AddResult(finding)
+		}
+
+		return nil
+	})
+}
+
+func (s Secrets) Fix(ctx context.Context, config ToolConfig) error {
+	return nil
+}
+
+func (s Secrets) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
+	return nil
+}
+
+// scanSecrets returns a result for every line of content containing a possible secret.
+func scanSecrets(fileName string, content []byte) ([]CheckResult, error) {
+	var results []CheckResult
+
+	isEnvFile := fileName == ".env" || strings.HasPrefix(fileName, ".env.")
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	// The whole content fits into the buffer, so even a file consisting of a single line is scanned completely
+	scanner.Buffer(make([]byte, 0, 64*1024), max(len(content)+1, bufio.MaxScanTokenSize))
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		found := false
+
+		for _, pattern := range secretPatterns {
+			match := pattern.regex.FindStringSubmatch(line)
+
+			if match == nil {
+				continue
+			}
+
+			secret := match[0]
+			if len(match) > 1 && match[1] != "" {
+				secret = match[1]
+			}
+
+			results = append(results, CheckResult{
+				Line:       lineNumber,
+				Message:    fmt.Sprintf("Possible %s found: %s", pattern.name, redactSecret(secret)),
+				Severity:   "error",
+				Identifier: "secrets/" + pattern.identifier,
+			})
+			found = true
+		}
+
+		if isEnvFile && !strings.HasSuffix(fileName, ".dist") && !strings.HasSuffix(fileName, ".example") {
+			if match := appSecretRegex.FindStringSubmatch(line); match != nil {
+				results = append(results, CheckResult{
+					Line:       lineNumber,
+					Message:    fmt.Sprintf("APP_SECRET is committed in %s: %s", fileName, redactSecret(match[1])),
+					Severity:   "error",
+					Identifier: "secrets/app-secret",
+				})
+				found = true
+			}
+		}
+
+		// Minified files consist of long lines full of random looking identifiers
+		if found || len(line) > secretMinifiedLineLen {
+			continue
+		}
+
+		for _, match := range secretCandidateRegex.FindAllStringSubmatch(line, -1) {
+			if !looksLikeSecret(match[1]) {
+				continue
+			}
+
+			results = append(results, CheckResult{
+				Line:       lineNumber,
+				Message:    fmt.Sprintf("Possible secret with high entropy found: %s", redactSecret(match[1])),
+				Severity:   "warning",
+				Identifier: "secrets/high-entropy",
+			})
+
+			break
+		}
+	}
+
+	return results, scanner.Err()
+}
+
+func shouldSkipSecretFile(name string) bool {
+	for _, skip := range secretSkipFiles {
+		if name == skip {
+			return true
+		}
+	}
+
+	for _, ext := range secretSkipExts {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+
+	return strings.HasSuffix(name, ".min.js") || strings.HasSuffix(name, ".min.css")
+}
+
+// looksLikeSecret checks a candidate for mixed character classes and a high shannon entropy.
+func looksLikeSecret(candidate string) bool {
+	var hasUpper, hasLower, hasDigit bool
+
+	for _, r := range candidate {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		}
+	}
+
+	// Paths, class names and hex hashes don't mix all character classes
+	if !hasUpper || !hasLower || !hasDigit || strings.Count(candidate, "/") > 2 {
+		return false
+	}
+
+	return shannonEntropy(candidate) >= secretEntropyMinimum
+}
+
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	frequencies := map[rune]float64{}
+	for _, r := range s {
+		frequencies[r]++
+	}
+
+	entropy := 0.0
+	length := float64(len(s))
+
+	for _, count := range frequencies {
+		p := count / length
+		entropy -= p * math.Log2(p)
+	}
+
+	return entropy
+}
+
+// redactSecret keeps only the first and last characters of a secret for the report.
+func redactSecret(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+
+	return secret[:4] + strings.Repeat("*", len(secret)-6) + secret[len(secret)-2:]
+}
+
+func init() {
+	AddTool(Secrets{})
+}
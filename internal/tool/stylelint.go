@@ -3,7 +3,9 @@ package tool
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path"
@@ -156,14 +158,109 @@ func (s StyleLint) Fix(ctx context.Context, config ToolConfig) error {
 	return gr.Wait()
 }
 
-func (s StyleLint) Format(ctx context.Context, config ToolConfig, dryRun bool) error {
-	return nil
+func (s StyleLint) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
+	if !dryRun {
+		return s.Fix(ctx, config)
+	}
+
+	cwd, err := os.Getwd()
+
+	if err != nil {
+		return err
+	}
+
+	paths := append(config.StorefrontDirectories, config.AdminDirectories...)
+
+	var gr errgroup.Group
+
+	for _, p := range paths {
+		p := p
+
+		if !path.IsAbs(p) {
+			p = path.Join(cwd, p)
+		}
+
+		files, err := scssFiles(p)
+		if err != nil {
+			return err
+		}
+
+		if len(files) == 0 {
+			continue
+		}
+
+		gr.Go(func() error {
+			formatted, err := formatCopy(p, files, func(tmpDir string) error {
+				stylelint := exec.CommandContext(ctx, "node", path.Join(cwd, "tools", "js", "node_modules", ".bin", "stylelint"),
+					"--config", path.Join(cwd, "tools", "js", fmt.Sprintf("stylelint.config.%s.mjs", path.Base(p))),
+					"**/*.scss",
+					"--fix",
+				)
+				stylelint.Dir = tmpDir
+				stylelint.Stderr = os.Stderr
+
+				// The exit code 2 means that problems are left which cannot be fixed, Check reports them
+				if err := stylelint.Run(); err != nil {
+					var exitErr *exec.ExitError
+					if !errors.As(err, &exitErr) || exitErr.ExitCode() != 2 {
+						return err
+					}
+				}
+
+				return nil
+			})
+
+			if err != nil {
+				return err
+			}
+
+			return addFormatCopyDiffs(check, config, s.Name(), p, formatted)
+		})
+	}
+
+	return gr.Wait()
 }
 
 func init() {
 	AddTool(StyleLint{})
 }
 
+// scssFiles returns the SCSS files below dir relative to it, compiled and vendored files are skipped.
+func scssFiles(dir string) ([]string, error) {
+	var files []string
+
+	err := filepath.WalkDir(dir, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			switch d.Name() {
+			case "dist", "vendor", "node_modules":
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if filepath.Ext(file) == ".scss" {
+			relative, err := filepath.Rel(dir, file)
+			if err != nil {
+				return err
+			}
+
+			files = append(files, relative)
+		}
+
+		return nil
+	})
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return files, err
+}
+
 func hasSCSSFiles(dir string) (bool, error) {
 	matches, err := filepath.Glob(filepath.Join(dir, "**", "*.scss"))
 	if err != nil {
@@ -48,7 +48,7 @@ func (s SWCLI) Fix(ctx context.Context, config ToolConfig) error {
 	return nil
 }
 
-func (s SWCLI) Format(ctx context.Context, config ToolConfig, dryRun bool) error {
+func (s SWCLI) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
 	return nil
 }
 
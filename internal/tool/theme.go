@@ -0,0 +1,321 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// themeSchema lists the known top-level keys of a theme.json with their expected JSON type.
+var themeSchema = map[string]string{
+	"name":              "string",
+	"author":            "string",
+	"description":       "object",
+	"views":             "array",
+	"style":             "array",
+	"script":            "array",
+	"asset":             "array",
+	"previewMedia":      "string",
+	"iconSets":          "object",
+	"config":            "object",
+	"configInheritance": "array",
+}
+
+// themeFieldTypes are the config field types supported by the theme compiler and the
+// JSON type their value must have.
+var themeFieldTypes = map[string]string{
+	"color":      "string",
+	"fontFamily": "string",
+	"media":      "string",
+	"text":       "string",
+	"textarea":   "string",
+	"url":        "string",
+	"number":     "number",
+	"checkbox":   "boolean",
+	"switch":     "boolean",
+}
+
+var (
+	themeReferenceRegex = regexp.MustCompile(`^@[A-Z][A-Za-z0-9_]*$`)
+	themeFieldNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+)
+
+// Theme validates the theme.json of storefront themes.
+type Theme struct{}
+
+func (t Theme) Name() string {
+	return "theme"
+}
+
+func (t Theme) Check(ctx context.Context, check *Check, config ToolConfig) error {
+	for _, sourceDirectory := range config.SourceDirectories {
+		themeFile := path.Join(sourceDirectory, "Resources", "theme.json")
+
+		content, err := os.ReadFile(themeFile)
+
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		relativePath := strings.TrimPrefix(strings.TrimPrefix(themeFile, "/private"), config.RootDir+"/")
+
+		for _, result := range validateThemeJSON(path.Dir(themeFile), content) {
+			result.Path = relativePath
+			check.AddResult(result)
+		}
+	}
+
+	return nil
+}
+
+func (t Theme) Fix(ctx context.Context, config ToolConfig) error {
+	return nil
+}
+
+func (t Theme) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
+	return nil
+}
+
+// validateThemeJSON validates the content of a theme.json, file references are resolved relative to resourcesDir.
+func validateThemeJSON(resourcesDir string, content []byte) []CheckResult {
+	var theme map[string]any
+
+	if err := json.Unmarshal(content, &theme); err != nil {
+		return []CheckResult{{
+			Message:    fmt.Sprintf("theme.json is not valid JSON: %s", err),
+			Severity:   "error",
+			Identifier: "theme/invalid-json",
+		}}
+	}
+
+	v := themeValidator{resourcesDir: resourcesDir, content: string(content)}
+
+	v.validateSchema(theme)
+
+	name, _ := theme["name"].(string)
+
+	if views, ok := theme["views"].([]any); ok {
+		v.validateViews(name, views)
+	}
+
+	for _, key := range []string{"style", "script", "asset"} {
+		if entries, ok := theme[key].([]any); ok {
+			v.validateFileList(key, entries)
+		}
+	}
+
+	if preview, ok := theme["previewMedia"].(string); ok {
+		v.validateFile("previewMedia", preview)
+	}
+
+	if inheritance, ok := theme["configInheritance"].([]any); ok {
+		for _, entry := range inheritance {
+			if reference, ok := entry.(string); !ok || !themeReferenceRegex.MatchString(reference) {
+				v.add("theme/invalid-reference", "error", fmt.Sprintf("configInheritance entry %v must reference a theme like @Storefront", entry), fmt.Sprint(entry))
+			}
+		}
+	}
+
+	if cfg, ok := theme["config"].(map[string]any); ok {
+		if fields, ok := cfg["fields"].(map[string]any); ok {
+			v.validateFields(fields)
+		}
+	}
+
+	return v.results
+}
+
+type themeValidator struct {
+	resourcesDir string
+	content      string
+	results      []CheckResult
+}
+
+// add records a result, the line is guessed by the first occurrence of needle in the file.
+func (v *themeValidator) add(identifier, severity, message, needle string) {
+	line := 0
+
+	if idx := strings.Index(v.content, `"`+needle+`"`); needle != "" && idx != -1 {
+		line = strings.Count(v.content[:idx], "\n") + 1
+	}
+
+	v.results = append(v.results, CheckResult{
+		Line:       line,
+		Message:    message,
+		Severity:   severity,
+		Identifier: identifier,
+	})
+}
+
+func (v *themeValidator) validateSchema(theme map[string]any) {
+	if _, ok := theme["name"]; !ok {
+		v.add("theme/schema", "error", "theme.json requires a name", "")
+	}
+
+	keys := make([]string, 0, len(theme))
+	for key := range theme {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		expected, known := themeSchema[key]
+
+		if !known {
+			v.add("theme/schema", "warning", fmt.Sprintf("Unknown key %q in theme.json", key), key)
+			continue
+		}
+
+		if actual := jsonType(theme[key]); actual != expected {
+			v.add("theme/schema", "error", fmt.Sprintf("%s must be of type %s, got %s", key, expected, actual), key)
+		}
+	}
+}
+
+func (v *themeValidator) validateViews(name string, views []any) {
+	hasPlugins := false
+
+	for _, entry := range views {
+		reference, ok := entry.(string)
+
+		if !ok || !themeReferenceRegex.MatchString(reference) {
+			v.add("theme/invalid-reference", "error", fmt.Sprintf("views entry %v must reference a bundle like @Storefront, @Plugins or @%s", entry, name), fmt.Sprint(entry))
+			continue
+		}
+
+		if reference == "@Plugins" {
+			hasPlugins = true
+		}
+	}
+
+	if !hasPlugins {
+		v.add("theme/invalid-reference", "warning", "views does not contain @Plugins, templates of other extensions will be ignored", "views")
+	}
+}
+
+func (v *themeValidator) validateFileList(key string, entries []any) {
+	for _, entry := range entries {
+		file, ok := entry.(string)
+
+		if !ok {
+			// Styles can be configured as object with a resolve mapping
+			if _, isObject := entry.(map[string]any); key == "style" && isObject {
+				continue
+			}
+
+			v.add("theme/schema", "error", fmt.Sprintf("%s entries must be strings, got %s", key, jsonType(entry)), key)
+			continue
+		}
+
+		if strings.HasPrefix(file, "@") {
+			if !themeReferenceRegex.MatchString(file) {
+				v.add("theme/invalid-reference", "error", fmt.Sprintf("%s entry %q is not a valid bundle reference like @Storefront", key, file), file)
+			}
+
+			continue
+		}
+
+		v.validateFile(key, file)
+	}
+}
+
+func (v *themeValidator) validateFile(key, file string) {
+	if _, err := os.Stat(path.Join(v.resourcesDir, file)); os.IsNotExist(err) {
+		v.add("theme/missing-file", "error", fmt.Sprintf("%s references %q, which does not exist", key, file), file)
+	}
+}
+
+func (v *themeValidator) validateFields(fields map[string]any) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		field, ok := fields[name].(map[string]any)
+
+		if !ok {
+			v.add("theme/invalid-field", "error", fmt.Sprintf("Field %s must be an object", name), name)
+			continue
+		}
+
+		if !themeFieldNameRegex.MatchString(name) {
+			v.add("theme/invalid-field", "error", fmt.Sprintf("Field name %s cannot be used as SCSS variable", name), name)
+		}
+
+		if editable, exists := field["editable"]; exists {
+			if _, isBool := editable.(bool); !isBool {
+				v.add("theme/invalid-field", "error", fmt.Sprintf("editable of field %s must be a boolean, got %s", name, jsonType(editable)), name)
+			}
+		}
+
+		if scss, exists := field["scss"]; exists {
+			if _, isBool := scss.(bool); !isBool {
+				v.add("theme/invalid-field", "error", fmt.Sprintf("scss of field %s must be a boolean, got %s", name, jsonType(scss)), name)
+			}
+		}
+
+		fieldType, hasType := field["type"].(string)
+
+		if !hasType {
+			continue
+		}
+
+		expectedValue, known := themeFieldTypes[fieldType]
+
+		if !known {
+			v.add("theme/invalid-field", "error", fmt.Sprintf("Field %s has unsupported type %q", name, fieldType), name)
+			continue
+		}
+
+		value, hasValue := field["value"]
+
+		if !hasValue || value == nil {
+			continue
+		}
+
+		actual := jsonType(value)
+
+		// The compiler casts numeric strings for number fields
+		if expectedValue == "number" && actual == "string" {
+			continue
+		}
+
+		if actual != expectedValue {
+			v.add("theme/invalid-field", "error", fmt.Sprintf("Field %s of type %s must have a %s value, got %s", name, fieldType, expectedValue, actual), name)
+		}
+	}
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	case nil:
+		return "null"
+	}
+
+	return "unknown"
+}
+
+func init() {
+	AddTool(Theme{})
+}
@@ -0,0 +1,64 @@
+package tool
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateThemeJSON(t *testing.T) {
+	resources := t.TempDir()
+
+	assert.NoError(t, os.MkdirAll(path.Join(resources, "app", "storefront", "src", "scss"), 0755))
+	assert.NoError(t, os.WriteFile(path.Join(resources, "app", "storefront", "src", "scss", "base.scss"), []byte(""), 0644))
+
+	valid := `{
+  "name": "FroshTheme",
+  "author": "FriendsOfShopware",
+  "views": ["@Storefront", "@Plugins", "@FroshTheme"],
+  "style": ["app/storefront/src/scss/base.scss", "@Storefront"],
+  "script": ["@Storefront"],
+  "config": {
+    "fields": {
+      "sw-color-brand-primary": {"type": "color", "value": "#008490", "editable": true},
+      "frosh-spacing": {"type": "number", "value": "12"}
+    }
+  }
+}`
+
+	assert.Empty(t, validateThemeJSON(resources, []byte(valid)))
+
+	invalid := `{
+  "name": "FroshTheme",
+  "unknown": true,
+  "views": ["@storefront", "@FroshTheme"],
+  "style": ["app/storefront/src/scss/missing.scss"],
+  "previewMedia": "preview.jpg",
+  "config": {
+    "fields": {
+      "frosh-color": {"type": "colour", "value": "#fff"},
+      "frosh-enabled": {"type": "switch", "value": "true", "editable": "false"}
+    }
+  }
+}`
+
+	var found []string
+	for _, r := range validateThemeJSON(resources, []byte(invalid)) {
+		found = append(found, r.Identifier)
+	}
+
+	assert.Equal(t, []string{
+		"theme/schema",
+		"theme/invalid-reference",
+		"theme/invalid-reference",
+		"theme/missing-file",
+		"theme/missing-file",
+		"theme/invalid-field",
+		"theme/invalid-field",
+		"theme/invalid-field",
+	}, found)
+
+	assert.Equal(t, "theme/invalid-json", validateThemeJSON(resources, []byte("{"))[0].Identifier)
+}
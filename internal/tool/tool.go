@@ -33,6 +33,10 @@ type ToolConfig struct {
 	AdminDirectories []string
 	// Contains a list of directories that are considered as storefront code
 	StorefrontDirectories []string
+	// The path to the release ZIP, when a packaged extension is checked
+	ArchivePath string
+	// Settings of the verifier itself
+	Verifier VerifierConfig
 
 	Extension extension.Extension
 }
@@ -47,5 +51,7 @@ type Tool interface {
 	Name() string
 	Check(ctx context.Context, check *Check, config ToolConfig) error
 	Fix(ctx context.Context, config ToolConfig) error
-	Format(ctx context.Context, config ToolConfig, dryRun bool) error
+	// Format formats the files in place. With dryRun nothing is written, the files which
+	// would change are added to check as format/<tool> results with their diff.
+	Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error
 }
@@ -0,0 +1,345 @@
+package tool
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/shopware/extension-verifier/internal/twig"
+)
+
+var (
+	hrefValueRegex   = regexp.MustCompile(`(?i)\shref\s*=\s*["']?$`)
+	targetBlankRegex = regexp.MustCompile(`(?is)<[a-z][a-z0-9-]*\s[^>]*?\btarget\s*=\s*["']_blank["'][^>]*>`)
+	relNoopenerRegex = regexp.MustCompile(`(?is)\brel\s*=\s*["'][^"']*(\bnoopener\b|\bnoreferrer\b|{{)`)
+	// The errors of the twig parser name the line of the tag or expression
+	twigErrorLineRegex = regexp.MustCompile(`\bline (\d+)`)
+)
+
+// urlSafeFunctions generate URLs of the shop itself, their scheme cannot be controlled by user input.
+var urlSafeFunctions = map[string]bool{
+	"path":   true,
+	"url":    true,
+	"seoUrl": true,
+	"asset":  true,
+}
+
+// TwigSecurity checks the storefront templates for output which is not escaped for its context.
+type TwigSecurity struct{}
+
+func (t TwigSecurity) Name() string {
+	return "twig-security"
+}
+
+func (t TwigSecurity) Check(ctx context.Context, check *Check, config ToolConfig) error {
+	for _, sourceDirectory := range config.SourceDirectories {
+		twigFolder := path.Join(sourceDirectory, "Resources", "views", "storefront")
+
+		if _, err := os.Stat(twigFolder); os.IsNotExist(err) {
+			continue
+		}
+
+		err := filepath.Walk(twigFolder, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() || filepath.Ext(file) != ".twig" {
+				return nil
+			}
+
+			content, err := os.ReadFile(file)
+
+			if err != nil {
+				return err
+			}
+
+			relativePath := strings.TrimPrefix(strings.TrimPrefix(file, "/private"), config.RootDir+"/")
+
+			results, err := checkTwigSecurity(string(content))
+
+			// A template which cannot be parsed is reported, the other templates are still checked
+			if err != nil {
+				line := 0
+
+				if match := twigErrorLineRegex.FindStringSubmatch(err.Error()); match != nil {
+					line, _ = strconv.Atoi(match[1])
+				}
+
+				check.AddResult(CheckResult{
+					Path:       relativePath,
+					Line:       line,
+					Message:    fmt.Sprintf("Cannot parse template: %s", err),
+					Severity:   "error",
+					Identifier: "twig-security/parse-error",
+				})
+
+				return nil
+			}
+
+			for _, result := range results {
+				result.Path = relativePath
+				check.AddResult(result)
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t TwigSecurity) Fix(ctx context.Context, config ToolConfig) error {
+	return nil
+}
+
+func (t TwigSecurity) Format(ctx context.Context, check *Check, config ToolConfig, dryRun bool) error {
+	return nil
+}
+
+// checkTwigSecurity checks the content of a storefront template.
+func checkTwigSecurity(content string) ([]CheckResult, error) {
+	nodes, err := twig.ParseTemplate(content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var results []CheckResult
+
+	add := func(line int, identifier, message string) {
+		results = append(results, CheckResult{
+			Line:       line,
+			Message:    message,
+			Severity:   "warning",
+			Identifier: identifier,
+		})
+	}
+
+	err = nodes.WalkExpressions(func(node twig.Node, expr twig.Expr) {
+		twig.Inspect(expr, func(expr twig.Expr) bool {
+			if filter, ok := expr.(*twig.FilterExpr); ok && filter.Name == "raw" && !isSanitized(filter.Node) {
+				add(filter.Pos().Line, "twig-security/raw-output", fmt.Sprintf("%s is printed without escaping, pass it through sw_sanitize before using raw", filter.Node))
+			}
+			return true
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	scanner := securityScanner{add: add}
+
+	if err := scanner.scan(nodes); err != nil {
+		return nil, err
+	}
+
+	for _, match := range targetBlankRegex.FindAllStringIndex(content, -1) {
+		tag := content[match[0]:match[1]]
+
+		if !relNoopenerRegex.MatchString(tag) {
+			add(strings.Count(content[:match[0]], "\n")+1, "twig-security/target-blank", "Links with target=\"_blank\" must have rel=\"noopener\", otherwise the opened page can access the window of the shop")
+		}
+	}
+
+	return results, nil
+}
+
+const (
+	outsideScript = iota
+	inScriptTag
+	inScriptBody
+)
+
+// securityScanner walks the nodes in the order of the template and keeps track of the HTML context of printed expressions.
+type securityScanner struct {
+	add func(line int, identifier, message string)
+	// State of the inline script around the current node
+	script int
+	// Text directly before the current node
+	previous string
+}
+
+func (s *securityScanner) scan(nodes twig.NodeList) error {
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *twig.TextNode:
+			s.text(n.Text)
+			continue
+		case *twig.WhitespaceNode:
+			s.text(n.Text)
+			continue
+		case *twig.AutoescapeNode:
+			if n.Strategy == "false" {
+				s.add(n.Line, "twig-security/autoescape-disabled", "Autoescaping is disabled, all variables in this region are printed without escaping")
+			}
+		case *twig.PrintNode:
+			if err := s.print(n); err != nil {
+				return err
+			}
+		}
+
+		s.previous = ""
+
+		if container, ok := node.(twig.Container); ok {
+			for _, body := range container.Bodies() {
+				if err := s.scan(*body); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *securityScanner) text(text string) {
+	s.previous += text
+
+	lower := strings.ToLower(text)
+
+	for {
+		var index int
+
+		switch s.script {
+		case outsideScript:
+			if index = strings.Index(lower, "<script"); index != -1 {
+				s.script = inScriptTag
+				index += len("<script")
+			}
+		case inScriptTag:
+			if index = strings.Index(lower, ">"); index != -1 {
+				s.script = inScriptBody
+				index++
+			}
+		case inScriptBody:
+			if index = strings.Index(lower, "</script"); index != -1 {
+				s.script = outsideScript
+				index += len("</script")
+			}
+		}
+
+		if index == -1 {
+			return
+		}
+
+		lower = lower[index:]
+	}
+}
+
+func (s *securityScanner) print(node *twig.PrintNode) error {
+	expr, err := node.Expr()
+
+	if err != nil {
+		return fmt.Errorf("invalid expression on line %d: %w", node.Line, err)
+	}
+
+	_, base := filterChain(expr)
+
+	if s.script == inScriptBody && !isLiteral(base) && !isEscapedFor(expr, "js") {
+		s.add(node.Line, "twig-security/inline-script", fmt.Sprintf("%s is printed into an inline script, escape it with e('js') or pass it as data attribute", expr))
+	}
+
+	if hrefValueRegex.MatchString(s.previous) && !isSafeURL(expr) {
+		s.add(node.Line, "twig-security/unescaped-url", fmt.Sprintf("%s is used as href, which allows javascript: URLs. Use path(), url() or escape it with e('url')", expr))
+	}
+
+	return nil
+}
+
+// filterChain returns the filters applied to an expression from the outermost to the innermost
+// and the expression the filters are applied to.
+func filterChain(expr twig.Expr) ([]*twig.FilterExpr, twig.Expr) {
+	var filters []*twig.FilterExpr
+
+	for {
+		filter, ok := expr.(*twig.FilterExpr)
+
+		if !ok {
+			return filters, expr
+		}
+
+		filters = append(filters, filter)
+		expr = filter.Node
+	}
+}
+
+func isSanitized(expr twig.Expr) bool {
+	filters, base := filterChain(expr)
+
+	for _, filter := range filters {
+		if filter.Name == "sw_sanitize" {
+			return true
+		}
+	}
+
+	return isLiteral(base)
+}
+
+func isLiteral(expr twig.Expr) bool {
+	_, ok := expr.(*twig.LiteralExpr)
+	return ok
+}
+
+// isEscapedFor checks whether the expression is escaped with e or escape for the given strategy.
+func isEscapedFor(expr twig.Expr, strategy string) bool {
+	filters, _ := filterChain(expr)
+
+	for _, filter := range filters {
+		if filter.Name != "e" && filter.Name != "escape" || len(filter.Arguments) == 0 {
+			continue
+		}
+
+		if literal, ok := filter.Arguments[0].(*twig.LiteralExpr); ok && literal.Value == strategy {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSafeURL checks whether the scheme of the URL cannot be controlled by a variable.
+func isSafeURL(expr twig.Expr) bool {
+	if isEscapedFor(expr, "url") {
+		return true
+	}
+
+	filters, base := filterChain(expr)
+
+	for _, filter := range filters {
+		if filter.Name == "url_encode" {
+			return true
+		}
+	}
+
+	// The start of a concatenation determines the scheme, like 'mailto:' ~ email
+	for {
+		binary, ok := base.(*twig.BinaryExpr)
+
+		if !ok || binary.Operator != "~" {
+			break
+		}
+
+		base = binary.Left
+	}
+
+	if function, ok := base.(*twig.FunctionExpr); ok {
+		return urlSafeFunctions[function.Name]
+	}
+
+	return isLiteral(base)
+}
+
+func init() {
+	AddTool(TwigSecurity{})
+}
@@ -0,0 +1,87 @@
+package tool
+
+import (
+	"context"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTwigSecurity(t *testing.T) {
+	safe := `{% sw_extends '@Storefront/storefront/base.html.twig' %}
+{% block base_content %}
+    {{ product.description|sw_sanitize|raw }}
+    {{ '<br>'|raw }}
+    {% autoescape 'html' %}{{ product.name }}{% endautoescape %}
+    <a href="{{ path('frontend.detail.page', { productId: product.id }) }}" target="_blank" rel="noopener noreferrer">{{ product.name }}</a>
+    <a href="mailto:{{ shop.email }}">{{ shop.email }}</a>
+    <a href="{{ 'tel:' ~ shop.phone }}" target='_blank' rel="{{ rel }}">{{ shop.phone }}</a>
+    <a href="{{ link|e('url') }}">Link</a>
+    <script src="{{ asset('bundles/app.js') }}"></script>
+    <script>window.productId = '{{ product.id|e('js') }}'; window.label = '{{ 'detail.label'|trans }}';</script>
+    <div data-product="{{ product.id }}"></div>
+{% endblock %}`
+
+	results, err := checkTwigSecurity(safe)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+
+	unsafe := `{% block base_content %}
+    {{ product.description|raw }}
+    {% autoescape false %}
+        {{ product.name }}
+    {% endautoescape %}
+    {% if product.link %}
+        <a href="{{ product.link }}"
+           target="_blank">Link</a>
+    {% endif %}
+    <script>
+        window.name = '{{ product.name }}';
+    </script>
+{% endblock %}`
+
+	results, err = checkTwigSecurity(unsafe)
+	assert.NoError(t, err)
+
+	var found []string
+	var lines []int
+	for _, r := range results {
+		found = append(found, r.Identifier)
+		lines = append(lines, r.Line)
+	}
+
+	assert.Equal(t, []string{
+		"twig-security/raw-output",
+		"twig-security/autoescape-disabled",
+		"twig-security/unescaped-url",
+		"twig-security/inline-script",
+		"twig-security/target-blank",
+	}, found)
+	assert.Equal(t, []int{2, 3, 7, 11, 7}, lines)
+}
+
+func TestCheckTwigSecurityInvalidExpression(t *testing.T) {
+	_, err := checkTwigSecurity(`{{ product.name| }}`)
+	assert.ErrorContains(t, err, "invalid expression on line 1")
+}
+
+func TestTwigSecurityReportsParseErrors(t *testing.T) {
+	root := t.TempDir()
+	twigFolder := path.Join(root, "src", "Resources", "views", "storefront")
+
+	assert.NoError(t, os.MkdirAll(twigFolder, 0755))
+	assert.NoError(t, os.WriteFile(path.Join(twigFolder, "broken.html.twig"), []byte("{% block a %}\n{{ product.name| }}\n{% endblock %}"), 0644))
+	assert.NoError(t, os.WriteFile(path.Join(twigFolder, "unsafe.html.twig"), []byte("{{ product.description|raw }}"), 0644))
+
+	check := NewCheck()
+	config := ToolConfig{RootDir: root, SourceDirectories: []string{path.Join(root, "src")}}
+	assert.NoError(t, TwigSecurity{}.Check(context.Background(), check, config))
+
+	assert.Len(t, check.Results, 2)
+	assert.Equal(t, "src/Resources/views/storefront/broken.html.twig", check.Results[0].Path)
+	assert.Equal(t, "twig-security/parse-error", check.Results[0].Identifier)
+	assert.Equal(t, 2, check.Results[0].Line)
+	assert.Equal(t, "twig-security/raw-output", check.Results[1].Identifier)
+}
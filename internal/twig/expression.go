@@ -0,0 +1,471 @@
+package twig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expr is a node of a Twig expression like product.name|upper.
+type Expr interface {
+	// Pos returns the position of the first token of the expression.
+	Pos() Position
+	// String returns the expression in canonical form.
+	String() string
+}
+
+// LiteralKind is the type of a literal.
+type LiteralKind string
+
+const (
+	LiteralString LiteralKind = "string"
+	LiteralNumber LiteralKind = "number"
+	LiteralBool   LiteralKind = "bool"
+	LiteralNull   LiteralKind = "null"
+)
+
+// LiteralExpr is a string, number, boolean or null literal. The value of a string is unquoted,
+// strings with interpolations are an InterpolationExpr.
+type LiteralExpr struct {
+	Position
+	Kind  LiteralKind
+	Value string
+}
+
+func (l *LiteralExpr) String() string {
+	if l.Kind == LiteralString {
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(l.Value) + "'"
+	}
+	return l.Value
+}
+
+// InterpolationExpr is a double quoted string with interpolated expressions like "Hello #{name}".
+// The parts are string literals and the interpolated expressions.
+type InterpolationExpr struct {
+	Position
+	Parts []Expr
+}
+
+func (i *InterpolationExpr) String() string {
+	var sb strings.Builder
+	sb.WriteString(`"`)
+	for _, part := range i.Parts {
+		if literal, ok := part.(*LiteralExpr); ok && literal.Kind == LiteralString {
+			sb.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`, `#{`, `\#{`).Replace(literal.Value))
+			continue
+		}
+		sb.WriteString("#{" + part.String() + "}")
+	}
+	sb.WriteString(`"`)
+	return sb.String()
+}
+
+// NameExpr is a variable like product.
+type NameExpr struct {
+	Position
+	Name string
+}
+
+func (n *NameExpr) String() string {
+	return n.Name
+}
+
+// AttributeExpr is the access of an attribute or method, product.name, product.getName() or product['name'].
+type AttributeExpr struct {
+	Position
+	Object Expr
+	// Name of the attribute with dot syntax
+	Name string
+	// Key of the subscript syntax, nil with dot syntax
+	Key Expr
+	// Arguments of a method call, nil when the attribute is not called
+	Arguments []Expr
+	// NullSafe is set for the null-safe operator product?.name
+	NullSafe bool
+}
+
+func (a *AttributeExpr) String() string {
+	object := wrapExpr(a.Object, primaryPrecedence)
+	if a.Key != nil {
+		return object + "[" + a.Key.String() + "]"
+	}
+	operator := "."
+	if a.NullSafe {
+		operator = "?."
+	}
+	if a.Arguments != nil {
+		return object + operator + a.Name + "(" + joinExprs(a.Arguments) + ")"
+	}
+	return object + operator + a.Name
+}
+
+// SliceExpr is the slice syntax items[start:length], either bound can be nil.
+type SliceExpr struct {
+	Position
+	Node   Expr
+	Start  Expr
+	Length Expr
+}
+
+func (s *SliceExpr) String() string {
+	var start, length string
+	if s.Start != nil {
+		start = s.Start.String()
+	}
+	if s.Length != nil {
+		length = s.Length.String()
+	}
+	return wrapExpr(s.Node, primaryPrecedence) + "[" + start + ":" + length + "]"
+}
+
+// FilterExpr applies a filter, like name|upper or price|format_currency('EUR').
+type FilterExpr struct {
+	Position
+	Node      Expr
+	Name      string
+	Arguments []Expr
+}
+
+func (f *FilterExpr) String() string {
+	filter := wrapExpr(f.Node, primaryPrecedence) + "|" + f.Name
+	if f.Arguments != nil {
+		filter += "(" + joinExprs(f.Arguments) + ")"
+	}
+	return filter
+}
+
+// FunctionExpr calls a function, like path('frontend.home.page').
+type FunctionExpr struct {
+	Position
+	Name      string
+	Arguments []Expr
+}
+
+func (f *FunctionExpr) String() string {
+	return f.Name + "(" + joinExprs(f.Arguments) + ")"
+}
+
+// NamedArgumentExpr is a named argument of a function, filter or method call like length: 10.
+type NamedArgumentExpr struct {
+	Position
+	Name  string
+	Value Expr
+}
+
+func (n *NamedArgumentExpr) String() string {
+	return n.Name + ": " + n.Value.String()
+}
+
+// TestExpr applies a test, like product is defined or count is not divisible by(3).
+type TestExpr struct {
+	Position
+	Node      Expr
+	Name      string
+	Not       bool
+	Arguments []Expr
+}
+
+func (t *TestExpr) String() string {
+	test := wrapExpr(t.Node, testPrecedence) + " is "
+	if t.Not {
+		test += "not "
+	}
+	test += t.Name
+	if t.Arguments != nil {
+		test += "(" + joinExprs(t.Arguments) + ")"
+	}
+	return test
+}
+
+// UnaryExpr is an expression with a unary operator: not, -, + or the spread operator ...
+type UnaryExpr struct {
+	Position
+	Operator string
+	Operand  Expr
+}
+
+func (u *UnaryExpr) String() string {
+	operator := u.Operator
+	if operator == "not" {
+		operator += " "
+	}
+	return operator + wrapExpr(u.Operand, unaryOperators[u.Operator])
+}
+
+// BinaryExpr is an expression with a binary operator like and, ==, ~ or ??.
+type BinaryExpr struct {
+	Position
+	Operator string
+	Left     Expr
+	Right    Expr
+}
+
+func (b *BinaryExpr) String() string {
+	precedence := binaryOperators[b.Operator].precedence
+	left, right := precedence, precedence+1
+	if binaryOperators[b.Operator].rightAssociative {
+		left, right = precedence+1, precedence
+	}
+	operator := " " + b.Operator + " "
+	// Ranges are written without spaces, 1..5
+	if b.Operator == ".." {
+		operator = b.Operator
+	}
+	return wrapExpr(b.Left, left) + operator + wrapExpr(b.Right, right)
+}
+
+// ConditionalExpr is the ternary operator a ? b : c and its short forms a ?: c and a ? b.
+type ConditionalExpr struct {
+	Position
+	Condition Expr
+	// Then is nil for a ?: c and Else is nil for a ? b
+	Then Expr
+	Else Expr
+}
+
+func (c *ConditionalExpr) String() string {
+	condition := wrapExpr(c.Condition, 1)
+	if c.Then == nil {
+		return condition + " ?: " + c.Else.String()
+	}
+	if c.Else == nil {
+		return condition + " ? " + c.Then.String()
+	}
+	return condition + " ? " + c.Then.String() + " : " + c.Else.String()
+}
+
+// ArrayExpr is an array literal like [1, 2, 3].
+type ArrayExpr struct {
+	Position
+	Elements []Expr
+}
+
+func (a *ArrayExpr) String() string {
+	return "[" + joinExprs(a.Elements) + "]"
+}
+
+// HashEntry is a key value pair of a hash. Keys written as plain names are string literals,
+// for the shorthand { name } the value is the variable of the same name. A spread ...other has no key.
+type HashEntry struct {
+	Key   Expr
+	Value Expr
+}
+
+// HashExpr is a hash literal like { name: 'Foo', (key): value }.
+type HashExpr struct {
+	Position
+	Entries []HashEntry
+}
+
+func (h *HashExpr) String() string {
+	var entries []string
+	for _, entry := range h.Entries {
+		// Spread of another hash
+		if entry.Key == nil {
+			entries = append(entries, entry.Value.String())
+			continue
+		}
+		key := entry.Key.String()
+		if literal, ok := entry.Key.(*LiteralExpr); ok && literal.Kind == LiteralString && isName(literal.Value) {
+			key = literal.Value
+		} else if _, ok := entry.Key.(*LiteralExpr); !ok {
+			key = "(" + key + ")"
+		}
+		entries = append(entries, key+": "+entry.Value.String())
+	}
+	return "{ " + strings.Join(entries, ", ") + " }"
+}
+
+// ArrowExpr is an arrow function like (key, value) => value * 2.
+type ArrowExpr struct {
+	Position
+	Parameters []string
+	Body       Expr
+}
+
+func (a *ArrowExpr) String() string {
+	parameters := strings.Join(a.Parameters, ", ")
+	if len(a.Parameters) != 1 {
+		parameters = "(" + parameters + ")"
+	}
+	return parameters + " => " + a.Body.String()
+}
+
+// Inspect traverses the expression depth-first and calls f for every expression.
+// The children of an expression are skipped when f returns false.
+func Inspect(expr Expr, f func(Expr) bool) {
+	if expr == nil || !f(expr) {
+		return
+	}
+
+	for _, child := range exprChildren(expr) {
+		Inspect(child, f)
+	}
+}
+
+func exprChildren(expr Expr) []Expr {
+	switch e := expr.(type) {
+	case *InterpolationExpr:
+		return e.Parts
+	case *AttributeExpr:
+		return append([]Expr{e.Object, e.Key}, e.Arguments...)
+	case *SliceExpr:
+		return []Expr{e.Node, e.Start, e.Length}
+	case *FilterExpr:
+		return append([]Expr{e.Node}, e.Arguments...)
+	case *FunctionExpr:
+		return e.Arguments
+	case *NamedArgumentExpr:
+		return []Expr{e.Value}
+	case *TestExpr:
+		return append([]Expr{e.Node}, e.Arguments...)
+	case *UnaryExpr:
+		return []Expr{e.Operand}
+	case *BinaryExpr:
+		return []Expr{e.Left, e.Right}
+	case *ConditionalExpr:
+		return []Expr{e.Condition, e.Then, e.Else}
+	case *ArrayExpr:
+		return e.Elements
+	case *HashExpr:
+		var children []Expr
+		for _, entry := range e.Entries {
+			children = append(children, entry.Key, entry.Value)
+		}
+		return children
+	case *ArrowExpr:
+		return []Expr{e.Body}
+	}
+	return nil
+}
+
+// exprPrecedence returns the precedence of the operator of an expression, operands with a lower
+// precedence than their operator have to be wrapped in parentheses.
+func exprPrecedence(expr Expr) int {
+	switch e := expr.(type) {
+	case *BinaryExpr:
+		return binaryOperators[e.Operator].precedence
+	case *UnaryExpr:
+		return unaryOperators[e.Operator]
+	case *TestExpr:
+		return testPrecedence
+	case *ConditionalExpr:
+		return 0
+	case *ArrowExpr:
+		return -1
+	}
+	return primaryPrecedence
+}
+
+func wrapExpr(expr Expr, precedence int) string {
+	if exprPrecedence(expr) < precedence {
+		return "(" + expr.String() + ")"
+	}
+	return expr.String()
+}
+
+func joinExprs(exprs []Expr) string {
+	var parts []string
+	for _, expr := range exprs {
+		parts = append(parts, expr.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+func isName(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		if !isNameChar(c) || (i == 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// Expr parses the printed expression.
+func (p *PrintNode) Expr() (Expr, error) {
+	pos, _ := p.positionOf(p.Expression, 2)
+	return parseExpressionAt(p.Expression, pos)
+}
+
+// ConditionExpr parses the condition of the branch, it is nil for the else branch.
+func (b *IfBranch) ConditionExpr() (Expr, error) {
+	if b.Condition == "" {
+		return nil, nil
+	}
+	// Skip the if or elseif keyword
+	pos, _ := b.positionOf(b.Condition, strings.Index(b.Raw, "if")+2)
+	return parseExpressionAt(b.Condition, pos)
+}
+
+// CollectionExpr parses the collection the loop iterates over.
+func (f *ForNode) CollectionExpr() (Expr, error) {
+	pos, _ := f.positionOf(f.Collection, strings.Index(f.Raw, " in "))
+	return parseExpressionAt(f.Collection, pos)
+}
+
+// ValueExprs parses the values of an inline assignment.
+func (s *SetNode) ValueExprs() ([]Expr, error) {
+	var exprs []Expr
+	from := strings.Index(s.Raw, "=")
+	for _, value := range s.Values {
+		var pos Position
+		pos, from = s.positionOf(value, from)
+		expr, err := parseExpressionAt(value, pos)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	return exprs, nil
+}
+
+// WalkExpressions parses the expressions of all print, set, if and for nodes and calls f with each of them.
+func (nl NodeList) WalkExpressions(f func(node Node, expr Expr)) error {
+	for _, node := range nl {
+		var exprs []Expr
+		var err error
+
+		switch n := node.(type) {
+		case *PrintNode:
+			var expr Expr
+			expr, err = n.Expr()
+			exprs = append(exprs, expr)
+		case *SetNode:
+			exprs, err = n.ValueExprs()
+		case *ForNode:
+			var expr Expr
+			expr, err = n.CollectionExpr()
+			exprs = append(exprs, expr)
+		case *IfNode:
+			for _, branch := range n.Branches {
+				var expr Expr
+				if expr, err = branch.ConditionExpr(); err != nil {
+					break
+				}
+				if expr != nil {
+					exprs = append(exprs, expr)
+				}
+			}
+		}
+
+		if err != nil {
+			return fmt.Errorf("invalid expression on line %d: %w", node.Pos().Line, err)
+		}
+
+		for _, expr := range exprs {
+			f(node, expr)
+		}
+
+		if container, ok := node.(Container); ok {
+			for _, body := range container.Bodies() {
+				if err := body.WalkExpressions(f); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,835 @@
+package twig
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	testPrecedence    = 100
+	primaryPrecedence = 1000
+)
+
+type binaryOperator struct {
+	precedence       int
+	rightAssociative bool
+}
+
+// binaryOperators and unaryOperators follow the precedence of the Twig 3 core extension.
+var binaryOperators = map[string]binaryOperator{
+	"or":          {precedence: 10},
+	"xor":         {precedence: 12},
+	"and":         {precedence: 15},
+	"b-or":        {precedence: 16},
+	"b-xor":       {precedence: 17},
+	"b-and":       {precedence: 18},
+	"==":          {precedence: 20},
+	"!=":          {precedence: 20},
+	"<=>":         {precedence: 20},
+	"<":           {precedence: 20},
+	">":           {precedence: 20},
+	">=":          {precedence: 20},
+	"<=":          {precedence: 20},
+	"not in":      {precedence: 20},
+	"in":          {precedence: 20},
+	"matches":     {precedence: 20},
+	"starts with": {precedence: 20},
+	"ends with":   {precedence: 20},
+	"has some":    {precedence: 20},
+	"has every":   {precedence: 20},
+	"..":          {precedence: 25},
+	"+":           {precedence: 30},
+	"-":           {precedence: 30},
+	"~":           {precedence: 40},
+	"*":           {precedence: 60},
+	"/":           {precedence: 60},
+	"//":          {precedence: 60},
+	"%":           {precedence: 60},
+	"**":          {precedence: 200, rightAssociative: true},
+	"??":          {precedence: 300, rightAssociative: true},
+}
+
+var unaryOperators = map[string]int{
+	"not": 50,
+	"-":   500,
+	"+":   500,
+	"...": 500,
+}
+
+// twoWordOperators are operators and tests which consist of two words, keyed by their first word.
+var twoWordOperators = map[string]string{
+	"not":    "in",
+	"starts": "with",
+	"ends":   "with",
+	"has":    "some every",
+}
+
+var twoWordTests = map[string]string{
+	"divisible": "by",
+	"same":      "as",
+}
+
+// punctuation is ordered by length, so the longest operator matches first.
+var punctuation = []string{
+	"...", "<=>",
+	"==", "!=", "<=", ">=", "//", "**", "..", "??", "?:", "?.", "=>",
+	"+", "-", "*", "/", "%", "~", "<", ">", "|", ".", ",", ":", "?", "=", "(", ")", "[", "]", "{", "}",
+}
+
+type exprTokenKind int
+
+const (
+	exprEOF exprTokenKind = iota
+	exprName
+	exprNumber
+	exprString
+	exprPunctuation
+)
+
+type exprToken struct {
+	kind   exprTokenKind
+	value  string
+	offset int
+	// Parts of a double quoted string with interpolations like "Hello #{name}"
+	parts []stringPart
+}
+
+// stringPart is a part of an interpolated string, either text or the source of an interpolated expression.
+type stringPart struct {
+	text string
+	// The text is the source of an expression starting at offset
+	expr   bool
+	offset int
+}
+
+type exprParser struct {
+	input  string
+	base   Position
+	tokens []exprToken
+	index  int
+}
+
+// ParseExpression parses a Twig expression like the content of {{ }}.
+func ParseExpression(input string) (Expr, error) {
+	return parseExpressionAt(input, Position{Line: 1, Column: 1})
+}
+
+// parseExpressionAt parses an expression which starts at base in the template.
+func parseExpressionAt(input string, base Position) (Expr, error) {
+	tokens, err := tokenizeExpression(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expressions of nodes which have not been parsed from a template
+	if base.Line == 0 {
+		base = Position{Line: 1, Column: 1}
+	}
+
+	p := &exprParser{input: input, base: base, tokens: tokens}
+	expr, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != exprEOF {
+		return nil, p.errorf(tok, "unexpected %q", tok.value)
+	}
+
+	return expr, nil
+}
+
+func tokenizeExpression(input string) ([]exprToken, error) {
+	var tokens []exprToken
+
+	for i := 0; i < len(input); {
+		c := input[i]
+
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(input) && (isDigit(input[i]) || input[i] == '_') {
+				i++
+			}
+			if i+1 < len(input) && input[i] == '.' && isDigit(input[i+1]) {
+				i++
+				for i < len(input) && (isDigit(input[i]) || input[i] == '_') {
+					i++
+				}
+			}
+			if i < len(input) && (input[i] == 'e' || input[i] == 'E') {
+				j := i + 1
+				if j < len(input) && (input[j] == '+' || input[j] == '-') {
+					j++
+				}
+				if j < len(input) && isDigit(input[j]) {
+					i = j
+					for i < len(input) && isDigit(input[i]) {
+						i++
+					}
+				}
+			}
+			tokens = append(tokens, exprToken{kind: exprNumber, value: input[start:i], offset: start})
+		case c == '\'' || c == '"':
+			tok, end, err := tokenizeString(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = end
+		case isNameChar(rune(c)) || c >= 0x80:
+			start := i
+			for i < len(input) && (isNameChar(rune(input[i])) || input[i] >= 0x80) {
+				i++
+			}
+			// Bitwise operators contain a dash
+			if input[start:i] == "b" {
+				for _, operator := range []string{"-and", "-xor", "-or"} {
+					if strings.HasPrefix(input[i:], operator) {
+						i += len(operator)
+						break
+					}
+				}
+			}
+			tokens = append(tokens, exprToken{kind: exprName, value: input[start:i], offset: start})
+		default:
+			matched := false
+			for _, operator := range punctuation {
+				if strings.HasPrefix(input[i:], operator) {
+					tokens = append(tokens, exprToken{kind: exprPunctuation, value: operator, offset: i})
+					i += len(operator)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at offset %d", c, i)
+			}
+		}
+	}
+
+	return append(tokens, exprToken{kind: exprEOF, offset: len(input)}), nil
+}
+
+// tokenizeString reads the string starting at start. Double quoted strings can interpolate expressions with #{},
+// their source is kept in the parts of the token.
+func tokenizeString(input string, start int) (exprToken, int, error) {
+	quote := input[start]
+	tok := exprToken{kind: exprString, offset: start}
+	var value strings.Builder
+
+	i := start + 1
+	for ; i < len(input) && input[i] != quote; i++ {
+		if input[i] == '\\' && i+1 < len(input) {
+			i++
+			value.WriteByte(input[i])
+			continue
+		}
+
+		if quote == '"' && input[i] == '#' && i+1 < len(input) && input[i+1] == '{' {
+			end, err := interpolationEnd(input, i+2)
+			if err != nil {
+				return tok, 0, err
+			}
+			tok.parts = append(tok.parts, stringPart{text: value.String()}, stringPart{text: input[i+2 : end], expr: true, offset: i + 2})
+			value.Reset()
+			i = end
+			continue
+		}
+
+		value.WriteByte(input[i])
+	}
+
+	if i >= len(input) {
+		return tok, 0, fmt.Errorf("unclosed string at offset %d", start)
+	}
+
+	if tok.parts != nil {
+		tok.parts = append(tok.parts, stringPart{text: value.String()})
+	} else {
+		tok.value = value.String()
+	}
+
+	return tok, i + 1, nil
+}
+
+// interpolationEnd returns the offset of the brace closing the interpolation starting at start.
+func interpolationEnd(input string, start int) (int, error) {
+	depth := 0
+
+	for i := start; i < len(input); i++ {
+		switch input[i] {
+		case '\'', '"':
+			_, end, err := tokenizeString(input, i)
+			if err != nil {
+				return 0, err
+			}
+			i = end - 1
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i, nil
+			}
+			depth--
+		}
+	}
+
+	return 0, fmt.Errorf("unclosed interpolation at offset %d", start-2)
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.index]
+}
+
+func (p *exprParser) peekAt(n int) exprToken {
+	if p.index+n >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.index+n]
+}
+
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.index]
+	if tok.kind != exprEOF {
+		p.index++
+	}
+	return tok
+}
+
+func (p *exprParser) is(value string) bool {
+	tok := p.peek()
+	return tok.kind == exprPunctuation && tok.value == value
+}
+
+func (p *exprParser) expect(value string) (exprToken, error) {
+	tok := p.next()
+	if tok.kind != exprPunctuation || tok.value != value {
+		return tok, p.errorf(tok, "expected %q, got %q", value, tok.value)
+	}
+	return tok, nil
+}
+
+func (p *exprParser) errorf(tok exprToken, format string, args ...any) error {
+	pos := p.position(tok.offset)
+	return fmt.Errorf("line %d column %d: %s", pos.Line, pos.Column, fmt.Sprintf(format, args...))
+}
+
+func (p *exprParser) position(offset int) Position {
+	return advance(p.base, p.input[:offset])
+}
+
+// binaryOperator returns the binary operator at the current token and the number of its tokens.
+func (p *exprParser) binaryOperator() (string, int) {
+	tok := p.peek()
+	if tok.kind != exprName && tok.kind != exprPunctuation {
+		return "", 0
+	}
+
+	if second, ok := twoWordOperators[tok.value]; ok && tok.kind == exprName {
+		next := p.peekAt(1)
+		if next.kind == exprName && strings.Contains(" "+second+" ", " "+next.value+" ") {
+			return tok.value + " " + next.value, 2
+		}
+	}
+
+	if tok.value == "is" && tok.kind == exprName {
+		return "is", 1
+	}
+
+	if _, ok := binaryOperators[tok.value]; ok {
+		return tok.value, 1
+	}
+
+	return "", 0
+}
+
+func (p *exprParser) parseExpression(precedence int) (Expr, error) {
+	if p.isArrow() {
+		return p.parseArrow()
+	}
+
+	var expr Expr
+	var err error
+
+	tok := p.peek()
+	if unary, ok := unaryOperators[tok.value]; ok && (tok.kind == exprPunctuation || tok.value == "not") {
+		p.next()
+		operand, err := p.parseExpression(unary)
+		if err != nil {
+			return nil, err
+		}
+		expr = &UnaryExpr{Position: p.position(tok.offset), Operator: tok.value, Operand: operand}
+	} else {
+		expr, err = p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		operator, length := p.binaryOperator()
+		if operator == "" {
+			break
+		}
+
+		if operator == "is" {
+			if testPrecedence < precedence {
+				break
+			}
+			p.next()
+			expr, err = p.parseTest(expr)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		binary := binaryOperators[operator]
+		if binary.precedence < precedence {
+			break
+		}
+
+		for range length {
+			p.next()
+		}
+
+		next := binary.precedence + 1
+		if binary.rightAssociative {
+			next = binary.precedence
+		}
+
+		right, err := p.parseExpression(next)
+		if err != nil {
+			return nil, err
+		}
+
+		expr = &BinaryExpr{Position: expr.Pos(), Operator: operator, Left: expr, Right: right}
+	}
+
+	if precedence == 0 {
+		return p.parseConditional(expr)
+	}
+
+	return expr, nil
+}
+
+func (p *exprParser) parseConditional(expr Expr) (Expr, error) {
+	for p.is("?") || p.is("?:") {
+		conditional := &ConditionalExpr{Position: expr.Pos(), Condition: expr}
+
+		if p.next().value == "?:" {
+			elseExpr, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			conditional.Else = elseExpr
+		} else {
+			then, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			conditional.Then = then
+
+			if p.is(":") {
+				p.next()
+				elseExpr, err := p.parseExpression(0)
+				if err != nil {
+					return nil, err
+				}
+				conditional.Else = elseExpr
+			}
+		}
+
+		expr = conditional
+	}
+
+	return expr, nil
+}
+
+func (p *exprParser) parseTest(node Expr) (Expr, error) {
+	test := &TestExpr{Position: node.Pos(), Node: node}
+
+	if p.peek().kind == exprName && p.peek().value == "not" {
+		p.next()
+		test.Not = true
+	}
+
+	tok := p.next()
+	if tok.kind != exprName {
+		return nil, p.errorf(tok, "expected test name, got %q", tok.value)
+	}
+	test.Name = tok.value
+
+	if second, ok := twoWordTests[tok.value]; ok && p.peek().kind == exprName && p.peek().value == second {
+		p.next()
+		test.Name += " " + second
+	}
+
+	if p.is("(") {
+		arguments, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+		test.Arguments = arguments
+	}
+
+	return test, nil
+}
+
+func (p *exprParser) parsePrimary() (Expr, error) {
+	tok := p.next()
+	pos := p.position(tok.offset)
+
+	var expr Expr
+
+	switch tok.kind {
+	case exprNumber:
+		expr = &LiteralExpr{Position: pos, Kind: LiteralNumber, Value: tok.value}
+	case exprString:
+		if tok.parts == nil {
+			expr = &LiteralExpr{Position: pos, Kind: LiteralString, Value: tok.value}
+			break
+		}
+		interpolation, err := p.parseInterpolation(pos, tok.parts)
+		if err != nil {
+			return nil, err
+		}
+		expr = interpolation
+	case exprName:
+		switch strings.ToLower(tok.value) {
+		case "true", "false":
+			expr = &LiteralExpr{Position: pos, Kind: LiteralBool, Value: strings.ToLower(tok.value)}
+		case "null", "none":
+			expr = &LiteralExpr{Position: pos, Kind: LiteralNull, Value: "null"}
+		default:
+			if p.is("(") {
+				arguments, err := p.parseArguments()
+				if err != nil {
+					return nil, err
+				}
+				expr = &FunctionExpr{Position: pos, Name: tok.value, Arguments: arguments}
+			} else {
+				expr = &NameExpr{Position: pos, Name: tok.value}
+			}
+		}
+	case exprPunctuation:
+		switch tok.value {
+		case "(":
+			inner, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			expr = inner
+		case "[":
+			array := &ArrayExpr{Position: pos, Elements: []Expr{}}
+			for !p.is("]") {
+				element, err := p.parseExpression(0)
+				if err != nil {
+					return nil, err
+				}
+				array.Elements = append(array.Elements, element)
+				if !p.is(",") {
+					break
+				}
+				p.next()
+			}
+			if _, err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			expr = array
+		case "{":
+			hash, err := p.parseHash(pos)
+			if err != nil {
+				return nil, err
+			}
+			expr = hash
+		default:
+			return nil, p.errorf(tok, "unexpected %q", tok.value)
+		}
+	default:
+		return nil, p.errorf(tok, "unexpected end of expression")
+	}
+
+	return p.parsePostfix(expr)
+}
+
+func (p *exprParser) parseInterpolation(pos Position, parts []stringPart) (Expr, error) {
+	interpolation := &InterpolationExpr{Position: pos}
+
+	for _, part := range parts {
+		if !part.expr {
+			if part.text != "" {
+				interpolation.Parts = append(interpolation.Parts, &LiteralExpr{Position: pos, Kind: LiteralString, Value: part.text})
+			}
+			continue
+		}
+
+		expr, err := parseExpressionAt(part.text, p.position(part.offset))
+		if err != nil {
+			return nil, err
+		}
+		interpolation.Parts = append(interpolation.Parts, expr)
+	}
+
+	return interpolation, nil
+}
+
+func (p *exprParser) parseHash(pos Position) (Expr, error) {
+	hash := &HashExpr{Position: pos, Entries: []HashEntry{}}
+
+	for !p.is("}") {
+		var entry HashEntry
+		tok := p.peek()
+
+		switch {
+		case tok.kind == exprName || tok.kind == exprString || tok.kind == exprNumber:
+			p.next()
+			kind := LiteralString
+			if tok.kind == exprNumber {
+				kind = LiteralNumber
+			}
+			entry.Key = &LiteralExpr{Position: p.position(tok.offset), Kind: kind, Value: tok.value}
+			// Shorthand syntax { name }
+			if tok.kind == exprName && (p.is(",") || p.is("}")) {
+				entry.Value = &NameExpr{Position: p.position(tok.offset), Name: tok.value}
+			}
+		case p.is("("):
+			p.next()
+			key, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			entry.Key = key
+		case p.is("..."):
+			spread, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			entry.Value = spread
+		default:
+			return nil, p.errorf(tok, "invalid hash key %q", tok.value)
+		}
+
+		if entry.Value == nil {
+			if _, err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			value, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			entry.Value = value
+		}
+
+		hash.Entries = append(hash.Entries, entry)
+
+		if !p.is(",") {
+			break
+		}
+		p.next()
+	}
+
+	if _, err := p.expect("}"); err != nil {
+		return nil, err
+	}
+
+	return hash, nil
+}
+
+func (p *exprParser) parsePostfix(expr Expr) (Expr, error) {
+	for {
+		tok := p.peek()
+		if tok.kind != exprPunctuation {
+			return expr, nil
+		}
+
+		pos := expr.Pos()
+
+		switch tok.value {
+		case ".", "?.":
+			p.next()
+			name := p.next()
+			if name.kind != exprName && name.kind != exprNumber {
+				return nil, p.errorf(name, "expected attribute name, got %q", name.value)
+			}
+			attribute := &AttributeExpr{Position: pos, Object: expr, Name: name.value, NullSafe: tok.value == "?."}
+			if p.is("(") {
+				arguments, err := p.parseArguments()
+				if err != nil {
+					return nil, err
+				}
+				attribute.Arguments = arguments
+			}
+			expr = attribute
+		case "[":
+			p.next()
+			var start, length Expr
+			var err error
+			if !p.is(":") {
+				start, err = p.parseExpression(0)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if p.is(":") {
+				p.next()
+				if !p.is("]") {
+					length, err = p.parseExpression(0)
+					if err != nil {
+						return nil, err
+					}
+				}
+				expr = &SliceExpr{Position: pos, Node: expr, Start: start, Length: length}
+			} else {
+				expr = &AttributeExpr{Position: pos, Object: expr, Key: start}
+			}
+			if _, err := p.expect("]"); err != nil {
+				return nil, err
+			}
+		case "|":
+			p.next()
+			name := p.next()
+			if name.kind != exprName {
+				return nil, p.errorf(name, "expected filter name, got %q", name.value)
+			}
+			filter := &FilterExpr{Position: pos, Node: expr, Name: name.value}
+			if p.is("(") {
+				arguments, err := p.parseArguments()
+				if err != nil {
+					return nil, err
+				}
+				filter.Arguments = arguments
+			}
+			expr = filter
+		default:
+			return expr, nil
+		}
+	}
+}
+
+// parseArguments parses the arguments of a call including the parentheses.
+func (p *exprParser) parseArguments() ([]Expr, error) {
+	if _, err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	arguments := []Expr{}
+
+	for !p.is(")") {
+		tok := p.peek()
+		next := p.peekAt(1)
+
+		var argument Expr
+		var err error
+
+		if tok.kind == exprName && next.kind == exprPunctuation && (next.value == ":" || next.value == "=") {
+			p.next()
+			p.next()
+			value, err := p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+			argument = &NamedArgumentExpr{Position: p.position(tok.offset), Name: tok.value, Value: value}
+		} else {
+			argument, err = p.parseExpression(0)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		arguments = append(arguments, argument)
+
+		if !p.is(",") {
+			break
+		}
+		p.next()
+	}
+
+	if _, err := p.expect(")"); err != nil {
+		return nil, err
+	}
+
+	return arguments, nil
+}
+
+// isArrow reports whether an arrow function like x => x or (a, b) => a starts at the current token.
+func (p *exprParser) isArrow() bool {
+	tok := p.peek()
+	if tok.kind == exprName {
+		next := p.peekAt(1)
+		return next.kind == exprPunctuation && next.value == "=>"
+	}
+
+	if !p.is("(") {
+		return false
+	}
+
+	for i := 1; ; i += 2 {
+		name := p.peekAt(i)
+		if name.kind == exprPunctuation && name.value == ")" && i == 1 {
+			arrow := p.peekAt(i + 1)
+			return arrow.kind == exprPunctuation && arrow.value == "=>"
+		}
+		if name.kind != exprName {
+			return false
+		}
+		separator := p.peekAt(i + 1)
+		if separator.kind != exprPunctuation {
+			return false
+		}
+		if separator.value == ")" {
+			arrow := p.peekAt(i + 2)
+			return arrow.kind == exprPunctuation && arrow.value == "=>"
+		}
+		if separator.value != "," {
+			return false
+		}
+	}
+}
+
+func (p *exprParser) parseArrow() (Expr, error) {
+	arrow := &ArrowExpr{Position: p.position(p.peek().offset), Parameters: []string{}}
+
+	if p.is("(") {
+		p.next()
+		for !p.is(")") {
+			arrow.Parameters = append(arrow.Parameters, p.next().value)
+			if p.is(",") {
+				p.next()
+			}
+		}
+		p.next()
+	} else {
+		arrow.Parameters = append(arrow.Parameters, p.next().value)
+	}
+
+	if _, err := p.expect("=>"); err != nil {
+		return nil, err
+	}
+
+	body, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	arrow.Body = body
+
+	return arrow, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameChar(c rune) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,145 @@
+package twig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExpression(t *testing.T) {
+	testcases := []struct {
+		input    string
+		expected string
+	}{
+		{input: `product.translated.name|striptags|u.truncate(30, '…')`, expected: `product.translated.name|striptags|u.truncate(30, '…')`},
+		{input: `"Hello"`, expected: `'Hello'`},
+		{input: `1 + 2 * 3`, expected: `1 + 2 * 3`},
+		{input: `(1 + 2) * 3`, expected: `(1 + 2) * 3`},
+		{input: `2 ** 3 ** 2`, expected: `2 ** 3 ** 2`},
+		{input: `not a and b or c`, expected: `not a and b or c`},
+		{input: `not (a and b)`, expected: `not (a and b)`},
+		{input: `-1|abs`, expected: `-1|abs`},
+		{input: `a ~ b ~ c`, expected: `a ~ b ~ c`},
+		{input: `user.name ?? 'Anonymous'`, expected: `user.name ?? 'Anonymous'`},
+		{input: `a ? b : c ? d : e`, expected: `a ? b : c ? d : e`},
+		{input: `a ?: b`, expected: `a ?: b`},
+		{input: `item not in ['a', "b"]`, expected: `item not in ['a', 'b']`},
+		{input: `name starts with 'sw'`, expected: `name starts with 'sw'`},
+		{input: `value is not same as(false)`, expected: `value is not same as(false)`},
+		{input: `loop.index is divisible by(3)`, expected: `loop.index is divisible by(3)`},
+		{input: `items is empty or items|length > 2`, expected: `items is empty or items|length > 2`},
+		{input: `{ 'data-id': id, name, (key): value, 1: true }`, expected: `{ 'data-id': id, name: name, (key): value, 1: true }`},
+		{input: `items|filter(item => item.active)|map((key, value) => key ~ value)`, expected: `items|filter(item => item.active)|map((key, value) => key ~ value)`},
+		{input: `path('frontend.detail.page', { productId: product.id })`, expected: `path('frontend.detail.page', { productId: product.id })`},
+		{input: `items[1:2]`, expected: `items[1:2]`},
+		{input: `items[:2]`, expected: `items[:2]`},
+		{input: `product['name']`, expected: `product['name']`},
+		{input: `1..5`, expected: `1..5`},
+		{input: `a b-and b`, expected: `a b-and b`},
+		{input: `[...a, 1]`, expected: `[...a, 1]`},
+		{input: `"a"|format_date(pattern: 'short', locale = 'en')`, expected: `'a'|format_date(pattern: 'short', locale: 'en')`},
+		{input: `True and NULL`, expected: `true and null`},
+		{input: `"hello #{name}"`, expected: `"hello #{name}"`},
+		{input: `"#{ product.name|upper } costs #{price ~ ' €'}!"`, expected: `"#{product.name|upper} costs #{price ~ ' €'}!"`},
+		{input: `"\#{literal} \"quoted\""`, expected: `'#{literal} "quoted"'`},
+		{input: `'#{literal}'`, expected: `'#{literal}'`},
+		{input: `"#{ { a: "#{b}" }|json_encode }"`, expected: `"#{{ a: "#{b}" }|json_encode}"`},
+		{input: `product?.cover?.media.url`, expected: `product?.cover?.media.url`},
+		{input: `page?.getHeader()`, expected: `page?.getHeader()`},
+		{input: `a ? b.c : d`, expected: `a ? b.c : d`},
+	}
+
+	for _, tc := range testcases {
+		expr, err := ParseExpression(tc.input)
+		if assert.NoError(t, err, tc.input) {
+			assert.Equal(t, tc.expected, expr.String(), tc.input)
+		}
+	}
+}
+
+func TestParseExpressionTree(t *testing.T) {
+	expr, err := ParseExpression(`a.b|raw ~ c`)
+	assert.NoError(t, err)
+
+	binary, ok := expr.(*BinaryExpr)
+	assert.True(t, ok)
+	assert.Equal(t, "~", binary.Operator)
+
+	filter, ok := binary.Left.(*FilterExpr)
+	assert.True(t, ok)
+	assert.Equal(t, "raw", filter.Name)
+
+	attribute, ok := filter.Node.(*AttributeExpr)
+	assert.True(t, ok)
+	assert.Equal(t, "b", attribute.Name)
+	assert.Equal(t, &NameExpr{Position: Position{Offset: 0, Line: 1, Column: 1}, Name: "a"}, attribute.Object)
+
+	assert.Equal(t, Position{Offset: 10, Line: 1, Column: 11}, binary.Right.Pos())
+}
+
+func TestParseExpressionInterpolation(t *testing.T) {
+	expr, err := ParseExpression(`"a #{x|raw}"`)
+	assert.NoError(t, err)
+
+	interpolation, ok := expr.(*InterpolationExpr)
+	assert.True(t, ok)
+	assert.Len(t, interpolation.Parts, 2)
+	assert.Equal(t, &LiteralExpr{Position: Position{Offset: 0, Line: 1, Column: 1}, Kind: LiteralString, Value: "a "}, interpolation.Parts[0])
+
+	var filters []string
+	Inspect(expr, func(expr Expr) bool {
+		if filter, ok := expr.(*FilterExpr); ok {
+			filters = append(filters, filter.Name)
+			assert.Equal(t, Position{Offset: 5, Line: 1, Column: 6}, filter.Pos())
+		}
+		return true
+	})
+	assert.Equal(t, []string{"raw"}, filters)
+}
+
+func TestParseExpressionError(t *testing.T) {
+	for _, input := range []string{`a +`, `foo(`, `a b`, `'unclosed`, `{ a }}`, `a is`, `"#{a"`, `"#{a +}"`} {
+		_, err := ParseExpression(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestWalkExpressions(t *testing.T) {
+	template := `{% block content %}
+    {% if page.header.navigation is defined %}
+        {% for item in page.header.navigation.tree %}{{ item.name|raw }}{% endfor %}
+    {% endif %}
+    {% set description, count = product.description|sw_sanitize, 1 %}
+{% endblock %}`
+
+	nodes, err := ParseTemplate(template)
+	assert.NoError(t, err)
+
+	var filters []string
+	var positions []Position
+
+	err = nodes.WalkExpressions(func(node Node, expr Expr) {
+		Inspect(expr, func(expr Expr) bool {
+			if filter, ok := expr.(*FilterExpr); ok {
+				filters = append(filters, filter.Name)
+				positions = append(positions, filter.Pos())
+			}
+			return true
+		})
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"raw", "sw_sanitize"}, filters)
+	assert.Equal(t, []Position{{Offset: 123, Line: 3, Column: 57}, {Offset: 200, Line: 5, Column: 33}}, positions)
+
+	ifNode := nodes.FindBlock("content").Children.RemoveWhitespace()[0].(*IfNode)
+	condition, err := ifNode.Branches[0].ConditionExpr()
+	assert.NoError(t, err)
+	assert.Equal(t, Position{Offset: 30, Line: 2, Column: 11}, condition.Pos())
+	assert.IsType(t, &TestExpr{}, condition)
+
+	// The template parser keeps expressions as text, invalid ones are reported when they are parsed
+	broken, err := ParseTemplate(`{{ a + }}`)
+	assert.NoError(t, err)
+	assert.ErrorContains(t, broken.WalkExpressions(func(Node, Expr) {}), "invalid expression on line 1")
+}
@@ -0,0 +1,314 @@
+package twig
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shopware/extension-verifier/internal/diff"
+)
+
+// maxExtendsDepth limits how many sw_extends are followed to find a block of a core template.
+const maxExtendsDepth = 10
+
+// ReferenceKind is the tag a template uses to reference another template.
+type ReferenceKind string
+
+const (
+	ReferenceExtends ReferenceKind = "extends"
+	ReferenceInclude ReferenceKind = "include"
+	ReferenceEmbed   ReferenceKind = "embed"
+)
+
+// Reference of a template to another template.
+type Reference struct {
+	Kind     ReferenceKind `json:"kind"`
+	Template string        `json:"template"`
+	// Blocks of the referenced template which are overridden, empty for includes
+	Blocks []string `json:"blocks,omitempty"`
+}
+
+// References returns the templates referenced with extends, include and embed or their Shopware variants.
+// Includes of dynamic template expressions can't be resolved and are skipped.
+func (nl NodeList) References() []Reference {
+	references := nl.references()
+
+	for i := range references {
+		if references[i].Kind == ReferenceExtends {
+			references[i].Blocks = nl.BlockNames()
+		}
+	}
+
+	return references
+}
+
+func (nl NodeList) references() []Reference {
+	var references []Reference
+
+	for _, node := range nl {
+		switch n := node.(type) {
+		case *SwExtendsNode:
+			references = append(references, Reference{Kind: ReferenceExtends, Template: n.Template})
+		case *ExtendsNode:
+			if !n.Dynamic {
+				references = append(references, Reference{Kind: ReferenceExtends, Template: n.Template})
+			}
+		case *SwIncludeNode:
+			if !n.Dynamic {
+				references = append(references, Reference{Kind: ReferenceInclude, Template: n.Template})
+			}
+		case *IncludeNode:
+			if !n.Dynamic {
+				references = append(references, Reference{Kind: ReferenceInclude, Template: n.Template})
+			}
+		case *SwEmbedNode:
+			if !n.Dynamic {
+				references = append(references, Reference{Kind: ReferenceEmbed, Template: n.Template, Blocks: n.Children.BlockNames()})
+			}
+		case *EmbedNode:
+			if !n.Dynamic {
+				references = append(references, Reference{Kind: ReferenceEmbed, Template: n.Template, Blocks: n.Children.BlockNames()})
+			}
+		}
+
+		if container, ok := node.(Container); ok {
+			for _, body := range container.Bodies() {
+				references = append(references, body.references()...)
+			}
+		}
+	}
+
+	return references
+}
+
+// TemplateLoader returns the parsed core template of the given name like @Storefront/storefront/base.html.twig
+// or nil when the template does not exist.
+type TemplateLoader func(name string) (NodeList, error)
+
+// GraphTemplate is a template of the extension or a template referenced by it.
+type GraphTemplate struct {
+	Name string `json:"name"`
+	// File of an extension template relative to the extension root
+	File string `json:"file,omitempty"`
+	// Core is true for referenced templates which are not part of the extension
+	Core       bool        `json:"core"`
+	References []Reference `json:"references,omitempty"`
+	// Impact of the core changes, set by Analyze on affected extension templates
+	Impact *Impact `json:"impact,omitempty"`
+}
+
+// Impact of the core changes between two versions on an extension template.
+type Impact struct {
+	// Overridden blocks which have been changed or removed in the core
+	ChangedBlocks []string `json:"changed_blocks,omitempty"`
+	RemovedBlocks []string `json:"removed_blocks,omitempty"`
+	// Referenced core templates which have been removed
+	RemovedTemplates []string `json:"removed_templates,omitempty"`
+	// Lines added and removed in the changed blocks, to estimate the effort of the upgrade
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+}
+
+// Graph contains the templates of an extension and the templates they reference.
+type Graph struct {
+	Templates []*GraphTemplate `json:"templates"`
+}
+
+// NewGraph builds the graph of the extension templates. Referenced templates which are not
+// part of the extension are added as core templates.
+func NewGraph(templates []*GraphTemplate) *Graph {
+	byName := map[string]*GraphTemplate{}
+
+	for _, tpl := range templates {
+		byName[tpl.Name] = tpl
+	}
+
+	graph := &Graph{Templates: templates}
+
+	for _, tpl := range templates {
+		for _, reference := range tpl.References {
+			if _, ok := byName[reference.Template]; ok {
+				continue
+			}
+
+			core := &GraphTemplate{Name: reference.Template, Core: true}
+			byName[core.Name] = core
+			graph.Templates = append(graph.Templates, core)
+		}
+	}
+
+	sort.Slice(graph.Templates, func(i, j int) bool {
+		return graph.Templates[i].Name < graph.Templates[j].Name
+	})
+
+	return graph
+}
+
+// Analyze compares the core templates referenced by the extension templates between the old and
+// new version and sets the impact on every affected extension template.
+func (g *Graph) Analyze(oldCore, newCore TemplateLoader) error {
+	core := map[string]bool{}
+
+	for _, tpl := range g.Templates {
+		core[tpl.Name] = tpl.Core
+	}
+
+	for _, tpl := range g.Templates {
+		if tpl.Core {
+			continue
+		}
+
+		impact := &Impact{}
+
+		for _, reference := range tpl.References {
+			if !core[reference.Template] {
+				continue
+			}
+
+			if err := analyzeReference(impact, reference, oldCore, newCore); err != nil {
+				return fmt.Errorf("%s: %w", tpl.Name, err)
+			}
+		}
+
+		tpl.Impact = nil
+
+		if len(impact.ChangedBlocks) > 0 || len(impact.RemovedBlocks) > 0 || len(impact.RemovedTemplates) > 0 {
+			tpl.Impact = impact
+		}
+	}
+
+	return nil
+}
+
+// Affected returns the extension templates affected by the core changes found by Analyze.
+func (g *Graph) Affected() []*GraphTemplate {
+	var affected []*GraphTemplate
+
+	for _, tpl := range g.Templates {
+		if tpl.Impact != nil {
+			affected = append(affected, tpl)
+		}
+	}
+
+	return affected
+}
+
+// DOT renders the graph in the Graphviz DOT language. Core templates are dashed and affected templates red.
+func (g *Graph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph templates {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	for _, tpl := range g.Templates {
+		label := tpl.Name
+		var attributes []string
+
+		if tpl.Core {
+			attributes = append(attributes, "style=dashed")
+		}
+
+		if tpl.Impact != nil {
+			attributes = append(attributes, "color=red")
+			label += fmt.Sprintf("\n%d changed, %d removed blocks", len(tpl.Impact.ChangedBlocks), len(tpl.Impact.RemovedBlocks))
+
+			if len(tpl.Impact.RemovedTemplates) > 0 {
+				label += fmt.Sprintf("\n%d removed templates", len(tpl.Impact.RemovedTemplates))
+			}
+		}
+
+		attributes = append([]string{fmt.Sprintf("label=%q", label)}, attributes...)
+		sb.WriteString(fmt.Sprintf("  %q [%s];\n", tpl.Name, strings.Join(attributes, ", ")))
+	}
+
+	for _, tpl := range g.Templates {
+		for _, reference := range tpl.References {
+			sb.WriteString(fmt.Sprintf("  %q -> %q [label=%q];\n", tpl.Name, reference.Template, reference.Kind))
+		}
+	}
+
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+func analyzeReference(impact *Impact, reference Reference, oldCore, newCore TemplateLoader) error {
+	oldAst, err := oldCore(reference.Template)
+
+	if err != nil || oldAst == nil {
+		return err
+	}
+
+	newAst, err := newCore(reference.Template)
+
+	if err != nil {
+		return err
+	}
+
+	if newAst == nil {
+		impact.RemovedTemplates = append(impact.RemovedTemplates, reference.Template)
+		return nil
+	}
+
+	for _, name := range reference.Blocks {
+		oldBlock, err := lookupBlock(oldCore, reference.Template, name)
+
+		if err != nil {
+			return err
+		}
+
+		// Block of the extension itself
+		if oldBlock == nil {
+			continue
+		}
+
+		newBlock, err := lookupBlock(newCore, reference.Template, name)
+
+		if err != nil {
+			return err
+		}
+
+		if newBlock == nil {
+			impact.RemovedBlocks = append(impact.RemovedBlocks, name)
+			continue
+		}
+
+		oldContent, newContent := oldBlock.Children.Dump(), newBlock.Children.Dump()
+
+		if oldContent == newContent {
+			continue
+		}
+
+		added, removed := diff.Stat(oldContent, newContent)
+		impact.ChangedBlocks = append(impact.ChangedBlocks, name)
+		impact.Added += added
+		impact.Removed += removed
+	}
+
+	return nil
+}
+
+// lookupBlock finds a block in a core template or in the templates it extends.
+func lookupBlock(load TemplateLoader, template, name string) (*BlockNode, error) {
+	for depth := 0; depth < maxExtendsDepth; depth++ {
+		ast, err := load(template)
+
+		if err != nil || ast == nil {
+			return nil, err
+		}
+
+		if block := ast.FindBlock(name); block != nil {
+			return block, nil
+		}
+
+		extends := ast.Extends()
+
+		if extends == nil {
+			return nil, nil
+		}
+
+		template = extends.Template
+	}
+
+	return nil, nil
+}
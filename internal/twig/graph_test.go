@@ -0,0 +1,126 @@
+package twig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncludeParsing(t *testing.T) {
+	testcases := []struct {
+		template  string
+		name      string
+		dynamic   bool
+		arguments string
+	}{
+		{
+			template: `{% sw_include '@Storefront/storefront/component/buy-widget.html.twig' %}`,
+			name:     "@Storefront/storefront/component/buy-widget.html.twig",
+		},
+		{
+			template:  `{% sw_include "@Storefront/storefront/utilities/alert.html.twig" with { type: 'info' } only %}`,
+			name:      "@Storefront/storefront/utilities/alert.html.twig",
+			arguments: "with { type: 'info' } only",
+		},
+		{
+			template:  `{% sw_include '@Storefront/storefront/' ~ name ~ '.html.twig' ignore missing %}`,
+			name:      `'@Storefront/storefront/' ~ name ~ '.html.twig'`,
+			dynamic:   true,
+			arguments: "ignore missing",
+		},
+	}
+
+	for _, tc := range testcases {
+		nodes, err := ParseTemplate(tc.template)
+		assert.NoError(t, err)
+		assert.Len(t, nodes, 1)
+
+		include, ok := nodes[0].(*SwIncludeNode)
+		assert.True(t, ok)
+		assert.Equal(t, tc.name, include.Template)
+		assert.Equal(t, tc.dynamic, include.Dynamic)
+		assert.Equal(t, tc.arguments, include.Arguments)
+
+		assert.Equal(t, tc.template, nodes.Dump())
+	}
+}
+
+func TestReferences(t *testing.T) {
+	template := `{% sw_extends '@Storefront/storefront/page/product-detail/index.html.twig' %}
+{% block page_product_detail_buy %}
+    {% sw_include '@Storefront/storefront/component/delivery-information.html.twig' %}
+    {% sw_include name %}
+    {% sw_embed '@Storefront/storefront/component/card.html.twig' %}
+        {% block card_body %}Body{% endblock %}
+    {% endsw_embed %}
+{% endblock %}`
+
+	nodes, err := ParseTemplate(template)
+	assert.NoError(t, err)
+
+	assert.Equal(t, template, nodes.Dump())
+	assert.Equal(t, []Reference{
+		{Kind: ReferenceExtends, Template: "@Storefront/storefront/page/product-detail/index.html.twig", Blocks: []string{"page_product_detail_buy"}},
+		{Kind: ReferenceInclude, Template: "@Storefront/storefront/component/delivery-information.html.twig"},
+		{Kind: ReferenceEmbed, Template: "@Storefront/storefront/component/card.html.twig", Blocks: []string{"card_body"}},
+	}, nodes.References())
+}
+
+func TestGraphAnalyze(t *testing.T) {
+	oldCore := map[string]string{
+		"@Storefront/storefront/base.html.twig":   `{% block base_header %}<header></header>{% endblock %}{% block base_footer %}<footer></footer>{% endblock %}`,
+		"@Storefront/storefront/page/index.twig":  `{% sw_extends '@Storefront/storefront/base.html.twig' %}{% block page_content %}<main></main>{% endblock %}`,
+		"@Storefront/storefront/component/a.twig": `A`,
+	}
+	newCore := map[string]string{
+		"@Storefront/storefront/base.html.twig":  `{% block base_header %}<header class="header"></header>{% endblock %}`,
+		"@Storefront/storefront/page/index.twig": `{% sw_extends '@Storefront/storefront/base.html.twig' %}{% block page_content %}<main></main>{% endblock %}`,
+	}
+
+	loader := func(templates map[string]string) TemplateLoader {
+		return func(name string) (NodeList, error) {
+			content, ok := templates[name]
+			if !ok {
+				return nil, nil
+			}
+			return ParseTemplate(content)
+		}
+	}
+
+	page, err := ParseTemplate(`{% sw_extends '@Storefront/storefront/page/index.twig' %}
+{% block base_header %}<header></header>{% endblock %}
+{% block base_footer %}<footer></footer>{% endblock %}
+{% block page_content %}<main></main>{% endblock %}
+{% block my_block %}{% endblock %}`)
+	assert.NoError(t, err)
+
+	component, err := ParseTemplate(`{% sw_include '@Storefront/storefront/component/a.twig' %}{% sw_include '@MyExtension/storefront/page/index.twig' %}`)
+	assert.NoError(t, err)
+
+	graph := NewGraph([]*GraphTemplate{
+		{Name: "@MyExtension/storefront/page/index.twig", References: page.References()},
+		{Name: "@MyExtension/storefront/component/b.twig", References: component.References()},
+	})
+
+	assert.Len(t, graph.Templates, 4)
+	assert.True(t, graph.Templates[2].Core)
+	assert.Equal(t, "@Storefront/storefront/component/a.twig", graph.Templates[2].Name)
+
+	assert.NoError(t, graph.Analyze(loader(oldCore), loader(newCore)))
+
+	affected := graph.Affected()
+	assert.Len(t, affected, 2)
+
+	assert.Equal(t, "@MyExtension/storefront/component/b.twig", affected[0].Name)
+	assert.Equal(t, []string{"@Storefront/storefront/component/a.twig"}, affected[0].Impact.RemovedTemplates)
+
+	assert.Equal(t, "@MyExtension/storefront/page/index.twig", affected[1].Name)
+	assert.Equal(t, &Impact{
+		ChangedBlocks: []string{"base_header"},
+		RemovedBlocks: []string{"base_footer"},
+		Added:         1,
+		Removed:       1,
+	}, affected[1].Impact)
+
+	assert.Contains(t, graph.DOT(), `"@MyExtension/storefront/component/b.twig" -> "@MyExtension/storefront/page/index.twig" [label="include"];`)
+}
@@ -0,0 +1,94 @@
+package twig
+
+import "github.com/shopware/extension-verifier/internal/diff"
+
+// BlockMerge is the result of merging the core changes into one block of an extension template.
+type BlockMerge struct {
+	Name string
+	// Block of the extension template, its children are replaced when the merge was clean
+	Block *BlockNode
+	// Content of the block in the old core, new core and extension template
+	Old       string
+	New       string
+	Extension string
+	// Merged content, contains conflict markers when Conflict is true
+	Merged   string
+	Conflict bool
+}
+
+// MergeBlocks applies the changes between oldCore and newCore to the paired blocks of extension
+// with a three-way merge. The returned merges contain every block changed in the core.
+func MergeBlocks(oldCore, newCore, extension NodeList) []BlockMerge {
+	merges := PairBlocks(oldCore, newCore, extension)
+
+	for i := range merges {
+		merge := &merges[i]
+
+		var clean bool
+		merge.Merged, clean = diff.Merge3(merge.Old, merge.Extension, merge.New, "extension", "shopware")
+		merge.Conflict = !clean
+
+		if !clean {
+			continue
+		}
+
+		children, err := ParseTemplate(merge.Merged)
+
+		if err != nil {
+			merge.Conflict = true
+			continue
+		}
+
+		merge.Block.Children = children
+	}
+
+	return merges
+}
+
+// PairBlocks pairs the blocks of extension with the blocks of oldCore and newCore by name and returns
+// the ones which have been changed between both core versions. Blocks calling {{ parent() }} don't
+// copy the core markup and stay untouched, only their nested blocks are paired.
+func PairBlocks(oldCore, newCore, extension NodeList) []BlockMerge {
+	var pairs []BlockMerge
+
+	for _, node := range extension {
+		block, ok := node.(*BlockNode)
+
+		if !ok {
+			// Blocks can be nested in control structures, but the blocks of embedded templates belong to these
+			if container, ok := node.(Container); ok && !isEmbed(node) {
+				for _, body := range container.Bodies() {
+					pairs = append(pairs, PairBlocks(oldCore, newCore, *body)...)
+				}
+			}
+
+			continue
+		}
+
+		if block.CallsParent() {
+			pairs = append(pairs, PairBlocks(oldCore, newCore, block.Children)...)
+			continue
+		}
+
+		oldBlock := oldCore.FindBlock(block.Name)
+		newBlock := newCore.FindBlock(block.Name)
+
+		if oldBlock == nil || newBlock == nil {
+			continue
+		}
+
+		pair := BlockMerge{
+			Name:      block.Name,
+			Block:     block,
+			Old:       oldBlock.Children.Dump(),
+			New:       newBlock.Children.Dump(),
+			Extension: block.Children.Dump(),
+		}
+
+		if pair.Old != pair.New {
+			pairs = append(pairs, pair)
+		}
+	}
+
+	return pairs
+}
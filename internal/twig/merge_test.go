@@ -0,0 +1,91 @@
+package twig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeBlocks(t *testing.T) {
+	oldCore := `{% block buy_widget %}
+    <div class="buy-widget">
+        <span class="price">{{ price }}</span>
+    </div>
+{% endblock %}
+{% block buy_widget_footer %}<footer></footer>{% endblock %}`
+
+	newCore := `{% block buy_widget %}
+    <div class="buy-widget" role="region">
+        <span class="price">{{ price }}</span>
+    </div>
+{% endblock %}
+{% block buy_widget_footer %}<footer class="footer"></footer>{% endblock %}`
+
+	extension := `{% sw_extends '@Storefront/storefront/buy-widget.html.twig' %}
+{% block buy_widget %}
+    <div class="buy-widget">
+        <span class="price">{{ price }}</span>
+        <span class="badge">New</span>
+    </div>
+{% endblock %}
+{% block buy_widget_footer %}
+    {{ parent() }}
+{% endblock %}`
+
+	oldAst, err := ParseTemplate(oldCore)
+	assert.NoError(t, err)
+	newAst, err := ParseTemplate(newCore)
+	assert.NoError(t, err)
+	extAst, err := ParseTemplate(extension)
+	assert.NoError(t, err)
+
+	merges := MergeBlocks(oldAst, newAst, extAst)
+
+	assert.Len(t, merges, 1)
+	assert.Equal(t, "buy_widget", merges[0].Name)
+	assert.False(t, merges[0].Conflict)
+
+	assert.Equal(t, `{% sw_extends '@Storefront/storefront/buy-widget.html.twig' %}
+{% block buy_widget %}
+    <div class="buy-widget" role="region">
+        <span class="price">{{ price }}</span>
+        <span class="badge">New</span>
+    </div>
+{% endblock %}
+{% block buy_widget_footer %}
+    {{ parent() }}
+{% endblock %}`, extAst.Dump())
+}
+
+func TestMergeBlocksConflict(t *testing.T) {
+	oldAst, err := ParseTemplate(`{% block title %}<h1>{{ title }}</h1>{% endblock %}`)
+	assert.NoError(t, err)
+	newAst, err := ParseTemplate(`{% block title %}<h2>{{ title }}</h2>{% endblock %}`)
+	assert.NoError(t, err)
+	extAst, err := ParseTemplate(`{% block title %}<h1 class="custom">{{ title }}</h1>{% endblock %}`)
+	assert.NoError(t, err)
+
+	merges := MergeBlocks(oldAst, newAst, extAst)
+
+	assert.Len(t, merges, 1)
+	assert.True(t, merges[0].Conflict)
+	assert.Contains(t, merges[0].Merged, "<<<<<<< extension")
+	assert.Equal(t, `{% block title %}<h1 class="custom">{{ title }}</h1>{% endblock %}`, extAst.Dump())
+}
+
+func TestPairBlocks(t *testing.T) {
+	oldAst, err := ParseTemplate(`{% block page %}{% block title %}<h1>{{ title }}</h1>{% endblock %}{% block text %}Text{% endblock %}{% endblock %}`)
+	assert.NoError(t, err)
+	newAst, err := ParseTemplate(`{% block page %}<main>{% block title %}<h2>{{ title }}</h2>{% endblock %}{% block text %}Text{% endblock %}</main>{% endblock %}`)
+	assert.NoError(t, err)
+	extAst, err := ParseTemplate(`{% block page %}{{ parent() }}{% block title %}<h1>{{ title }}!</h1>{% endblock %}{% block text %}Other{% endblock %}{% endblock %}`)
+	assert.NoError(t, err)
+
+	pairs := PairBlocks(oldAst, newAst, extAst)
+
+	assert.Len(t, pairs, 1)
+	assert.Equal(t, "title", pairs[0].Name)
+	assert.Equal(t, "<h1>{{ title }}</h1>", pairs[0].Old)
+	assert.Equal(t, "<h2>{{ title }}</h2>", pairs[0].New)
+	assert.Equal(t, "<h1>{{ title }}!</h1>", pairs[0].Extension)
+}
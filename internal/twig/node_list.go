@@ -10,16 +10,27 @@ func (nl NodeList) Find(predicate func(Node) bool) NodeList {
 		if predicate(node) {
 			result = append(result, node)
 		}
-		// If the node is a BlockNode, search recursively in its children.
-		if block, ok := node.(*BlockNode); ok {
-			children := NodeList(block.Children)
-			nestedMatches := children.Find(predicate)
-			result = append(result, nestedMatches...)
+		// Search recursively in the children, the blocks of an embedded template belong to that template.
+		if isEmbed(node) {
+			continue
+		}
+		if container, ok := node.(Container); ok {
+			for _, body := range container.Bodies() {
+				result = append(result, body.Find(predicate)...)
+			}
 		}
 	}
 	return result
 }
 
+func isEmbed(node Node) bool {
+	switch node.(type) {
+	case *EmbedNode, *SwEmbedNode:
+		return true
+	}
+	return false
+}
+
 func (nl NodeList) FindBlock(name string) *BlockNode {
 	matches := nl.Find(func(node Node) bool {
 		block, ok := node.(*BlockNode)
@@ -62,8 +73,10 @@ func (nl NodeList) BlockNames() []string {
 func (nl NodeList) Traverse(visitor func(Node) Node) NodeList {
 	for i, node := range nl {
 		// If the node has children, traverse them first.
-		if block, ok := node.(*BlockNode); ok {
-			block.Children = NodeList(block.Children).Traverse(visitor)
+		if container, ok := node.(Container); ok {
+			for _, body := range container.Bodies() {
+				*body = body.Traverse(visitor)
+			}
 		}
 		// Apply the visitor function.
 		nl[i] = visitor(node)
@@ -71,11 +84,15 @@ func (nl NodeList) Traverse(visitor func(Node) Node) NodeList {
 	return nl
 }
 
+// RemoveWhitespace returns the nodes of the list which are no WhitespaceNode, children are kept as they are.
 func (nl NodeList) RemoveWhitespace() NodeList {
-	return nl.Find(func(node Node) bool {
-		_, isWhitespace := node.(*WhitespaceNode)
-		return !isWhitespace
-	})
+	var result NodeList
+	for _, node := range nl {
+		if _, isWhitespace := node.(*WhitespaceNode); !isWhitespace {
+			result = append(result, node)
+		}
+	}
+	return result
 }
 
 func (nl NodeList) String() string {
@@ -95,3 +112,13 @@ func (nl NodeList) Dump() string {
 	}
 	return sb.String()
 }
+
+// indented returns the debug representation of the nodes with the indentation of a child.
+func (nl NodeList) indented(indent string) string {
+	var sb strings.Builder
+	for _, node := range nl {
+		sb.WriteString(node.String(indent + "  "))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
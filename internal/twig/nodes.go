@@ -2,6 +2,7 @@ package twig
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -11,10 +12,13 @@ type Node interface {
 	String(indent string) string
 	// Dump outputs the node (and its children) back into source code.
 	Dump() string
+	// Pos returns the position of the node in the parsed template.
+	Pos() Position
 }
 
 // TextNode holds non‑whitespace plain text.
 type TextNode struct {
+	Position
 	Text string
 }
 
@@ -30,6 +34,7 @@ func (t *TextNode) Dump() string {
 // WhitespaceNode holds a text fragment that consists solely of
 // whitespace (spaces, tabs, newlines, etc).
 type WhitespaceNode struct {
+	Position
 	Text string
 }
 
@@ -42,11 +47,30 @@ func (w *WhitespaceNode) Dump() string {
 	return w.Text
 }
 
+// CommentNode represents a Twig comment {# ... #}.
+type CommentNode struct {
+	Position
+	// Text between the delimiters
+	Text string
+}
+
+func (c *CommentNode) String(indent string) string {
+	return fmt.Sprintf("%sCommentNode(%q)", indent, c.Text)
+}
+
+func (c *CommentNode) Dump() string {
+	return "{#" + c.Text + "#}"
+}
+
 // BlockNode represents a Twig block (with opening tag {% block <name> %}
 // and ending tag {% endblock %}), and contains nested child nodes.
 type BlockNode struct {
-	Name     string
-	Children NodeList
+	Tag
+	Name string
+	// Content of the short syntax {% block title page_title %}, which has no children and end tag
+	Expression string
+	Children   NodeList
+	End        Tag
 }
 
 func (b *BlockNode) String(indent string) string {
@@ -59,30 +83,98 @@ func (b *BlockNode) String(indent string) string {
 	return sb.String()
 }
 
+func (b *BlockNode) content() string {
+	if b.Expression != "" {
+		return "block " + b.Name + " " + b.Expression
+	}
+	return "block " + b.Name
+}
+
 func (b *BlockNode) Dump() string {
-	var sb strings.Builder
-	sb.WriteString("{% block " + b.Name + " %}")
-	for _, child := range b.Children {
-		sb.WriteString(child.Dump())
+	if b.Expression != "" {
+		return b.dumpBlockTag(b.content())
 	}
-	sb.WriteString("{% endblock %}")
-	return sb.String()
+	return b.dumpBlockTag(b.content()) + b.Children.Dump() + b.End.dumpBlockTag("endblock")
+}
+
+func (b *BlockNode) Bodies() []*NodeList {
+	return []*NodeList{&b.Children}
+}
+
+// CallsParent reports whether the block renders the parent block with {{ parent() }}, also inside of
+// control structures. Nested blocks and embedded templates are not taken into account.
+func (b *BlockNode) CallsParent() bool {
+	return callsParent(b.Children)
+}
+
+func callsParent(nodes NodeList) bool {
+	for _, node := range nodes {
+		switch node.(type) {
+		case *ParentNode:
+			return true
+		case *BlockNode, *EmbedNode, *SwEmbedNode:
+			continue
+		}
+
+		if container, ok := node.(Container); ok {
+			for _, body := range container.Bodies() {
+				if callsParent(*body) {
+					return true
+				}
+			}
+		}
+	}
+	return false
 }
 
 // ParentNode represents the Twig expression {{ parent() }}.
-type ParentNode struct{}
+type ParentNode struct {
+	Tag
+}
+
+// isParentCall reports whether the printed expression only calls parent(), independent of its spelling like parent ().
+func isParentCall(expression string) bool {
+	expr, err := parseExpressionAt(expression, Position{})
+	if err != nil {
+		return false
+	}
+
+	function, ok := expr.(*FunctionExpr)
+	return ok && function.Name == "parent" && len(function.Arguments) == 0
+}
 
 func (p *ParentNode) String(indent string) string {
 	return fmt.Sprintf("%sParentNode(parent())", indent)
 }
 
 func (p *ParentNode) Dump() string {
-	return "{{ parent() }}"
+	return p.dump("{{", "parent()", "}}")
+}
+
+// ExtendsNode represents the Twig tag {% extends %}.
+type ExtendsNode struct {
+	Tag
+	// Template path, or the expression when Dynamic is true
+	Template string
+	Dynamic  bool
+}
+
+func (e *ExtendsNode) String(indent string) string {
+	return fmt.Sprintf("%sExtendsNode(Template: %q)", indent, e.Template)
+}
+
+func (e *ExtendsNode) content() string {
+	return "extends " + dumpTemplateArgument(e.Template, e.Dynamic, "")
+}
+
+func (e *ExtendsNode) Dump() string {
+	return e.dumpBlockTag(e.content())
 }
 
 // SwExtendsNode represents the Twig tag for extending a template.
 // It supports both simple and object-literal syntaxes.
 type SwExtendsNode struct {
+	Tag
 	Template string
 	Scopes   []string
 }
@@ -94,7 +186,7 @@ func (s *SwExtendsNode) String(indent string) string {
 	return fmt.Sprintf("%sSwExtendsNode(Template: %q)", indent, s.Template)
 }
 
-func (s *SwExtendsNode) Dump() string {
+func (s *SwExtendsNode) content() string {
 	// Dump in canonical form.
 	if len(s.Scopes) > 0 {
 		// Build a scopes array such as ['default', 'subscription']
@@ -102,18 +194,195 @@ func (s *SwExtendsNode) Dump() string {
 		for _, scope := range s.Scopes {
 			scopesParts = append(scopesParts, fmt.Sprintf("'%s'", scope))
 		}
-		return fmt.Sprintf("{%% sw_extends { template: '%s', scopes: [%s] } %%}",
+		return fmt.Sprintf("sw_extends { template: '%s', scopes: [%s] }",
 			s.Template, strings.Join(scopesParts, ", "))
 	}
 	// Simple syntax?
-	return "{% sw_extends '" + s.Template + "' %}"
+	return "sw_extends '" + s.Template + "'"
+}
+
+func (s *SwExtendsNode) Dump() string {
+	return s.dumpBlockTag(s.content())
+}
+
+// IncludeNode represents the Twig tag {% include %}.
+type IncludeNode struct {
+	Tag
+	// Template path, or the expression when Dynamic is true
+	Template string
+	Dynamic  bool
+	// Remaining arguments like "with { foo: 'bar' } only"
+	Arguments string
+}
+
+func (i *IncludeNode) String(indent string) string {
+	return fmt.Sprintf("%sIncludeNode(Template: %q, Arguments: %q)", indent, i.Template, i.Arguments)
+}
+
+func (i *IncludeNode) content() string {
+	return "include " + dumpTemplateArgument(i.Template, i.Dynamic, i.Arguments)
+}
+
+func (i *IncludeNode) Dump() string {
+	return i.dumpBlockTag(i.content())
+}
+
+// SwIncludeNode represents the Twig tag {% sw_include %}, which renders a template
+// resolved through the Shopware template inheritance.
+type SwIncludeNode struct {
+	Tag
+	// Template path, or the expression when Dynamic is true
+	Template string
+	Dynamic  bool
+	// Remaining arguments like "with { foo: 'bar' } only"
+	Arguments string
+}
+
+func (s *SwIncludeNode) String(indent string) string {
+	return fmt.Sprintf("%sSwIncludeNode(Template: %q, Arguments: %q)", indent, s.Template, s.Arguments)
+}
+
+func (s *SwIncludeNode) content() string {
+	return "sw_include " + dumpTemplateArgument(s.Template, s.Dynamic, s.Arguments)
+}
+
+func (s *SwIncludeNode) Dump() string {
+	return s.dumpBlockTag(s.content())
+}
+
+// EmbedNode represents the Twig tag {% embed %}, which includes a template
+// and overrides its blocks with the nested ones.
+type EmbedNode struct {
+	Tag
+	Template  string
+	Dynamic   bool
+	Arguments string
+	Children  NodeList
+	End       Tag
+}
+
+func (e *EmbedNode) String(indent string) string {
+	return fmt.Sprintf("%sEmbedNode(Template: %q, Arguments: %q)\n", indent, e.Template, e.Arguments) + e.Children.indented(indent)
+}
+
+func (e *EmbedNode) content() string {
+	return "embed " + dumpTemplateArgument(e.Template, e.Dynamic, e.Arguments)
+}
+
+func (e *EmbedNode) Dump() string {
+	return e.dumpBlockTag(e.content()) + e.Children.Dump() + e.End.dumpBlockTag("endembed")
+}
+
+func (e *EmbedNode) Bodies() []*NodeList {
+	return []*NodeList{&e.Children}
+}
+
+// SwEmbedNode represents the Twig tag {% sw_embed %}, which includes a template
+// and overrides its blocks with the nested ones.
+type SwEmbedNode struct {
+	Tag
+	Template  string
+	Dynamic   bool
+	Arguments string
+	Children  NodeList
+	End       Tag
+}
+
+func (s *SwEmbedNode) String(indent string) string {
+	return fmt.Sprintf("%sSwEmbedNode(Template: %q, Arguments: %q)\n", indent, s.Template, s.Arguments) + s.Children.indented(indent)
+}
+
+func (s *SwEmbedNode) content() string {
+	return "sw_embed " + dumpTemplateArgument(s.Template, s.Dynamic, s.Arguments)
+}
+
+func (s *SwEmbedNode) Dump() string {
+	return s.dumpBlockTag(s.content()) + s.Children.Dump() + s.End.dumpBlockTag("endsw_embed")
+}
+
+func (s *SwEmbedNode) Bodies() []*NodeList {
+	return []*NodeList{&s.Children}
+}
+
+// dumpTemplateArgument dumps the template and arguments of an include like tag.
+func dumpTemplateArgument(template string, dynamic bool, arguments string) string {
+	if !dynamic {
+		template = "'" + template + "'"
+	}
+	if arguments == "" {
+		return template
+	}
+	return template + " " + arguments
+}
+
+// IfNode represents an if tag with its elseif and else branches.
+type IfNode struct {
+	Branches []*IfBranch
+	End      Tag
+}
+
+// IfBranch is the if, an elseif or the else branch of an IfNode. The else branch has no condition.
+type IfBranch struct {
+	Tag
+	Condition string
+	Children  NodeList
+}
+
+func (i *IfNode) String(indent string) string {
+	var sb strings.Builder
+	sb.WriteString(indent + "IfNode\n")
+	for _, branch := range i.Branches {
+		sb.WriteString(fmt.Sprintf("%s  IfBranch(Condition: %s)\n", indent, branch.Condition))
+		sb.WriteString(branch.Children.indented(indent + "  "))
+	}
+	return sb.String()
+}
+
+func (i *IfNode) content(index int) string {
+	if index == 0 {
+		return "if " + i.Branches[index].Condition
+	}
+	if i.Branches[index].Condition == "" {
+		return "else"
+	}
+	return "elseif " + i.Branches[index].Condition
+}
+
+func (i *IfNode) Dump() string {
+	var sb strings.Builder
+	for index, branch := range i.Branches {
+		sb.WriteString(branch.dumpBlockTag(i.content(index)))
+		sb.WriteString(branch.Children.Dump())
+	}
+	sb.WriteString(i.End.dumpBlockTag("endif"))
+	return sb.String()
+}
+
+func (i *IfNode) Pos() Position {
+	if len(i.Branches) == 0 {
+		return Position{}
+	}
+	return i.Branches[0].Pos()
+}
+
+func (i *IfNode) Bodies() []*NodeList {
+	var bodies []*NodeList
+	for _, branch := range i.Branches {
+		bodies = append(bodies, &branch.Children)
+	}
+	return bodies
 }
 
 // ForNode represents a for-loop in the template.
 type ForNode struct {
+	Tag
 	Var        string
 	Collection string
 	Children   NodeList
+	// Rendered when the collection is empty, only present with ElseTag
+	Else    NodeList
+	ElseTag *Tag
+	End     Tag
 }
 
 func (f *ForNode) String(indent string) string {
@@ -125,19 +394,29 @@ func (f *ForNode) String(indent string) string {
 	return s
 }
 
+func (f *ForNode) content() string {
+	return "for " + f.Var + " in " + f.Collection
+}
+
 func (f *ForNode) Dump() string {
 	var sb strings.Builder
-	sb.WriteString("{% for " + f.Var + " in " + f.Collection + " %}")
-	// ...existing code...
-	for _, child := range f.Children {
-		sb.WriteString(child.Dump())
+	sb.WriteString(f.dumpBlockTag(f.content()))
+	sb.WriteString(f.Children.Dump())
+	if f.ElseTag != nil {
+		sb.WriteString(f.ElseTag.dumpBlockTag("else"))
+		sb.WriteString(f.Else.Dump())
 	}
-	sb.WriteString("{% endfor %}")
+	sb.WriteString(f.End.dumpBlockTag("endfor"))
 	return sb.String()
 }
 
+func (f *ForNode) Bodies() []*NodeList {
+	return []*NodeList{&f.Children, &f.Else}
+}
+
 // PrintNode represents an expression that prints a variable.
 type PrintNode struct {
+	Tag
 	Expression string
 }
 
@@ -146,11 +425,12 @@ func (p *PrintNode) String(indent string) string {
 }
 
 func (p *PrintNode) Dump() string {
-	return "{{ " + p.Expression + " }}"
+	return p.dump("{{", p.Expression, "}}")
 }
 
 // DeprecatedNode represents a deprecated tag in the template.
 type DeprecatedNode struct {
+	Tag
 	Message string
 }
 
@@ -158,16 +438,22 @@ func (d *DeprecatedNode) String(indent string) string {
 	return indent + "DeprecatedNode(" + d.Message + ")"
 }
 
+func (d *DeprecatedNode) content() string {
+	return "deprecated '" + d.Message + "'"
+}
+
 func (d *DeprecatedNode) Dump() string {
-	return "{% deprecated '" + d.Message + "' %}"
+	return d.dumpBlockTag(d.content())
 }
 
 // SetNode represents a 'set' assignment in the template.
 type SetNode struct {
+	Tag
 	Variables []string // left-hand side variable(s)
 	Values    []string // right-hand side expression(s) for inline assignment; empty when IsBlock is true
 	IsBlock   bool     // true when using block assignment
 	Children  NodeList // block assignment content
+	End       Tag
 }
 
 func (s *SetNode) String(indent string) string {
@@ -177,11 +463,22 @@ func (s *SetNode) String(indent string) string {
 	return fmt.Sprintf("%sSetNode(Inline, Variables: %v, Values: %v)", indent, s.Variables, s.Values)
 }
 
+func (s *SetNode) content() string {
+	if s.IsBlock {
+		return "set " + joinNames(s.Variables)
+	}
+	return "set " + joinNames(s.Variables) + " = " + joinNames(s.Values)
+}
+
 func (s *SetNode) Dump() string {
 	if s.IsBlock {
-		return "{% set " + joinNames(s.Variables) + " %}" + s.Children.Dump() + "{% endset %}"
+		return s.dumpBlockTag(s.content()) + s.Children.Dump() + s.End.dumpBlockTag("endset")
 	}
-	return "{% set " + joinNames(s.Variables) + " = " + joinNames(s.Values) + " %}"
+	return s.dumpBlockTag(s.content())
+}
+
+func (s *SetNode) Bodies() []*NodeList {
+	return []*NodeList{&s.Children}
 }
 
 // joinNames is a helper to join a slice of strings with ", ".
@@ -191,20 +488,37 @@ func joinNames(arr []string) string {
 
 // AutoescapeNode represents an autoescape block in the template.
 type AutoescapeNode struct {
+	Tag
 	Strategy string   // e.g. "html"
 	Children NodeList // content within the autoescape block
+	End      Tag
 }
 
 func (a *AutoescapeNode) String(indent string) string {
 	return fmt.Sprintf("%sAutoescapeNode(Strategy: %s)", indent, a.Strategy)
 }
 
+func (a *AutoescapeNode) content() string {
+	switch a.Strategy {
+	case "", "html":
+		return "autoescape"
+	case "false":
+		return "autoescape false"
+	}
+	return "autoescape '" + a.Strategy + "'"
+}
+
 func (a *AutoescapeNode) Dump() string {
-	return "{% autoescape %}" + a.Children.Dump() + "{% endautoescape %}"
+	return a.dumpBlockTag(a.content()) + a.Children.Dump() + a.End.dumpBlockTag("endautoescape")
+}
+
+func (a *AutoescapeNode) Bodies() []*NodeList {
+	return []*NodeList{&a.Children}
 }
 
 // TypesNode represents a types definition tag such as {% types score: 'number' %}
 type TypesNode struct {
+	Tag
 	Types map[string]string
 }
 
@@ -212,10 +526,187 @@ func (t *TypesNode) String(indent string) string {
 	return fmt.Sprintf("%sTypesNode(%v)", indent, t.Types)
 }
 
-func (t *TypesNode) Dump() string {
+func (t *TypesNode) content() string {
 	var parts []string
 	for key, value := range t.Types {
 		parts = append(parts, fmt.Sprintf("%s: %s", key, value))
 	}
-	return "{% types " + strings.Join(parts, " ") + " %}"
+	sort.Strings(parts)
+	return "types " + strings.Join(parts, " ")
+}
+
+func (t *TypesNode) Dump() string {
+	return t.dumpBlockTag(t.content())
+}
+
+// MacroNode represents a macro definition {% macro name(arguments) %}.
+type MacroNode struct {
+	Tag
+	Name string
+	// Arguments between the parentheses, e.g. "name, value = null"
+	Arguments string
+	Children  NodeList
+	End       Tag
+}
+
+func (m *MacroNode) String(indent string) string {
+	return fmt.Sprintf("%sMacroNode(Name: %s, Arguments: %s)\n", indent, m.Name, m.Arguments) + m.Children.indented(indent)
+}
+
+func (m *MacroNode) content() string {
+	return "macro " + m.Name + "(" + m.Arguments + ")"
+}
+
+func (m *MacroNode) Dump() string {
+	return m.dumpBlockTag(m.content()) + m.Children.Dump() + m.End.dumpBlockTag("endmacro")
+}
+
+func (m *MacroNode) Bodies() []*NodeList {
+	return []*NodeList{&m.Children}
+}
+
+// ImportNode represents the Twig tag {% import 'forms.html' as forms %}.
+type ImportNode struct {
+	Tag
+	// Template path, or the expression like _self when Dynamic is true
+	Template string
+	Dynamic  bool
+	Alias    string
+}
+
+func (i *ImportNode) String(indent string) string {
+	return fmt.Sprintf("%sImportNode(Template: %q, Alias: %s)", indent, i.Template, i.Alias)
+}
+
+func (i *ImportNode) content() string {
+	return "import " + dumpTemplateArgument(i.Template, i.Dynamic, "") + " as " + i.Alias
+}
+
+func (i *ImportNode) Dump() string {
+	return i.dumpBlockTag(i.content())
+}
+
+// FromNode represents the Twig tag {% from 'forms.html' import input as field %}.
+type FromNode struct {
+	Tag
+	Template string
+	Dynamic  bool
+	// Imported macros, e.g. "input as field"
+	Imports []string
+}
+
+func (f *FromNode) String(indent string) string {
+	return fmt.Sprintf("%sFromNode(Template: %q, Imports: %q)", indent, f.Template, f.Imports)
+}
+
+func (f *FromNode) content() string {
+	return "from " + dumpTemplateArgument(f.Template, f.Dynamic, "") + " import " + joinNames(f.Imports)
+}
+
+func (f *FromNode) Dump() string {
+	return f.dumpBlockTag(f.content())
+}
+
+// ApplyNode represents the Twig tag {% apply upper|trim %}, which applies filters to its content.
+type ApplyNode struct {
+	Tag
+	Filters  string
+	Children NodeList
+	End      Tag
+}
+
+func (a *ApplyNode) String(indent string) string {
+	return fmt.Sprintf("%sApplyNode(Filters: %s)\n", indent, a.Filters) + a.Children.indented(indent)
+}
+
+func (a *ApplyNode) content() string {
+	return "apply " + a.Filters
+}
+
+func (a *ApplyNode) Dump() string {
+	return a.dumpBlockTag(a.content()) + a.Children.Dump() + a.End.dumpBlockTag("endapply")
+}
+
+func (a *ApplyNode) Bodies() []*NodeList {
+	return []*NodeList{&a.Children}
+}
+
+// WithNode represents the Twig tag {% with { foo: 'bar' } only %}, which creates a new scope.
+type WithNode struct {
+	Tag
+	// Variables of the scope, empty when none are given
+	Expression string
+	Only       bool
+	Children   NodeList
+	End        Tag
+}
+
+func (w *WithNode) String(indent string) string {
+	return fmt.Sprintf("%sWithNode(Expression: %s, Only: %t)\n", indent, w.Expression, w.Only) + w.Children.indented(indent)
+}
+
+func (w *WithNode) content() string {
+	content := "with"
+	if w.Expression != "" {
+		content += " " + w.Expression
+	}
+	if w.Only {
+		content += " only"
+	}
+	return content
+}
+
+func (w *WithNode) Dump() string {
+	return w.dumpBlockTag(w.content()) + w.Children.Dump() + w.End.dumpBlockTag("endwith")
+}
+
+func (w *WithNode) Bodies() []*NodeList {
+	return []*NodeList{&w.Children}
+}
+
+// VerbatimNode represents a verbatim block, its content is not parsed.
+type VerbatimNode struct {
+	Tag
+	Text string
+	End  Tag
+}
+
+func (v *VerbatimNode) String(indent string) string {
+	return fmt.Sprintf("%sVerbatimNode(%q)", indent, v.Text)
+}
+
+func (v *VerbatimNode) Dump() string {
+	return v.dumpBlockTag("verbatim") + v.Text + v.End.dumpBlockTag("endverbatim")
+}
+
+// TagNode represents any other tag like {% do %}, {% sw_icon %} or {% cache %}.
+// Tags which enclose content have an end tag.
+type TagNode struct {
+	Tag
+	Name      string
+	Arguments string
+	Children  NodeList
+	End       *Tag
+}
+
+func (t *TagNode) String(indent string) string {
+	return fmt.Sprintf("%sTagNode(Name: %s, Arguments: %s)\n", indent, t.Name, t.Arguments) + t.Children.indented(indent)
+}
+
+func (t *TagNode) content() string {
+	if t.Arguments == "" {
+		return t.Name
+	}
+	return t.Name + " " + t.Arguments
+}
+
+func (t *TagNode) Dump() string {
+	if t.End == nil {
+		return t.dumpBlockTag(t.content())
+	}
+	return t.dumpBlockTag(t.content()) + t.Children.Dump() + t.End.dumpBlockTag("end"+t.Name)
+}
+
+func (t *TagNode) Bodies() []*NodeList {
+	return []*NodeList{&t.Children}
 }
@@ -6,14 +6,21 @@ import (
 )
 
 // parseTypes parses the content of a types tag.
-// For example, given "score: 'number'" it returns a TypesNode with the mapping.
-func parseTypes(content string) (Node, error) {
+// For example, given "score: 'number'" or "{score: 'number', name: 'string'}" it returns a TypesNode with the mapping.
+func parseTypes(content string) (*TypesNode, error) {
 	typesMap := make(map[string]string)
+	content = strings.TrimSpace(content)
+	if strings.HasPrefix(content, "{") && strings.HasSuffix(content, "}") {
+		content = content[1 : len(content)-1]
+	}
 	if strings.TrimSpace(content) == "" {
 		return nil, errors.New("no types provided")
 	}
-	// For simplicity, assume tokens do not contain spaces.
-	tokens := strings.Fields(content)
+	tokens := splitExpressions(content)
+	if len(tokens) == 1 {
+		// For simplicity, assume tokens do not contain spaces.
+		tokens = strings.Fields(content)
+	}
 	for _, token := range tokens {
 		parts := strings.SplitN(token, ":", 2)
 		if len(parts) != 2 {
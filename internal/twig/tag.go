@@ -0,0 +1,88 @@
+package twig
+
+import "strings"
+
+// Position of a node in the template source. Lines and columns start at 1, columns count bytes.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// Pos returns the position, the zero value for nodes which have not been parsed.
+func (p Position) Pos() Position {
+	return p
+}
+
+// Tag holds the source of a tag like {% if foo %} or {{ foo }}. A parsed tag is dumped as it has
+// been written as long as the values parsed from it stay unchanged, otherwise it is dumped in canonical form.
+type Tag struct {
+	Position
+	// Source of the tag including its delimiters
+	Raw string
+	// Whitespace control modifier after the opening and before the closing delimiter, "-", "~" or empty
+	TrimBefore string
+	TrimAfter  string
+	// Canonical content and modifiers of the tag when it was parsed
+	parsed string
+}
+
+func (t *Tag) keep(content string) {
+	t.parsed = t.key(content)
+}
+
+func (t Tag) key(content string) string {
+	return t.TrimBefore + "\x00" + content + "\x00" + t.TrimAfter
+}
+
+func (t Tag) dump(open, content, close string) string {
+	if t.Raw != "" && t.parsed == t.key(content) {
+		return t.Raw
+	}
+
+	return open + t.TrimBefore + " " + content + " " + t.TrimAfter + close
+}
+
+// dumpBlockTag dumps a {% %} tag.
+func (t Tag) dumpBlockTag(content string) string {
+	return t.dump("{%", content, "%}")
+}
+
+// positionOf returns the position of content in the source of the tag, searching from the given offset
+// of the source, and the offset after the content. Without a source, e.g. for a modified tag,
+// the position of the tag is returned.
+func (t Tag) positionOf(content string, from int) (Position, int) {
+	from = max(from, 0)
+	if from > len(t.Raw) {
+		return t.Position, from
+	}
+
+	index := strings.Index(t.Raw[from:], content)
+	if t.Raw == "" || content == "" || index == -1 {
+		return t.Position, from
+	}
+
+	index += from
+	return advance(t.Position, t.Raw[:index]), index + len(content)
+}
+
+// advance returns the position after text starting at pos.
+func advance(pos Position, text string) Position {
+	for _, c := range []byte(text) {
+		if c == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	pos.Offset += len(text)
+	return pos
+}
+
+// Container is implemented by nodes with nested nodes like blocks or control structures.
+type Container interface {
+	Node
+	// Bodies returns the nested node lists, e.g. one for every branch of an if.
+	Bodies() []*NodeList
+}
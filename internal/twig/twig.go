@@ -2,290 +2,601 @@ package twig
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"unicode"
 )
 
-// tokenizeText splits a text string into a slice of Nodes. Each continuous
-// segment of pure whitespace becomes a WhitespaceNode, whereas every other
-// segment becomes a TextNode.
-func tokenizeText(text string) NodeList {
-	var nodes NodeList
-	if len(text) == 0 {
-		return nodes
+// bodyTags are the tags without an own node type which enclose content up to their end tag.
+var bodyTags = map[string]bool{
+	"cache":                  true,
+	"guard":                  true,
+	"sandbox":                true,
+	"sw_silent_feature_call": true,
+}
+
+var endVerbatimPattern = regexp.MustCompile(`{%[-~]?\s*endverbatim\s*[-~]?%}`)
+
+type tokenKind int
+
+const (
+	textToken tokenKind = iota
+	commentToken
+	printToken
+	tagToken
+)
+
+// token is a piece of the template source: plain text, a comment, an expression or a tag.
+type token struct {
+	kind tokenKind
+	tag  Tag
+	// Text of a text token, content of a comment and the content of an expression or tag without delimiters and modifiers
+	content string
+	// Name and arguments of a tag
+	name string
+	args string
+}
+
+type parser struct {
+	input string
+	pos   int
+	// Offsets at which the lines start
+	lines []int
+}
+
+// ParseTemplate is the entry point that builds an AST for the template.
+// Dumping the unmodified AST returns the template as it has been written.
+func ParseTemplate(input string) (NodeList, error) {
+	p := &parser{input: input, lines: []int{0}}
+	for i, c := range input {
+		if c == '\n' {
+			p.lines = append(p.lines, i+1)
+		}
 	}
 
-	var current strings.Builder
-	// Determine the type for the first rune.
-	firstRune, _ := utf8DecodeRuneInString(text)
-	inWhitespace := isWhitespace(firstRune)
-
-	for _, r := range text {
-		if isWhitespace(r) == inWhitespace {
-			current.WriteRune(r)
-		} else {
-			token := current.String()
-			if inWhitespace {
-				nodes = append(nodes, &WhitespaceNode{Text: token})
+	nodes, _, err := p.parse(nil)
+	return nodes, err
+}
+
+// parse returns the nodes up to one of the given end tags and the end tag itself.
+// Without end tags it parses up to the end of the input.
+func (p *parser) parse(open *token, ends ...string) (NodeList, *token, error) {
+	var nodes NodeList
+
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if tok == nil {
+			if open != nil {
+				return nil, nil, fmt.Errorf("unclosed %s tag on line %d, expected %s", open.name, open.tag.Line, strings.Join(ends, " or "))
+			}
+			return nodes, nil, nil
+		}
+
+		switch tok.kind {
+		case textToken:
+			nodes = append(nodes, p.text(tok.content, tok.tag.Offset)...)
+		case commentToken:
+			nodes = append(nodes, &CommentNode{Position: tok.tag.Position, Text: tok.content})
+		case printToken:
+			if isParentCall(tok.content) {
+				node := &ParentNode{Tag: tok.tag}
+				node.keep("parent()")
+				nodes = append(nodes, node)
 			} else {
-				nodes = append(nodes, &TextNode{Text: token})
+				// Create a PrintNode for expressions like {{ a_variable }}
+				node := &PrintNode{Tag: tok.tag, Expression: tok.content}
+				node.keep(node.Expression)
+				nodes = append(nodes, node)
+			}
+		case tagToken:
+			if slices.Contains(ends, tok.name) {
+				return nodes, tok, nil
+			}
+
+			node, err := p.tag(tok)
+			if err != nil {
+				return nil, nil, err
+			}
+			nodes = append(nodes, node)
+		}
+	}
+}
+
+// tag builds the node of a tag, parsing its content up to the end tag.
+func (p *parser) tag(tok *token) (Node, error) {
+	switch tok.name {
+	case "block":
+		name, expression := cutName(tok.args)
+		if name == "" {
+			return nil, fmt.Errorf("invalid block tag on line %d: no block name", tok.tag.Line)
+		}
+		node := &BlockNode{Tag: tok.tag, Name: name, Expression: expression}
+		node.keep(node.content())
+		if expression != "" {
+			return node, nil
+		}
+		children, end, err := p.parse(tok, "endblock")
+		if err != nil {
+			return nil, err
+		}
+		node.Children = children
+		node.End = endTag(end, "endblock")
+		return node, nil
+	case "if":
+		node := &IfNode{}
+		branch := &IfBranch{Tag: tok.tag, Condition: tok.args}
+		for {
+			children, end, err := p.parse(tok, "elseif", "else", "endif")
+			if err != nil {
+				return nil, err
+			}
+			branch.Children = children
+			node.Branches = append(node.Branches, branch)
+			branch.keep(node.content(len(node.Branches) - 1))
+
+			if end.name == "endif" {
+				node.End = endTag(end, "endif")
+				return node, nil
+			}
+
+			branch = &IfBranch{Tag: end.tag}
+			if end.name == "elseif" {
+				branch.Condition = end.args
+			}
+		}
+	case "for":
+		variables, collection, ok := strings.Cut(tok.args, " in ")
+		if !ok {
+			return nil, fmt.Errorf("invalid for tag on line %d: missing in", tok.tag.Line)
+		}
+		node := &ForNode{Tag: tok.tag, Var: strings.TrimSpace(variables), Collection: strings.TrimSpace(collection)}
+		node.keep(node.content())
+		children, end, err := p.parse(tok, "else", "endfor")
+		if err != nil {
+			return nil, err
+		}
+		node.Children = children
+		if end.name == "else" {
+			elseTag := endTag(end, "else")
+			node.ElseTag = &elseTag
+			node.Else, end, err = p.parse(tok, "endfor")
+			if err != nil {
+				return nil, err
+			}
+		}
+		node.End = endTag(end, "endfor")
+		return node, nil
+	case "set":
+		if strings.Contains(tok.args, "=") {
+			// Inline assignment.
+			lhs, rhs, _ := strings.Cut(tok.args, "=")
+			node := &SetNode{
+				Tag:       tok.tag,
+				Variables: splitAndTrim(lhs, ","),
+				Values:    splitExpressions(rhs),
 			}
-			current.Reset()
+			node.keep(node.content())
+			return node, nil
+		}
+		// Block assignment.
+		node := &SetNode{Tag: tok.tag, Variables: splitAndTrim(tok.args, ","), IsBlock: true}
+		node.keep(node.content())
+		children, end, err := p.parse(tok, "endset")
+		if err != nil {
+			return nil, err
+		}
+		node.Children = children
+		node.End = endTag(end, "endset")
+		return node, nil
+	case "deprecated":
+		// Remove surrounding quotes if present.
+		node := &DeprecatedNode{Tag: tok.tag, Message: strings.Trim(tok.args, `"'`)}
+		node.keep(node.content())
+		return node, nil
+	case "autoescape":
+		// Optionally, support a custom strategy.
+		node := &AutoescapeNode{Tag: tok.tag, Strategy: "html"}
+		if tok.args != "" {
+			node.Strategy = strings.Trim(tok.args, `"'`)
+		}
+		node.keep(node.content())
+		children, end, err := p.parse(tok, "endautoescape")
+		if err != nil {
+			return nil, err
+		}
+		node.Children = children
+		node.End = endTag(end, "endautoescape")
+		return node, nil
+	case "types":
+		node, err := parseTypes(tok.args)
+		if err != nil {
+			return nil, err
+		}
+		node.Tag = tok.tag
+		node.keep(node.content())
+		return node, nil
+	case "sw_extends":
+		node, err := parseSwExtends(tok.args)
+		if err != nil {
+			return nil, err
+		}
+		node.Tag = tok.tag
+		node.keep(node.content())
+		return node, nil
+	case "extends":
+		node := &ExtendsNode{Tag: tok.tag}
+		node.Template, node.Dynamic, _ = splitTemplateArgument(tok.args)
+		node.keep(node.content())
+		return node, nil
+	case "include":
+		node := &IncludeNode{Tag: tok.tag}
+		node.Template, node.Dynamic, node.Arguments = splitTemplateArgument(tok.args)
+		node.keep(node.content())
+		return node, nil
+	case "sw_include":
+		node := &SwIncludeNode{Tag: tok.tag}
+		node.Template, node.Dynamic, node.Arguments = splitTemplateArgument(tok.args)
+		node.keep(node.content())
+		return node, nil
+	case "embed":
+		node := &EmbedNode{Tag: tok.tag}
+		node.Template, node.Dynamic, node.Arguments = splitTemplateArgument(tok.args)
+		node.keep(node.content())
+		children, end, err := p.parse(tok, "endembed")
+		if err != nil {
+			return nil, err
+		}
+		node.Children = children
+		node.End = endTag(end, "endembed")
+		return node, nil
+	case "sw_embed":
+		node := &SwEmbedNode{Tag: tok.tag}
+		node.Template, node.Dynamic, node.Arguments = splitTemplateArgument(tok.args)
+		node.keep(node.content())
+		children, end, err := p.parse(tok, "endsw_embed")
+		if err != nil {
+			return nil, err
+		}
+		node.Children = children
+		node.End = endTag(end, "endsw_embed")
+		return node, nil
+	case "macro":
+		node := &MacroNode{Tag: tok.tag, Name: tok.args}
+		if open := strings.Index(tok.args, "("); open != -1 {
+			node.Name = strings.TrimSpace(tok.args[:open])
+			node.Arguments = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(tok.args[open+1:]), ")"))
+		}
+		node.keep(node.content())
+		children, end, err := p.parse(tok, "endmacro")
+		if err != nil {
+			return nil, err
+		}
+		node.Children = children
+		node.End = endTag(end, "endmacro")
+		return node, nil
+	case "import":
+		template, alias, ok := cutLast(tok.args, " as ")
+		if !ok {
+			return nil, fmt.Errorf("invalid import tag on line %d: missing as", tok.tag.Line)
+		}
+		node := &ImportNode{Tag: tok.tag, Alias: strings.TrimSpace(alias)}
+		node.Template, node.Dynamic, _ = splitTemplateArgument(strings.TrimSpace(template))
+		node.keep(node.content())
+		return node, nil
+	case "from":
+		template, imports, ok := strings.Cut(tok.args, " import ")
+		if !ok {
+			return nil, fmt.Errorf("invalid from tag on line %d: missing import", tok.tag.Line)
+		}
+		node := &FromNode{Tag: tok.tag, Imports: splitAndTrim(imports, ",")}
+		node.Template, node.Dynamic, _ = splitTemplateArgument(strings.TrimSpace(template))
+		node.keep(node.content())
+		return node, nil
+	case "apply":
+		node := &ApplyNode{Tag: tok.tag, Filters: tok.args}
+		node.keep(node.content())
+		children, end, err := p.parse(tok, "endapply")
+		if err != nil {
+			return nil, err
+		}
+		node.Children = children
+		node.End = endTag(end, "endapply")
+		return node, nil
+	case "with":
+		node := &WithNode{Tag: tok.tag, Expression: tok.args}
+		if node.Expression == "only" || strings.HasSuffix(node.Expression, " only") {
+			node.Expression = strings.TrimSpace(strings.TrimSuffix(node.Expression, "only"))
+			node.Only = true
+		}
+		node.keep(node.content())
+		children, end, err := p.parse(tok, "endwith")
+		if err != nil {
+			return nil, err
+		}
+		node.Children = children
+		node.End = endTag(end, "endwith")
+		return node, nil
+	case "verbatim":
+		// The content is not parsed, so the end tag is searched in the source.
+		loc := endVerbatimPattern.FindStringIndex(p.input[p.pos:])
+		if loc == nil {
+			return nil, fmt.Errorf("unclosed verbatim tag on line %d, expected endverbatim", tok.tag.Line)
+		}
+		node := &VerbatimNode{Tag: tok.tag, Text: p.input[p.pos : p.pos+loc[0]]}
+		node.keep("verbatim")
+		p.pos += loc[0]
+		end, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		node.End = endTag(end, "endverbatim")
+		return node, nil
+	}
+
+	// Treat an unexpected end tag as literal text.
+	if strings.HasPrefix(tok.name, "end") || tok.name == "else" || tok.name == "elseif" {
+		return &TextNode{Position: tok.tag.Position, Text: tok.tag.Raw}, nil
+	}
+
+	node := &TagNode{Tag: tok.tag, Name: tok.name, Arguments: tok.args}
+	node.keep(node.content())
+	if bodyTags[tok.name] {
+		children, end, err := p.parse(tok, "end"+tok.name)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = children
+		endTag := endTag(end, "end"+tok.name)
+		node.End = &endTag
+	}
+	return node, nil
+}
+
+// endTag returns the tag of an end token like {% endblock %}.
+func endTag(tok *token, content string) Tag {
+	tag := tok.tag
+	tag.keep(content)
+	return tag
+}
+
+// next returns the next token or nil at the end of the input.
+func (p *parser) next() (*token, error) {
+	if p.pos >= len(p.input) {
+		return nil, nil
+	}
+
+	start := p.pos
+	tok := &token{tag: Tag{Position: p.position(start)}}
+
+	open := nextDelimiter(p.input, start)
+	if open != start {
+		if open == -1 {
+			open = len(p.input)
+		}
+		tok.kind = textToken
+		tok.content = p.input[start:open]
+		p.pos = open
+		return tok, nil
+	}
+
+	var closing string
+
+	switch p.input[start+1] {
+	case '#':
+		end := strings.Index(p.input[start+2:], "#}")
+		if end == -1 {
+			return nil, fmt.Errorf("unclosed comment on line %d", tok.tag.Line)
+		}
+		tok.kind = commentToken
+		tok.content = p.input[start+2 : start+2+end]
+		p.pos = start + 2 + end + 2
+		tok.tag.Raw = p.input[start:p.pos]
+		return tok, nil
+	case '{':
+		tok.kind = printToken
+		closing = "}}"
+	default:
+		tok.kind = tagToken
+		closing = "%}"
+	}
+
+	end := findClosing(p.input, start+2, closing)
+	if end == -1 {
+		if tok.kind == printToken {
+			return nil, fmt.Errorf("unclosed expression tag on line %d", tok.tag.Line)
+		}
+		return nil, fmt.Errorf("unclosed block tag on line %d", tok.tag.Line)
+	}
+
+	p.pos = end + 2
+	tok.tag.Raw = p.input[start:p.pos]
+
+	// Get tag content inside the delimiters.
+	inner := p.input[start+2 : end]
+	if len(inner) > 0 && (inner[0] == '-' || inner[0] == '~') {
+		tok.tag.TrimBefore = inner[:1]
+		inner = inner[1:]
+	}
+	if len(inner) > 0 && (inner[len(inner)-1] == '-' || inner[len(inner)-1] == '~') {
+		tok.tag.TrimAfter = inner[len(inner)-1:]
+		inner = inner[:len(inner)-1]
+	}
+
+	tok.content = strings.TrimSpace(inner)
+
+	if tok.kind == tagToken {
+		tok.name, tok.args = cutName(tok.content)
+	}
+
+	return tok, nil
+}
+
+// text splits a text into nodes. Each continuous segment of pure whitespace
+// becomes a WhitespaceNode, whereas every other segment becomes a TextNode.
+func (p *parser) text(text string, offset int) NodeList {
+	var nodes NodeList
+
+	start, inWhitespace := 0, false
+	for i, r := range text {
+		if i == 0 {
 			inWhitespace = isWhitespace(r)
-			current.WriteRune(r)
+			continue
+		}
+		if isWhitespace(r) != inWhitespace {
+			nodes = append(nodes, p.textNode(text[start:i], offset+start, inWhitespace))
+			start, inWhitespace = i, isWhitespace(r)
 		}
 	}
+
 	// Flush remaining token.
-	if current.Len() > 0 {
-		token := current.String()
-		if inWhitespace {
-			nodes = append(nodes, &WhitespaceNode{Text: token})
-		} else {
-			nodes = append(nodes, &TextNode{Text: token})
-		}
+	if start < len(text) {
+		nodes = append(nodes, p.textNode(text[start:], offset+start, inWhitespace))
 	}
+
 	return nodes
 }
 
-// utf8DecodeRuneInString is a helper to decode the first rune.
-// If the string is empty, it returns 0.
-func utf8DecodeRuneInString(s string) (r rune, size int) {
-	if len(s) == 0 {
-		return 0, 0
+func (p *parser) textNode(text string, offset int, whitespace bool) Node {
+	if whitespace {
+		return &WhitespaceNode{Position: p.position(offset), Text: text}
 	}
-	return []rune(s)[0], 1
+	return &TextNode{Position: p.position(offset), Text: text}
 }
 
-// isWhitespace returns true if the rune is a whitespace character.
-func isWhitespace(r rune) bool {
-	return unicode.IsSpace(r)
-}
+func (p *parser) position(offset int) Position {
+	line := sort.Search(len(p.lines), func(i int) bool {
+		return p.lines[i] > offset
+	}) - 1
 
-// ParseTemplate is the entry point that builds an AST for the template.
-func ParseTemplate(input string) (NodeList, error) {
-	pos := 0
-	return parseNodes(input, &pos, false)
+	return Position{Offset: offset, Line: line + 1, Column: offset - p.lines[line] + 1}
 }
 
-// parseNodes walks through the input string from position *pos and returns
-// a slice of nodes. It recognizes two types of tags:
-//   - Block tags: delimited by {% ... %}.
-//   - Expression tags: delimited by {{ ... }}.
-//
-// If stopOnEndBlock is true, parsing stops when a matching {% endblock %} is
-// encountered.
-func parseNodes(input string, pos *int, stopOnEndBlock bool) ([]Node, error) {
-	var nodes []Node
-
-	for *pos < len(input) {
-		relativeBlock := strings.Index(input[*pos:], "{%")
-		relativeExpr := strings.Index(input[*pos:], "{{")
-		var nextTagIndex int
-		var tagType string
-
-		if relativeBlock == -1 && relativeExpr == -1 {
-			nextTagIndex = -1
-		} else if relativeBlock == -1 {
-			nextTagIndex = relativeExpr
-			tagType = "expr"
-		} else if relativeExpr == -1 {
-			nextTagIndex = relativeBlock
-			tagType = "block"
-		} else if relativeBlock < relativeExpr {
-			nextTagIndex = relativeBlock
-			tagType = "block"
-		} else {
-			nextTagIndex = relativeExpr
-			tagType = "expr"
-		}
-
-		if nextTagIndex == -1 {
-			remaining := input[*pos:]
-			nodes = append(nodes, tokenizeText(remaining)...)
-			*pos = len(input)
-			break
+// nextDelimiter returns the offset of the next {{, {% or {# starting at from or -1.
+func nextDelimiter(input string, from int) int {
+	for from < len(input) {
+		index := strings.IndexByte(input[from:], '{')
+		if index == -1 {
+			return -1
 		}
-
-		tagStart := *pos + nextTagIndex
-		if tagStart > *pos {
-			text := input[*pos:tagStart]
-			nodes = append(nodes, tokenizeText(text)...)
+		index += from
+		if index+1 < len(input) && strings.IndexByte("{%#", input[index+1]) != -1 {
+			return index
 		}
+		from = index + 1
+	}
+	return -1
+}
 
-		if tagType == "block" {
-			closeTagIndex := strings.Index(input[tagStart:], "%}")
-			if closeTagIndex == -1 {
-				return nil, errors.New("unclosed block tag")
-			}
-			tagEnd := tagStart + closeTagIndex + 2
-			// Get tag content inside the delimiters.
-			tagContent := strings.TrimSpace(input[tagStart+2 : tagStart+closeTagIndex])
-
-			// Check for deprecated tag.
-			if strings.HasPrefix(tagContent, "deprecated ") {
-				message := strings.TrimSpace(tagContent[len("deprecated "):])
-				// Remove surrounding quotes if present.
-				message = strings.Trim(message, `"'`)
-				nodes = append(nodes, &DeprecatedNode{Message: message})
-				*pos = tagEnd
-				continue
+// findClosing returns the offset of the closing delimiter, which is ignored inside of string literals.
+func findClosing(input string, from int, closing string) int {
+	var quote byte
+	for i := from; i < len(input)-1; i++ {
+		c := input[i]
+		if quote != 0 {
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
 			}
+			continue
+		}
+		if c == '\'' || c == '"' {
+			quote = c
+			continue
+		}
+		if c == closing[0] && input[i+1] == closing[1] {
+			return i
+		}
+	}
 
-			// Handle 'autoescape' tag.
-			if strings.HasPrefix(tagContent, "autoescape") {
-				// Optionally, support a custom strategy.
-				strategy := "html"
-				parts := strings.Fields(tagContent)
-				if len(parts) > 1 {
-					strategy = strings.Trim(parts[1], `"'`)
-				}
-				*pos = tagEnd
-				children, err := parseNodes(input, pos, true)
-				if err != nil {
-					return nil, err
-				}
-				nodes = append(nodes, &AutoescapeNode{
-					Strategy: strategy,
-					Children: children,
-				})
-				continue
-			} else if strings.HasPrefix(tagContent, "endautoescape") {
-				if stopOnEndBlock {
-					*pos = tagEnd
-					return nodes, nil
-				}
-				// Treat unexpected end tag as literal text.
-				nodes = append(nodes, tokenizeText(input[tagStart:tagEnd])...)
-				*pos = tagEnd
-				continue
-			}
+	// Unbalanced quotes, fall back to the first delimiter
+	if index := strings.Index(input[from:], closing); index != -1 {
+		return from + index
+	}
+	return -1
+}
 
-			// ADD: Handle 'set' tag.
-			if strings.HasPrefix(tagContent, "set ") {
-				assignment := strings.TrimSpace(tagContent[len("set "):])
-				if strings.Contains(assignment, "=") {
-					// Inline assignment.
-					parts := strings.SplitN(assignment, "=", 2)
-					lhs := strings.TrimSpace(parts[0])
-					rhs := strings.TrimSpace(parts[1])
-					varNames := splitAndTrim(lhs, ",")
-					varValues := splitAndTrim(rhs, ",")
-					nodes = append(nodes, &SetNode{
-						Variables: varNames,
-						Values:    varValues,
-						IsBlock:   false,
-					})
-					*pos = tagEnd
-					continue
-				} else {
-					// Block assignment.
-					varNames := splitAndTrim(assignment, ",")
-					*pos = tagEnd
-					children, err := parseNodes(input, pos, true)
-					if err != nil {
-						return nil, err
-					}
-					nodes = append(nodes, &SetNode{
-						Variables: varNames,
-						IsBlock:   true,
-						Children:  children,
-					})
-					continue
-				}
-			}
+// cutName splits the content of a tag into its first word and the remaining arguments.
+func cutName(content string) (string, string) {
+	index := strings.IndexFunc(content, unicode.IsSpace)
+	if index == -1 {
+		return content, ""
+	}
+	return content[:index], strings.TrimSpace(content[index:])
+}
 
-			// Handle 'types' tag.
-			if strings.HasPrefix(tagContent, "types") {
-				remainder := strings.TrimSpace(tagContent[len("types"):])
-				typesNode, err := parseTypes(remainder)
-				if err != nil {
-					return nil, err
-				}
-				nodes = append(nodes, typesNode)
-				*pos = tagEnd
-				continue
-			}
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (string, string, bool) {
+	index := strings.LastIndex(s, sep)
+	if index == -1 {
+		return s, "", false
+	}
+	return s[:index], s[index+len(sep):], true
+}
 
-			// For node.
-			if strings.HasPrefix(tagContent, "block ") {
-				parts := strings.Fields(tagContent)
-				if len(parts) < 2 {
-					return nil, errors.New("invalid block tag: no block name")
-				}
-				blockName := parts[1]
-				*pos = tagEnd
-				children, err := parseNodes(input, pos, true)
-				if err != nil {
-					return nil, err
-				}
-				block := &BlockNode{
-					Name:     blockName,
-					Children: children,
-				}
-				nodes = append(nodes, block)
-				continue
-			} else if strings.HasPrefix(tagContent, "sw_extends") {
-				// New support for sw_extends.
-				remainder := strings.TrimSpace(tagContent[len("sw_extends"):])
-				var tmpl string
-				scopes := []string{}
-				if strings.HasPrefix(remainder, "{") {
-					// Extended syntax: an object literal.
-					startIdx := strings.Index(remainder, "{")
-					endIdx := strings.LastIndex(remainder, "}")
-					if startIdx == -1 || endIdx == -1 || endIdx <= startIdx {
-						return nil, errors.New("invalid sw_extends syntax: missing or mismatched braces")
-					}
-					objectContent := strings.TrimSpace(remainder[startIdx+1 : endIdx])
-					var err error
-					tmpl, scopes, err = parseSwExtendsLiteral(objectContent)
-					if err != nil {
-						return nil, err
-					}
-				} else {
-					// Simple syntax.
-					parts := strings.Fields(tagContent)
-					if len(parts) < 2 {
-						return nil, errors.New("invalid sw_extends tag: missing template path")
-					}
-					tmpl = strings.Trim(parts[1], `"'`)
-				}
-				nodes = append(nodes, &SwExtendsNode{Template: tmpl, Scopes: scopes})
-				*pos = tagEnd
-				continue
-			} else if strings.HasPrefix(tagContent, "endblock") || strings.HasPrefix(tagContent, "endfor") {
-				if stopOnEndBlock {
-					*pos = tagEnd
-					return nodes, nil
-				}
-				// If an endblock appears unexpectedly, treat it as literal text.
-				nodes = append(nodes, tokenizeText(input[tagStart:tagEnd])...)
-				*pos = tagEnd
-				continue
-			} else {
-				// Unrecognized block tag: treat it as literal text.
-				nodes = append(nodes, tokenizeText(input[tagStart:tagEnd])...)
-				*pos = tagEnd
-				continue
-			}
-		} else if tagType == "expr" {
-			closeExprIndex := strings.Index(input[tagStart:], "}}")
-			if closeExprIndex == -1 {
-				return nil, errors.New("unclosed expression tag")
-			}
-			tagEnd := tagStart + closeExprIndex + 2
-			exprContent := strings.TrimSpace(input[tagStart+2 : tagStart+closeExprIndex])
-			if exprContent == "parent()" {
-				nodes = append(nodes, &ParentNode{})
-			} else {
-				// Create a PrintNode for expressions like {{ a_variable }}
-				nodes = append(nodes, &PrintNode{Expression: exprContent})
+// isWhitespace returns true if the rune is a whitespace character.
+func isWhitespace(r rune) bool {
+	return unicode.IsSpace(r)
+}
+
+// parseSwExtends parses the arguments of a sw_extends tag in simple or object literal syntax.
+func parseSwExtends(arguments string) (*SwExtendsNode, error) {
+	if !strings.HasPrefix(arguments, "{") {
+		// Simple syntax.
+		template, _ := cutName(arguments)
+		if template == "" {
+			return nil, errors.New("invalid sw_extends tag: missing template path")
+		}
+		return &SwExtendsNode{Template: strings.Trim(template, `"'`), Scopes: []string{}}, nil
+	}
+
+	// Extended syntax: an object literal.
+	endIdx := strings.LastIndex(arguments, "}")
+	if endIdx <= 0 {
+		return nil, errors.New("invalid sw_extends syntax: missing or mismatched braces")
+	}
+	template, scopes, err := parseSwExtendsLiteral(strings.TrimSpace(arguments[1:endIdx]))
+	if err != nil {
+		return nil, err
+	}
+	return &SwExtendsNode{Template: template, Scopes: scopes}, nil
+}
+
+// splitExpressions splits a list of expressions at the commas which are not nested in brackets or strings.
+func splitExpressions(s string) []string {
+	var results []string
+	var quote byte
+	depth, start := 0, 0
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
 			}
-			*pos = tagEnd
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			results = append(results, s[start:i])
+			start = i + 1
 		}
 	}
 
-	return nodes, nil
+	return splitAndTrim(strings.Join(append(results, s[start:]), "\x00"), "\x00")
 }
 
 // splitAndTrim splits the string s by the given sep and trims whitespace from each element.
@@ -301,6 +612,30 @@ func splitAndTrim(s, sep string) []string {
 	return results
 }
 
+// splitTemplateArgument splits the arguments of an include like tag into the template and the
+// remaining arguments starting with with, ignore missing or only. Templates which are no plain
+// string literal are returned as dynamic expression.
+func splitTemplateArgument(s string) (string, bool, string) {
+	expression, arguments := s, ""
+	fields := strings.Fields(s)
+	for i, field := range fields {
+		if field == "with" || field == "ignore" || field == "only" {
+			expression = strings.Join(fields[:i], " ")
+			arguments = strings.Join(fields[i:], " ")
+			break
+		}
+	}
+
+	if len(expression) >= 2 {
+		quote := expression[0]
+		if (quote == '\'' || quote == '"') && expression[len(expression)-1] == quote && !strings.ContainsRune(expression[1:len(expression)-1], rune(quote)) {
+			return expression[1 : len(expression)-1], false, arguments
+		}
+	}
+
+	return expression, true, arguments
+}
+
 // parseSwExtendsLiteral parses the object literal inside a sw_extends tag.
 // It expects an input like:
 //
@@ -103,6 +103,27 @@ func TestPrintNodeParsing(t *testing.T) {
 	assert.Equal(t, "{{ a_variable }}", nodes.Dump())
 }
 
+func TestBlockCallsParent(t *testing.T) {
+	cases := map[string]bool{
+		`{% block page %}{{ parent() }}{% endblock %}`:                                         true,
+		`{% block page %}{{- parent () -}}{% endblock %}`:                                      true,
+		`{% block page %}{% if x %}{{ parent() }}{% endif %}custom{% endblock %}`:              true,
+		`{% block page %}{{ parent()|raw }}{% endblock %}`:                                     false,
+		`{% block page %}{% block title %}{{ parent() }}{% endblock %}{% endblock %}`:          false,
+		`{% block page %}{% sw_embed 'a.twig' %}{{ parent() }}{% endsw_embed %}{% endblock %}`: false,
+	}
+
+	for template, expected := range cases {
+		nodes, err := ParseTemplate(template)
+		assert.NoError(t, err, template)
+
+		block := nodes.FindBlock("page")
+		assert.NotNil(t, block, template)
+		assert.Equal(t, expected, block.CallsParent(), template)
+		assert.Equal(t, template, nodes.Dump())
+	}
+}
+
 func TestDeprecatedNodeParsing(t *testing.T) {
 	template := `{% deprecated 'The "base.html.twig" template is deprecated, use "layout.html.twig" instead.' %}`
 	nodes, err := ParseTemplate(template)
@@ -183,3 +204,93 @@ func TestAutoescapeNodeParsing(t *testing.T) {
 	assert.Contains(t, dumped, "{% autoescape %}")
 	assert.Contains(t, dumped, "{% endautoescape %}")
 }
+
+func TestLosslessDump(t *testing.T) {
+	template := `{% sw_extends "@Storefront/storefront/base.html.twig" %}
+{# Product listing #}
+{% import _self as helpers %}
+{% from 'macros.html.twig' import price as formatPrice, badge %}
+{%- block base_content -%}
+    {% if page.listing.total > 0 %}
+        {% for product in page.listing.elements %}
+            {{- product.translated.name|e -}}
+            {% include '@Storefront/storefront/component/product/card/box.html.twig' with { product: product } only %}
+        {% else %}
+            {{ "listing.empty"|trans }}
+        {% endfor %}
+    {% elseif page.listing.loading %}
+        {% apply upper %}Loading{% endapply %}
+    {% else %}
+        {% embed 'alert.html.twig' with { type: 'info' } %}{% block alert_content %}Empty{% endblock %}{% endembed %}
+    {% endif %}
+    {% with { title: 'Foo' } only %}{{ title }}{% endwith %}
+    {% macro badge(text, type = 'info') %}<span class="badge">{{ text }}</span>{% endmacro %}
+    {% verbatim %}{{ not parsed }}{% if %}{% endverbatim %}
+    {% sw_icon 'arrow-head-right' style { size: 'sm' } %}
+    {% set classes, title = ['a', 'b'], 'Foo, bar' %}
+{%~ endblock base_content ~%}`
+
+	nodes, err := ParseTemplate(template)
+	assert.NoError(t, err)
+	assert.Equal(t, template, nodes.Dump())
+
+	block := nodes.FindBlock("base_content")
+	assert.NotNil(t, block)
+	assert.Equal(t, "-", block.TrimBefore)
+	assert.Equal(t, "-", block.TrimAfter)
+	assert.Equal(t, "~", block.End.TrimBefore)
+	assert.Equal(t, Position{Offset: 174, Line: 5, Column: 1}, block.Pos())
+
+	// Blocks nested in control structures are found, blocks of embedded templates are not
+	assert.Equal(t, []string{"base_content"}, nodes.BlockNames())
+
+	ifNode := block.Children.RemoveWhitespace()[0].(*IfNode)
+	assert.Len(t, ifNode.Branches, 3)
+	assert.Equal(t, "page.listing.total > 0", ifNode.Branches[0].Condition)
+	assert.Equal(t, "page.listing.loading", ifNode.Branches[1].Condition)
+	assert.Equal(t, "", ifNode.Branches[2].Condition)
+	assert.Equal(t, 6, ifNode.Pos().Line)
+
+	forNode := ifNode.Branches[0].Children.RemoveWhitespace()[0].(*ForNode)
+	assert.Equal(t, "product", forNode.Var)
+	assert.Equal(t, "page.listing.elements", forNode.Collection)
+	assert.NotNil(t, forNode.ElseTag)
+
+	include := forNode.Children.RemoveWhitespace()[1].(*IncludeNode)
+	assert.Equal(t, "@Storefront/storefront/component/product/card/box.html.twig", include.Template)
+	assert.Equal(t, "with { product: product } only", include.Arguments)
+
+	set := nodes.Find(func(node Node) bool {
+		_, ok := node.(*SetNode)
+		return ok
+	})[0].(*SetNode)
+	assert.Equal(t, []string{"classes", "title"}, set.Variables)
+	assert.Equal(t, []string{"['a', 'b']", "'Foo, bar'"}, set.Values)
+
+	verbatim := nodes.Find(func(node Node) bool {
+		_, ok := node.(*VerbatimNode)
+		return ok
+	})[0].(*VerbatimNode)
+	assert.Equal(t, "{{ not parsed }}{% if %}", verbatim.Text)
+}
+
+func TestDumpModifiedTag(t *testing.T) {
+	nodes, err := ParseTemplate(`{%- if  a  -%}A{%else%}B{% endif %}{{-name-}}`)
+	assert.NoError(t, err)
+
+	ifNode := nodes[0].(*IfNode)
+	ifNode.Branches[0].Condition = "b"
+	nodes[1].(*PrintNode).Expression = "title"
+
+	assert.Equal(t, `{%- if b -%}A{%else%}B{% endif %}{{- title -}}`, nodes.Dump())
+}
+
+func TestUnclosedTag(t *testing.T) {
+	_, err := ParseTemplate("{% block a %}\n{% if b %}{% endblock %}")
+	assert.EqualError(t, err, "unclosed if tag on line 2, expected elseif or else or endif")
+
+	// Unexpected end tags are kept as text
+	nodes, err := ParseTemplate("{% endif %}")
+	assert.NoError(t, err)
+	assert.IsType(t, &TextNode{}, nodes[0])
+}
@@ -18,6 +18,14 @@ func detectDefaultReporter() string {
 	return "summary"
 }
 
+func validateReporter(reporter string) error {
+	if reporter != "summary" && reporter != "json" && reporter != "github" && reporter != "junit" && reporter != "markdown" && reporter != "" {
+		return fmt.Errorf("invalid reporter format: %s. Must be either 'summary', 'json', 'github', 'junit' or 'markdown'", reporter)
+	}
+
+	return nil
+}
+
 func doCheckReport(result *tool.Check, reportingFormat string) error {
 	switch reportingFormat {
 	case "summary":
@@ -66,16 +74,30 @@ func doSummaryReport(result *tool.Check) error {
 				warningCount++
 			}
 			fmt.Printf("  %d  %-7s  %s  %s\n", r.Line, r.Severity, r.Message, r.Identifier)
+
+			if r.Diff != "" {
+				fmt.Printf("\n%s\n", indentDiff(r.Diff, "      "))
+			}
 		}
 	}
 
 	fmt.Printf("\n✖ %d problems (%d errors, %d warnings)\n", totalProblems, errorCount, warningCount)
 
+	if len(tool.RulesOf(result.Results)) > 0 {
+		fmt.Println("\nRun sw-extension-verifier rules explain <identifier> for a description of the rule with examples")
+	}
+
 	return nil
 }
 
 func doJSONReport(result *tool.Check) error {
-	j, err := json.Marshal(result)
+	j, err := json.Marshal(struct {
+		Results []tool.CheckResult    `json:"results"`
+		Rules   []tool.RuleDescriptor `json:"rules,omitempty"`
+	}{
+		Results: result.Results,
+		Rules:   tool.RulesOf(result.Results),
+	})
 
 	if err != nil {
 		return err
@@ -97,12 +119,20 @@ func doGitHubReport(result *tool.Check) error {
 		}
 	}
 
+	rules := rulesByIdentifier(result.Results)
+
 	for _, res := range result.Results {
-		if res.Line == 0 {
-			fmt.Printf("::%s file=%s::%s\n", res.Severity, res.Path, res.Message)
-		} else {
-			fmt.Printf("::%s file=%s,line=%d::%s\n", res.Severity, res.Path, res.Line, res.Message)
+		properties := "file=" + res.Path
+
+		if res.Line != 0 {
+			properties += fmt.Sprintf(",line=%d", res.Line)
+		}
+
+		if rule, ok := rules[res.Identifier]; ok {
+			properties += ",title=" + escapeGitHubProperty(rule.Title)
 		}
+
+		fmt.Printf("::%s %s::%s\n", res.Severity, properties, res.Message)
 	}
 
 	return nil
@@ -139,6 +169,7 @@ func doJUnitReport(result *tool.Check) error {
 	// Create a test case for each result
 	testcases := make([]testcase, 0, len(result.Results))
 	failures := 0
+	rules := rulesByIdentifier(result.Results)
 
 	for _, res := range result.Results {
 		tc := testcase{
@@ -159,6 +190,14 @@ func doJUnitReport(result *tool.Check) error {
 				Type:    res.Severity,
 				Content: fmt.Sprintf("Line: %d\nMessage: %s", res.Line, res.Message),
 			}
+
+			if rule, ok := rules[res.Identifier]; ok {
+				tc.Failure.Content += fmt.Sprintf("\nRule: %s\n%s", rule.Title, rule.Description)
+			}
+
+			if res.Diff != "" {
+				tc.Failure.Content += "\n\n" + res.Diff
+			}
 		}
 
 		testcases = append(testcases, tc)
@@ -219,5 +258,50 @@ func convertResultsToMarkdown(check []tool.CheckResult) string {
 
 	builder.WriteString("\n")
 
+	rules := tool.RulesOf(check)
+
+	if len(rules) > 0 {
+		builder.WriteString("## Rules\n\n")
+	}
+
+	for _, rule := range rules {
+		builder.WriteString(fmt.Sprintf("### %s\n\n**%s** (fix: %s)\n\n%s\n\n", rule.Identifier, rule.Title, rule.FixSafety, rule.Description))
+	}
+
+	for _, result := range check {
+		if result.Diff == "" {
+			continue
+		}
+
+		builder.WriteString(fmt.Sprintf("### %s\n\n```diff\n%s```\n\n", result.Path, result.Diff))
+	}
+
 	return builder.String()
 }
+
+// indentDiff indents every line of the diff for the summary.
+func indentDiff(diff, indent string) string {
+	lines := strings.Split(strings.TrimSuffix(diff, "\n"), "\n")
+
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// rulesByIdentifier returns the rules of the results keyed by their identifier.
+func rulesByIdentifier(results []tool.CheckResult) map[string]tool.RuleDescriptor {
+	rules := map[string]tool.RuleDescriptor{}
+
+	for _, rule := range tool.RulesOf(results) {
+		rules[rule.Identifier] = rule
+	}
+
+	return rules
+}
+
+// escapeGitHubProperty escapes the value of a property of a workflow command.
+func escapeGitHubProperty(value string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(value)
+}